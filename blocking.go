@@ -20,6 +20,12 @@ type Blocking[T comparable] struct {
 	initialElems []T
 	elems        []T
 	capacity     *int
+	// shared is set while a Snapshot may share the backing array of
+	// elems; see unshare.
+	shared bool
+	// members is set WithUnique, WithIndex or WithBloomFilter; see
+	// membership.
+	members *membership[T]
 
 	// synchronization
 	lock         sync.RWMutex
@@ -40,6 +46,10 @@ func NewBlocking[T comparable](
 		o.apply(&options)
 	}
 
+	if options.unique {
+		elems = distinct(elems)
+	}
+
 	// Trim caller elems to capacity first so initialElems reflects what
 	// actually fits in the queue; otherwise Reset can restore more elements
 	// than the queue is allowed to hold.
@@ -60,8 +70,11 @@ func NewBlocking[T comparable](
 		initialElems: initialElems,
 		capacity:     options.capacity,
 		lock:         sync.RWMutex{},
+		members:      newMembership[T](options),
 	}
 
+	queue.members.reset(ownedElems)
+
 	queue.notEmptyCond = sync.NewCond(&queue.lock)
 	queue.notFullCond = sync.NewCond(&queue.lock)
 
@@ -71,16 +84,19 @@ func NewBlocking[T comparable](
 // ==================================Insertion=================================
 
 // OfferWait inserts the element to the tail the queue.
-// It waits for necessary space to become available.
+// It waits for necessary space to become available and, if the queue was
+// created WithUnique and already contains the element, for the element to
+// be removed.
 func (bq *Blocking[T]) OfferWait(elem T) {
 	bq.lock.Lock()
 	defer bq.lock.Unlock()
 
-	for bq.isFull() {
+	for !bq.members.admits(elem) || bq.isFull() {
 		bq.notFullCond.Wait()
 	}
 
 	bq.elems = append(bq.elems, elem)
+	bq.members.add(elem)
 
 	// Broadcast so any mix of GetWait / PeekWait waiters re-check.
 	// Signal would only wake one, requiring a cascade hack in PeekWait
@@ -89,16 +105,22 @@ func (bq *Blocking[T]) OfferWait(elem T) {
 }
 
 // Offer inserts the element to the tail the queue.
-// If the queue is full it returns the ErrQueueIsFull error.
+// If the queue is full it returns the ErrQueueIsFull error, and if it was
+// created WithUnique and already contains the element, ErrDuplicate.
 func (bq *Blocking[T]) Offer(elem T) error {
 	bq.lock.Lock()
 	defer bq.lock.Unlock()
 
+	if !bq.members.admits(elem) {
+		return ErrDuplicate
+	}
+
 	if bq.isFull() {
 		return ErrQueueIsFull
 	}
 
 	bq.elems = append(bq.elems, elem)
+	bq.members.add(elem)
 
 	bq.notEmptyCond.Broadcast()
 
@@ -113,6 +135,8 @@ func (bq *Blocking[T]) Reset() {
 	// Restore initial elements
 	bq.elems = make([]T, len(bq.initialElems))
 	copy(bq.elems, bq.initialElems)
+	bq.shared = false
+	bq.members.reset(bq.elems)
 
 	bq.notEmptyCond.Broadcast()
 	bq.notFullCond.Broadcast()
@@ -131,11 +155,14 @@ func (bq *Blocking[T]) GetWait() (v T) {
 		bq.notEmptyCond.Wait()
 	}
 
+	bq.unshare()
+
 	var zero T
 
 	elem := bq.elems[0]
 	bq.elems[0] = zero
 	bq.elems = bq.elems[1:]
+	bq.members.remove(elem)
 
 	bq.notFullCond.Broadcast()
 
@@ -161,6 +188,16 @@ func (bq *Blocking[T]) Clear() []T {
 	removed := make([]T, len(bq.elems))
 	copy(removed, bq.elems)
 
+	bq.members.reset(nil)
+
+	// A snapshot still reads the backing array: leave it alone.
+	if bq.shared {
+		bq.elems = nil
+		bq.shared = false
+
+		return removed
+	}
+
 	// Drop references into the backing array so popped elements can be
 	// GC'd while the queue outlives them. elems = elems[:0] alone keeps
 	// the underlying slots populated.
@@ -230,6 +267,23 @@ func (bq *Blocking[T]) PeekWait() T {
 	return bq.elems[0]
 }
 
+// Snapshot returns an immutable, point-in-time view of the elements in
+// the queue. It runs in O(1) and reading the snapshot does not take the
+// queue lock. The cost is moved to the first Get or GetWait after it,
+// which copies every element of the queue into a new backing array and so
+// runs in O(n), as the copy must not be shared. Taking a Snapshot between
+// every Get therefore makes each Get O(n).
+func (bq *Blocking[T]) Snapshot() *Snapshot[T] {
+	bq.lock.Lock()
+	defer bq.lock.Unlock()
+
+	bq.shared = true
+
+	// Appends past len(bq.elems) may reuse the spare capacity of the
+	// backing array; they never touch the shared elements.
+	return sliceSnapshot(bq.elems[:len(bq.elems):len(bq.elems)])
+}
+
 // Size returns the number of elements in the queue.
 func (bq *Blocking[T]) Size() int {
 	bq.lock.RLock()
@@ -239,10 +293,15 @@ func (bq *Blocking[T]) Size() int {
 }
 
 // Contains returns true if the queue contains the given element.
+// See WithIndex and WithBloomFilter for how they speed it up.
 func (bq *Blocking[T]) Contains(elem T) bool {
 	bq.lock.RLock()
 	defer bq.lock.RUnlock()
 
+	if found, sure := bq.members.lookup(elem); sure {
+		return found
+	}
+
 	for _, e := range bq.elems {
 		if e == elem {
 			return true
@@ -252,6 +311,43 @@ func (bq *Blocking[T]) Contains(elem T) bool {
 	return false
 }
 
+// MaybeContains reports whether the queue may contain the given element.
+// It never returns false for an element the queue contains; see
+// WithBloomFilter for when it returns true for one it does not.
+func (bq *Blocking[T]) MaybeContains(elem T) bool {
+	if bq.members == nil {
+		return bq.Contains(elem)
+	}
+
+	bq.lock.RLock()
+	defer bq.lock.RUnlock()
+
+	found, _ := bq.members.lookup(elem)
+
+	return found
+}
+
+// Count returns the number of times the given element is in the queue.
+// See WithIndex and WithBloomFilter for how they speed it up.
+func (bq *Blocking[T]) Count(elem T) int {
+	bq.lock.RLock()
+	defer bq.lock.RUnlock()
+
+	if n, sure := bq.members.count(elem); sure {
+		return n
+	}
+
+	n := 0
+
+	for _, e := range bq.elems {
+		if e == elem {
+			n++
+		}
+	}
+
+	return n
+}
+
 // IsEmpty returns true if the queue is empty.
 func (bq *Blocking[T]) IsEmpty() bool {
 	bq.lock.RLock()
@@ -276,11 +372,28 @@ func (bq *Blocking[T]) isFull() bool {
 	return len(bq.elems) >= *bq.capacity
 }
 
+// unshare copies the elements into a new backing array if a Snapshot
+// shares the current one, before the caller overwrites an element. The
+// copy is O(n), and paid once per Snapshot.
+func (bq *Blocking[T]) unshare() {
+	if !bq.shared {
+		return
+	}
+
+	elems := make([]T, len(bq.elems))
+	copy(elems, bq.elems)
+
+	bq.elems = elems
+	bq.shared = false
+}
+
 func (bq *Blocking[T]) get() (v T, _ error) {
 	if bq.isEmpty() {
 		return v, ErrNoElementsAvailable
 	}
 
+	bq.unshare()
+
 	elem := bq.elems[0]
 
 	// Zero the popped slot so the backing array no longer references the
@@ -290,6 +403,7 @@ func (bq *Blocking[T]) get() (v T, _ error) {
 
 	bq.elems[0] = zero
 	bq.elems = bq.elems[1:]
+	bq.members.remove(elem)
 
 	bq.notFullCond.Broadcast()
 
@@ -0,0 +1,191 @@
+package queue
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// maxBloomCount is the value at which a counter of a countingBloom
+// saturates. A saturated counter is never decremented again, as the
+// filter no longer knows how many elements it counts.
+const maxBloomCount = math.MaxUint8
+
+// countingBloom is a counting Bloom filter: every element increments k of
+// its counters, picked by hashing, and removing the element decrements
+// them. It never reports a false negative, and reports a false positive
+// at the rate it was sized for as long as it holds at most the expected
+// number of elements.
+type countingBloom struct {
+	counters []uint8
+	k        int
+}
+
+// newCountingBloom returns a counting Bloom filter sized for expected
+// elements with the given false positive rate.
+func newCountingBloom(expected int, fpRate float64) *countingBloom {
+	m := math.Ceil(-float64(expected) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+
+	k := int(math.Round(m / float64(expected) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &countingBloom{
+		counters: make([]uint8, int(m)),
+		k:        k,
+	}
+}
+
+// index returns the counter an element with the hash h uses for its i-th
+// hash function, derived from h by double hashing.
+func (f *countingBloom) index(h uint64, i int) int {
+	step := h>>32 | 1
+
+	return int((h + uint64(i)*step) % uint64(len(f.counters)))
+}
+
+// add records an element with the hash h.
+func (f *countingBloom) add(h uint64) {
+	for i := 0; i < f.k; i++ {
+		if c := &f.counters[f.index(h, i)]; *c < maxBloomCount {
+			*c++
+		}
+	}
+}
+
+// remove forgets an element with the hash h, which must have been added.
+func (f *countingBloom) remove(h uint64) {
+	for i := 0; i < f.k; i++ {
+		if c := &f.counters[f.index(h, i)]; *c < maxBloomCount {
+			*c--
+		}
+	}
+}
+
+// test reports whether an element with the hash h may have been added.
+func (f *countingBloom) test(h uint64) bool {
+	for i := 0; i < f.k; i++ {
+		if f.counters[f.index(h, i)] == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clear forgets every element.
+func (f *countingBloom) clear() {
+	for i := range f.counters {
+		f.counters[i] = 0
+	}
+}
+
+// newHasher returns a func hashing elements of type T with hash/maphash,
+// so that equal elements have equal hashes. Strings, integers and floats
+// are hashed directly; other types are walked with reflect, hashing
+// pointers, channels and other values compared by identity by their
+// address rather than by what they point to, which can change while the
+// element is queued.
+func newHasher[T comparable]() func(T) uint64 {
+	seed := maphash.MakeSeed()
+
+	return func(elem T) uint64 {
+		var buf [8]byte
+
+		switch v := any(elem).(type) {
+		case string:
+			return maphash.String(seed, v)
+		case int:
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		case int64:
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		case int32:
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		case uint:
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		case uint64:
+			binary.LittleEndian.PutUint64(buf[:], v)
+		case uint32:
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		case float64:
+			binary.LittleEndian.PutUint64(buf[:], floatBits(v))
+		case float32:
+			binary.LittleEndian.PutUint64(buf[:], floatBits(float64(v)))
+		default:
+			var h maphash.Hash
+
+			h.SetSeed(seed)
+			writeHash(&h, reflect.ValueOf(any(elem)))
+
+			return h.Sum64()
+		}
+
+		return maphash.Bytes(seed, buf[:])
+	}
+}
+
+// writeHash writes a comparable value to h, so that equal values write
+// the same bytes.
+func writeHash(h *maphash.Hash, v reflect.Value) {
+	var buf [8]byte
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			buf[0] = 1
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		binary.LittleEndian.PutUint64(buf[:], v.Uint())
+	case reflect.Float32, reflect.Float64:
+		binary.LittleEndian.PutUint64(buf[:], floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+
+		binary.LittleEndian.PutUint64(buf[:], floatBits(real(c)))
+		_, _ = h.Write(buf[:])
+		binary.LittleEndian.PutUint64(buf[:], floatBits(imag(c)))
+	case reflect.String:
+		_, _ = h.WriteString(v.String())
+
+		return
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v.Pointer()))
+	case reflect.Interface:
+		if !v.IsNil() {
+			writeHash(h, v.Elem())
+
+			return
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeHash(h, v.Index(i))
+		}
+
+		return
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeHash(h, v.Field(i))
+		}
+
+		return
+	default:
+		// A nil interface element, the only invalid value, or a kind that
+		// is not comparable and so cannot be in a queue.
+	}
+
+	_, _ = h.Write(buf[:])
+}
+
+// floatBits returns the bits of f, the same for both zeros, which are
+// equal.
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+
+	return math.Float64bits(f)
+}
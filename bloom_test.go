@@ -0,0 +1,335 @@
+package queue_test
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/adrianbrad/queue"
+)
+
+// identityHash hashes an int to itself, which makes Bloom filters
+// deterministic in tests.
+func identityHash(i int) uint64 {
+	return uint64(i)
+}
+
+func TestWithBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InvalidArguments", testWithBloomFilterInvalidArguments)
+	t.Run("MaybeContains", testWithBloomFilterMaybeContains)
+	t.Run("FalsePositives", testWithBloomFilterFalsePositives)
+	t.Run("Removal", testWithBloomFilterRemoval)
+	t.Run("Saturation", testWithBloomFilterSaturation)
+	t.Run("Exact", testWithBloomFilterExact)
+	t.Run("DerivedHasher", testWithBloomFilterDerivedHasher)
+	t.Run("MutatedPointers", testWithBloomFilterMutatedPointers)
+}
+
+func testWithBloomFilterInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		want string
+		opts []queue.Option
+	}{
+		"NoExpectedElements": {
+			want: "bloom filter expected elements must be positive",
+			opts: []queue.Option{queue.WithBloomFilter(0, 0.01)},
+		},
+		"ZeroFalsePositiveRate": {
+			want: "bloom filter false positive rate must be in (0, 1)",
+			opts: []queue.Option{queue.WithBloomFilter(10, 0)},
+		},
+		"FalsePositiveRateOfOne": {
+			want: "bloom filter false positive rate must be in (0, 1)",
+			opts: []queue.Option{queue.WithBloomFilter(10, 1)},
+		},
+		"NaNFalsePositiveRate": {
+			want: "bloom filter false positive rate must be in (0, 1)",
+			opts: []queue.Option{queue.WithBloomFilter(10, math.NaN())},
+		},
+		"HasherTypeMismatch": {
+			want: "hasher func does not match the element type",
+			opts: []queue.Option{
+				queue.WithBloomFilter(10, 0.01),
+				queue.WithHasher(func(string) uint64 { return 0 }),
+			},
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if p := recover(); p != c.want {
+					t.Fatalf("expected panic %q, got %v", c.want, p)
+				}
+			}()
+
+			queue.NewBlocking[int](nil, c.opts...)
+		})
+	}
+}
+
+func testWithBloomFilterMaybeContains(t *testing.T) {
+	t.Parallel()
+
+	opts := []queue.Option{
+		queue.WithBloomFilter(100, 0.01),
+		queue.WithHasher(identityHash),
+	}
+
+	for name, q := range countingQueues([]int{1, 2, 1}, opts...) {
+		if !q.MaybeContains(1) || !q.MaybeContains(2) {
+			t.Fatalf("%s: expected a possible match", name)
+		}
+
+		// The filter rules out the missing element, as one of its
+		// counters is not used by any queued element.
+		if q.MaybeContains(3) || q.Contains(3) || q.Count(3) != 0 {
+			t.Fatalf("%s: expected 3 to be missing", name)
+		}
+
+		// A possible match is checked by a scan.
+		if !q.Contains(1) || q.Count(1) != 2 {
+			t.Fatalf("%s: expected 1 twice", name)
+		}
+	}
+}
+
+func testWithBloomFilterFalsePositives(t *testing.T) {
+	t.Parallel()
+
+	// Hashing every element to the same counters makes every lookup a
+	// possible match, which Contains and Count resolve by scanning.
+	q := queue.NewBlocking(
+		[]int{1},
+		queue.WithBloomFilter(100, 0.01),
+		queue.WithHasher(func(int) uint64 { return 0 }),
+	)
+
+	if !q.MaybeContains(2) {
+		t.Fatal("expected a false positive")
+	}
+
+	if q.Contains(2) || q.Count(2) != 0 {
+		t.Fatal("expected 2 to be missing")
+	}
+
+	// With the default hasher, the false positive rate stays close to the
+	// one the filter was sized for.
+	const n = 1000
+
+	q = queue.NewBlocking[int](nil, queue.WithBloomFilter(n, 0.01))
+
+	for i := 0; i < n; i++ {
+		_ = q.Offer(i)
+	}
+
+	falsePositives := 0
+
+	for i := n; i < 11*n; i++ {
+		if q.MaybeContains(i) {
+			falsePositives++
+		}
+	}
+
+	if falsePositives > n/2 {
+		t.Fatalf("expected about 1%% false positives, got %d in %d", falsePositives, 10*n)
+	}
+}
+
+func testWithBloomFilterRemoval(t *testing.T) {
+	t.Parallel()
+
+	opts := []queue.Option{
+		// A high rate gives a single hash function.
+		queue.WithBloomFilter(10, 0.9),
+		queue.WithHasher(identityHash),
+	}
+
+	for name, q := range countingQueues([]int{1, 1}, opts...) {
+		_, _ = q.Get()
+		_, _ = q.Get()
+
+		if q.MaybeContains(1) {
+			t.Fatalf("%s: expected 1 to be removed", name)
+		}
+
+		_ = q.Offer(2)
+		_ = q.Clear()
+
+		if q.MaybeContains(2) {
+			t.Fatalf("%s: expected 2 to be cleared", name)
+		}
+
+		_ = q.Offer(2)
+		q.Reset()
+
+		if q.MaybeContains(2) || !q.MaybeContains(1) {
+			t.Fatalf("%s: expected the initial elements", name)
+		}
+
+		_ = q.Iterator()
+
+		if q.MaybeContains(1) {
+			t.Fatalf("%s: expected an empty queue", name)
+		}
+	}
+}
+
+func testWithBloomFilterSaturation(t *testing.T) {
+	t.Parallel()
+
+	lq := queue.NewLinked[int](
+		nil,
+		queue.WithBloomFilter(10, 0.01),
+		queue.WithHasher(identityHash),
+	)
+
+	for i := 0; i < 300; i++ {
+		_ = lq.Offer(1)
+	}
+
+	_ = lq.Clear()
+
+	if lq.MaybeContains(1) {
+		t.Fatal("expected Clear to reset the filter")
+	}
+
+	for i := 0; i < 300; i++ {
+		_ = lq.Offer(1)
+	}
+
+	for i := 0; i < 300; i++ {
+		_, _ = lq.Get()
+	}
+
+	// Saturated counters are never decremented, so the filter still
+	// reports a possible match, which the scan rules out.
+	if !lq.MaybeContains(1) || lq.Contains(1) {
+		t.Fatal("expected a false positive from saturated counters")
+	}
+}
+
+func testWithBloomFilterExact(t *testing.T) {
+	t.Parallel()
+
+	// WithIndex tracks elements exactly, so the filter is not used.
+	bq := queue.NewBlocking(
+		[]int{1, 1},
+		queue.WithBloomFilter(10, 0.01),
+		queue.WithHasher(func(int) uint64 { return 0 }),
+		queue.WithIndex(),
+	)
+
+	if bq.MaybeContains(2) || bq.Count(1) != 2 {
+		t.Fatal("expected exact answers")
+	}
+
+	// Without a filter or an index, MaybeContains is Contains.
+	for name, q := range countingQueues([]int{1}) {
+		if !q.MaybeContains(1) || q.MaybeContains(2) {
+			t.Fatalf("%s: unexpected MaybeContains result", name)
+		}
+	}
+}
+
+// maybeContainsAll reports whether a Blocking queue created with the
+// offered elements WithBloomFilter, using the default hasher, may contain
+// every element to find.
+func maybeContainsAll[T comparable](offered, find []T) bool {
+	q := queue.NewBlocking(offered, queue.WithBloomFilter(100, 0.01))
+
+	for _, elem := range find {
+		if !q.MaybeContains(elem) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testWithBloomFilterDerivedHasher(t *testing.T) {
+	t.Parallel()
+
+	type point struct{ X, Y int }
+
+	// mixed has a field of every comparable kind.
+	type mixed struct {
+		B   bool
+		I8  int8
+		U16 uint16
+		P   uintptr
+		F   float64
+		C   complex128
+		S   string
+		Ptr *int
+		Ch  chan int
+		UP  unsafe.Pointer
+		Any any
+		Nil any
+		Arr [2]point
+	}
+
+	negativeZero := math.Copysign(0, -1)
+
+	i, ch := 1, make(chan int)
+
+	newMixed := func(zero float64) mixed {
+		return mixed{
+			true, -1, 2, 3, zero, complex(zero, zero), "s",
+			&i, ch, unsafe.Pointer(&i), point{1, 2}, nil, [2]point{{1, 2}},
+		}
+	}
+
+	for name, ok := range map[string]bool{
+		"String":  maybeContainsAll([]string{"a", "b"}, []string{"a", "b"}),
+		"Int":     maybeContainsAll([]int{-1, 2}, []int{-1, 2}),
+		"Int64":   maybeContainsAll([]int64{-1, 2}, []int64{-1, 2}),
+		"Int32":   maybeContainsAll([]int32{-1, 2}, []int32{-1, 2}),
+		"Uint":    maybeContainsAll([]uint{1, 2}, []uint{1, 2}),
+		"Uint64":  maybeContainsAll([]uint64{1, 2}, []uint64{1, 2}),
+		"Uint32":  maybeContainsAll([]uint32{1, 2}, []uint32{1, 2}),
+		"Float64": maybeContainsAll([]float64{negativeZero, 1.5}, []float64{0, 1.5}),
+		"Float32": maybeContainsAll([]float32{float32(negativeZero), 1.5}, []float32{0, 1.5}),
+		"Struct":  maybeContainsAll([]point{{1, 2}}, []point{{1, 2}}),
+		"Mixed":   maybeContainsAll([]mixed{newMixed(negativeZero)}, []mixed{newMixed(0)}),
+		"Nil":     maybeContainsAll([]any{nil, 1}, []any{nil, 1}),
+	} {
+		if !ok {
+			t.Fatalf("%s: expected equal elements to match", name)
+		}
+	}
+}
+
+func testWithBloomFilterMutatedPointers(t *testing.T) {
+	t.Parallel()
+
+	type job struct{ attempts int }
+
+	a, b := &job{}, &job{}
+
+	bq := queue.NewBlocking([]*job{a, b}, queue.WithBloomFilter(100, 0.001))
+
+	// Pointers are equal whatever they point to, and so are their hashes.
+	a.attempts++
+	b.attempts++
+
+	if !bq.MaybeContains(a) || !bq.Contains(b) {
+		t.Fatal("expected the mutated elements to be found")
+	}
+
+	// Removing a mutated element clears its own counters.
+	_, _ = bq.Get()
+	_, _ = bq.Get()
+
+	if bq.MaybeContains(a) || bq.MaybeContains(b) {
+		t.Fatal("expected the elements to be removed")
+	}
+}
@@ -25,12 +25,18 @@ type Circular[T comparable] struct {
 	head            int
 	tail            int
 	size            int
+	// shared is set while a Snapshot may share elems; see unshare.
+	shared bool
+	// members is set WithUnique, WithIndex or WithBloomFilter; see
+	// membership.
+	members *membership[T]
 
 	// synchronization
 	lock sync.RWMutex
 }
 
 // NewCircular creates a new Circular Queue containing the given elements.
+// Panics if the capacity is not positive.
 func NewCircular[T comparable](
 	givenElems []T,
 	capacity int,
@@ -48,6 +54,10 @@ func NewCircular[T comparable](
 		panic("capacity must be positive")
 	}
 
+	if options.unique {
+		givenElems = distinct(givenElems)
+	}
+
 	elems := make([]T, *options.capacity)
 
 	copy(elems, givenElems)
@@ -72,29 +82,45 @@ func NewCircular[T comparable](
 		size = len(initialElems)
 	}
 
-	return &Circular[T]{
+	queue := &Circular[T]{
 		initialElements: initialElems,
 		elems:           elems,
 		head:            0,
 		tail:            tail,
 		size:            size,
 		lock:            sync.RWMutex{},
+		members:         newMembership[T](options),
 	}
+
+	queue.members.reset(initialElems)
+
+	return queue
 }
 
 // ==================================Insertion=================================
 
 // Offer adds an element into the queue.
 // If the queue is full then the oldest item is overwritten.
+// If the queue was created WithUnique and already contains the element,
+// it returns the ErrDuplicate error instead, and overwrites nothing.
 func (q *Circular[T]) Offer(item T) error {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
+	if !q.members.admits(item) {
+		return ErrDuplicate
+	}
+
 	if q.size < len(q.elems) {
 		q.size++
+	} else {
+		// The slot to overwrite holds the oldest element.
+		q.unshare()
+		q.members.remove(q.elems[q.tail])
 	}
 
 	q.elems[q.tail] = item
+	q.members.add(item)
 	q.tail = (q.tail + 1) % len(q.elems)
 
 	return nil
@@ -105,6 +131,11 @@ func (q *Circular[T]) Reset() {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
+	if q.shared {
+		q.elems = make([]T, len(q.elems))
+		q.shared = false
+	}
+
 	copy(q.elems, q.initialElements)
 
 	// Drop references in any slot past the initial set; otherwise pointer
@@ -119,6 +150,7 @@ func (q *Circular[T]) Reset() {
 	q.head = 0
 	q.tail = 0
 	q.size = len(q.initialElements)
+	q.members.reset(q.initialElements)
 
 	if len(q.initialElements) < len(q.elems) {
 		q.tail = len(q.initialElements)
@@ -184,10 +216,15 @@ func (q *Circular[T]) IsEmpty() bool {
 }
 
 // Contains returns true if the queue contains the given element.
+// See WithIndex and WithBloomFilter for how they speed it up.
 func (q *Circular[T]) Contains(elem T) bool {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
 
+	if found, sure := q.members.lookup(elem); sure {
+		return found
+	}
+
 	if q.isEmpty() {
 		return false
 	}
@@ -214,6 +251,43 @@ func (q *Circular[T]) Contains(elem T) bool {
 	return false
 }
 
+// MaybeContains reports whether the queue may contain the given element.
+// It never returns false for an element the queue contains; see
+// WithBloomFilter for when it returns true for one it does not.
+func (q *Circular[T]) MaybeContains(elem T) bool {
+	if q.members == nil {
+		return q.Contains(elem)
+	}
+
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	found, _ := q.members.lookup(elem)
+
+	return found
+}
+
+// Count returns the number of times the given element is in the queue.
+// See WithIndex and WithBloomFilter for how they speed it up.
+func (q *Circular[T]) Count(elem T) int {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	if n, sure := q.members.count(elem); sure {
+		return n
+	}
+
+	n := 0
+
+	for i := 0; i < q.size; i++ {
+		if q.elems[(q.head+i)%len(q.elems)] == elem {
+			n++
+		}
+	}
+
+	return n
+}
+
 // Peek returns the element at the head of the queue.
 func (q *Circular[T]) Peek() (v T, _ error) {
 	q.lock.RLock()
@@ -226,6 +300,27 @@ func (q *Circular[T]) Peek() (v T, _ error) {
 	return q.elems[q.head], nil
 }
 
+// Snapshot returns an immutable, point-in-time view of the elements in
+// the queue. It runs in O(1) and reading the snapshot does not take the
+// queue lock; the next call that overwrites or removes an element pays
+// for a copy of the ring instead.
+func (q *Circular[T]) Snapshot() *Snapshot[T] {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.shared = true
+
+	firstChunk := len(q.elems) - q.head
+	if firstChunk > q.size {
+		firstChunk = q.size
+	}
+
+	return sliceSnapshot(
+		q.elems[q.head:q.head+firstChunk],
+		q.elems[:q.size-firstChunk],
+	)
+}
+
 // Size returns the number of elements in the queue.
 func (q *Circular[T]) Size() int {
 	q.lock.RLock()
@@ -248,14 +343,32 @@ func (q *Circular[T]) get() (v T, _ error) {
 }
 
 func (q *Circular[T]) pop() (v T) {
+	q.unshare()
+
 	item := q.elems[q.head]
 	q.elems[q.head] = *new(T) // clear popped slot for garbage collection
 	q.head = (q.head + 1) % len(q.elems)
 	q.size--
+	q.members.remove(item)
 
 	return item
 }
 
+// unshare copies the ring into a new slice if a Snapshot shares the
+// current one, before the caller overwrites an element. Offers into free
+// slots do not need it, as a snapshot only reads the occupied ones.
+func (q *Circular[T]) unshare() {
+	if !q.shared {
+		return
+	}
+
+	elems := make([]T, len(q.elems))
+	copy(elems, q.elems)
+
+	q.elems = elems
+	q.shared = false
+}
+
 // isEmpty returns true if the queue is empty.
 func (q *Circular[T]) isEmpty() bool {
 	return q.size == 0
@@ -0,0 +1,351 @@
+package queue
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Ensure Coalescing implements the Queue interface.
+var _ Queue[any] = (*Coalescing[any, any])(nil)
+
+// Coalescing is a FIFO Queue implementation that keeps at most one element
+// per key, as computed by a caller-supplied function, e.g. to skip stale
+// cache invalidations. Offering an element whose key is already queued
+// replaces the queued element in place, keeping its position, so the
+// consumer only sees the latest element for that key. With WithMerge, the
+// two elements are combined instead.
+//
+// Like Blocking, it supports operations that wait for the queue to have
+// available elements, and wait for a free slot in case it is full. An
+// element that coalesces with a queued one never needs a free slot.
+// Contains runs in O(1).
+type Coalescing[K comparable, T comparable] struct {
+	keyFunc   func(T) K
+	mergeFunc func(queued, offered T) T
+
+	initialElems []T
+	// keys holds the keys of the queued elements in FIFO order, and
+	// elems the element queued for each of them.
+	keys     []K
+	elems    map[K]T
+	capacity *int
+
+	// synchronization
+	lock         sync.RWMutex
+	notEmptyCond *sync.Cond
+	notFullCond  *sync.Cond
+}
+
+// NewCoalescing returns a new Coalescing queue containing the given
+// elements, offered in order, so that elements with the same key coalesce.
+// Panics if keyFunc is nil, WithCapacity is negative or the WithMerge
+// function does not take T.
+func NewCoalescing[K comparable, T comparable](
+	elems []T,
+	keyFunc func(T) K,
+	opts ...Option,
+) *Coalescing[K, T] {
+	if keyFunc == nil {
+		panic("nil key func")
+	}
+
+	options := options{
+		capacity: nil,
+		merge:    nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	var mergeFunc func(T, T) T
+
+	if options.merge != nil {
+		fn, ok := options.merge.(func(T, T) T)
+		if !ok {
+			panic("merge func does not match the element type")
+		}
+
+		mergeFunc = fn
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	cq := &Coalescing[K, T]{
+		keyFunc:      keyFunc,
+		mergeFunc:    mergeFunc,
+		initialElems: initialElems,
+		capacity:     options.capacity,
+	}
+
+	cq.notEmptyCond = sync.NewCond(&cq.lock)
+	cq.notFullCond = sync.NewCond(&cq.lock)
+
+	cq.reset()
+
+	return cq
+}
+
+// ==================================Insertion=================================
+
+// OfferWait inserts the element to the tail of the queue, or coalesces it
+// with the queued element of the same key.
+// It waits for necessary space to become available.
+func (cq *Coalescing[K, T]) OfferWait(elem T) {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	key := cq.keyFunc(elem)
+
+	for !cq.coalesces(key) && cq.isFull() {
+		cq.notFullCond.Wait()
+	}
+
+	cq.offer(key, elem)
+}
+
+// Offer inserts the element to the tail of the queue, or coalesces it with
+// the queued element of the same key.
+// If the element does not coalesce and the queue is full it returns the
+// ErrQueueIsFull error.
+func (cq *Coalescing[K, T]) Offer(elem T) error {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	key := cq.keyFunc(elem)
+
+	if !cq.coalesces(key) && cq.isFull() {
+		return ErrQueueIsFull
+	}
+
+	cq.offer(key, elem)
+
+	return nil
+}
+
+// Reset sets the queue to its initial state with the original elements.
+func (cq *Coalescing[K, T]) Reset() {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	cq.reset()
+
+	cq.notEmptyCond.Broadcast()
+	cq.notFullCond.Broadcast()
+}
+
+// ===================================Removal==================================
+
+// GetWait removes and returns the head of the queue.
+// If no element is available it waits until the queue
+// has an element available.
+func (cq *Coalescing[K, T]) GetWait() T {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	for cq.isEmpty() {
+		cq.notEmptyCond.Wait()
+	}
+
+	return cq.get()
+}
+
+// Get removes and returns the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (cq *Coalescing[K, T]) Get() (v T, _ error) {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	if cq.isEmpty() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return cq.get(), nil
+}
+
+// Clear removes and returns all elements from the queue.
+func (cq *Coalescing[K, T]) Clear() []T {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	defer cq.notFullCond.Broadcast()
+
+	removed := cq.ordered()
+
+	cq.keys = nil
+	cq.elems = make(map[K]T)
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this queue.
+// It removes the elements from the queue.
+func (cq *Coalescing[K, T]) Iterator() <-chan T {
+	elems := cq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (cq *Coalescing[K, T]) Peek() (v T, _ error) {
+	cq.lock.RLock()
+	defer cq.lock.RUnlock()
+
+	if cq.isEmpty() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return cq.elems[cq.keys[0]], nil
+}
+
+// PeekWait retrieves but does not remove the head of the queue.
+// If no element is available it waits until the queue
+// has an element available.
+func (cq *Coalescing[K, T]) PeekWait() T {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	for cq.isEmpty() {
+		cq.notEmptyCond.Wait()
+	}
+
+	return cq.elems[cq.keys[0]]
+}
+
+// Size returns the number of elements in the queue.
+func (cq *Coalescing[K, T]) Size() int {
+	cq.lock.RLock()
+	defer cq.lock.RUnlock()
+
+	return len(cq.keys)
+}
+
+// Contains returns true if the queue contains the given element. It looks
+// up the element by its key, in O(1).
+func (cq *Coalescing[K, T]) Contains(elem T) bool {
+	cq.lock.RLock()
+	defer cq.lock.RUnlock()
+
+	queued, ok := cq.elems[cq.keyFunc(elem)]
+
+	return ok && queued == elem
+}
+
+// IsEmpty returns true if the queue is empty.
+func (cq *Coalescing[K, T]) IsEmpty() bool {
+	cq.lock.RLock()
+	defer cq.lock.RUnlock()
+
+	return cq.isEmpty()
+}
+
+// MarshalJSON serializes the Coalescing queue to JSON.
+func (cq *Coalescing[K, T]) MarshalJSON() ([]byte, error) {
+	cq.lock.RLock()
+	elems := cq.ordered()
+	cq.lock.RUnlock()
+
+	return json.Marshal(elems)
+}
+
+// ===================================Helpers==================================
+
+// isEmpty returns true if the queue is empty.
+func (cq *Coalescing[K, T]) isEmpty() bool {
+	return len(cq.keys) == 0
+}
+
+// isFull returns true if the queue is full.
+func (cq *Coalescing[K, T]) isFull() bool {
+	if cq.capacity == nil {
+		return false
+	}
+
+	return len(cq.keys) >= *cq.capacity
+}
+
+// coalesces reports whether an element with the key is queued.
+func (cq *Coalescing[K, T]) coalesces(key K) bool {
+	_, ok := cq.elems[key]
+
+	return ok
+}
+
+// offer coalesces the element with the queued element of the same key or
+// appends it. Caller must hold the lock and make room for the element.
+func (cq *Coalescing[K, T]) offer(key K, elem T) {
+	queued, ok := cq.elems[key]
+
+	switch {
+	case !ok:
+		cq.keys = append(cq.keys, key)
+	case cq.mergeFunc != nil:
+		elem = cq.mergeFunc(queued, elem)
+	}
+
+	cq.elems[key] = elem
+
+	cq.notEmptyCond.Broadcast()
+}
+
+// get removes and returns the head of the queue. The queue must be
+// non-empty and the caller must hold the lock.
+func (cq *Coalescing[K, T]) get() T {
+	key := cq.keys[0]
+	elem := cq.elems[key]
+
+	// Zero the popped slot so the backing array no longer references the
+	// popped key.
+	var zero K
+
+	cq.keys[0] = zero
+	cq.keys = cq.keys[1:]
+
+	delete(cq.elems, key)
+
+	cq.notFullCond.Broadcast()
+
+	return elem
+}
+
+// ordered returns the queued elements in FIFO order.
+func (cq *Coalescing[K, T]) ordered() []T {
+	elems := make([]T, len(cq.keys))
+
+	for i, key := range cq.keys {
+		elems[i] = cq.elems[key]
+	}
+
+	return elems
+}
+
+// reset offers the initial elements to an empty queue, dropping those past
+// the capacity. Caller must hold the lock.
+func (cq *Coalescing[K, T]) reset() {
+	cq.keys = nil
+	cq.elems = make(map[K]T, len(cq.initialElems))
+
+	for _, elem := range cq.initialElems {
+		key := cq.keyFunc(elem)
+
+		if cq.coalesces(key) || !cq.isFull() {
+			cq.offer(key, elem)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+// update is a versioned update of a key, coalescing by key.
+type update struct {
+	Key     string
+	Version int
+}
+
+func updateKey(u update) string {
+	return u.Key
+}
+
+func TestCoalescing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InvalidArguments", testCoalescingInvalidArguments)
+	t.Run("ReplacesInPlace", testCoalescingReplacesInPlace)
+	t.Run("Merge", testCoalescingMerge)
+	t.Run("Capacity", testCoalescingCapacity)
+	t.Run("Empty", testCoalescingEmpty)
+	t.Run("OfferWait", testCoalescingOfferWait)
+	t.Run("GetWaitAndPeekWait", testCoalescingGetWaitAndPeekWait)
+	t.Run("Contains", testCoalescingContains)
+	t.Run("ClearAndIterator", testCoalescingClearAndIterator)
+	t.Run("Reset", testCoalescingReset)
+	t.Run("MarshalJSON", testCoalescingMarshalJSON)
+}
+
+func testCoalescingInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		want string
+		new  func()
+	}{
+		"NilKeyFunc": {
+			want: "nil key func",
+			new:  func() { queue.NewCoalescing[string, update](nil, nil) },
+		},
+		"NegativeCapacity": {
+			want: negativeCapacityPanic,
+			new:  func() { queue.NewCoalescing(nil, updateKey, queue.WithCapacity(-1)) },
+		},
+		"MergeTypeMismatch": {
+			want: "merge func does not match the element type",
+			new: func() {
+				queue.NewCoalescing(nil, updateKey, queue.WithMerge(func(a, _ int) int { return a }))
+			},
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if p := recover(); p != c.want {
+					t.Fatalf("expected panic %q, got %v", c.want, p)
+				}
+			}()
+
+			c.new()
+		})
+	}
+}
+
+func testCoalescingReplacesInPlace(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing([]update{{"a", 1}, {"b", 1}}, updateKey)
+
+	for _, u := range []update{{"a", 2}, {"c", 1}, {"a", 3}} {
+		if err := cq.Offer(u); err != nil {
+			t.Fatalf("offer %v: %v", u, err)
+		}
+	}
+
+	if cq.Size() != 3 {
+		t.Fatalf("expected size 3, got %d", cq.Size())
+	}
+
+	for _, want := range []update{{"a", 3}, {"b", 1}, {"c", 1}} {
+		got, err := cq.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	// Once taken, the key is queued anew at the tail.
+	_ = cq.Offer(update{"b", 2})
+	_ = cq.Offer(update{"a", 4})
+
+	if got := cq.Clear(); !reflect.DeepEqual(got, []update{{"b", 2}, {"a", 4}}) {
+		t.Fatalf("expected [{b 2} {a 4}], got %v", got)
+	}
+}
+
+func testCoalescingMerge(t *testing.T) {
+	t.Parallel()
+
+	// Sum the versions of the updates of a key.
+	cq := queue.NewCoalescing(
+		[]update{{"a", 1}, {"a", 2}},
+		updateKey,
+		queue.WithMerge(func(queued, offered update) update {
+			return update{queued.Key, queued.Version + offered.Version}
+		}),
+	)
+
+	_ = cq.Offer(update{"a", 3})
+
+	if got, _ := cq.Peek(); got != (update{"a", 6}) {
+		t.Fatalf("expected {a 6}, got %v", got)
+	}
+}
+
+func testCoalescingCapacity(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing(
+		[]update{{"a", 1}, {"a", 2}, {"b", 1}, {"c", 1}},
+		updateKey,
+		queue.WithCapacity(2),
+	)
+
+	if got := cq.Clear(); !reflect.DeepEqual(got, []update{{"a", 2}, {"b", 1}}) {
+		t.Fatalf("expected [{a 2} {b 1}], got %v", got)
+	}
+
+	cq.Reset()
+
+	if err := cq.Offer(update{"c", 1}); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+
+	// Coalescing does not need a free slot.
+	if err := cq.Offer(update{"b", 2}); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	cq.OfferWait(update{"a", 3})
+
+	if got := cq.Clear(); !reflect.DeepEqual(got, []update{{"a", 3}, {"b", 2}}) {
+		t.Fatalf("expected [{a 3} {b 2}], got %v", got)
+	}
+}
+
+func testCoalescingEmpty(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing[string, update](nil, updateKey)
+
+	if _, err := cq.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := cq.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !cq.IsEmpty() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testCoalescingOfferWait(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing([]update{{"a", 1}}, updateKey, queue.WithCapacity(1))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+
+		_, _ = cq.Get()
+	}()
+
+	cq.OfferWait(update{"b", 1})
+
+	if got, _ := cq.Peek(); got != (update{"b", 1}) {
+		t.Fatalf("expected {b 1}, got %v", got)
+	}
+}
+
+func testCoalescingGetWaitAndPeekWait(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing[string, update](nil, updateKey)
+
+	offerLater := func(u update) {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			_ = cq.Offer(u)
+		}()
+	}
+
+	offerLater(update{"a", 1})
+
+	if got := cq.GetWait(); got != (update{"a", 1}) {
+		t.Fatalf("get: expected {a 1}, got %v", got)
+	}
+
+	offerLater(update{"b", 1})
+
+	if got := cq.PeekWait(); got != (update{"b", 1}) {
+		t.Fatalf("peek: expected {b 1}, got %v", got)
+	}
+}
+
+func testCoalescingContains(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing([]update{{"a", 1}, {"a", 2}}, updateKey)
+
+	if !cq.Contains(update{"a", 2}) {
+		t.Fatal("expected the queue to contain {a 2}")
+	}
+
+	// {a 1} was replaced by {a 2}, and b was never queued.
+	if cq.Contains(update{"a", 1}) || cq.Contains(update{"b", 2}) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testCoalescingClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing([]update{{"a", 1}, {"b", 1}}, updateKey)
+
+	var got []update
+
+	for u := range cq.Iterator() {
+		got = append(got, u)
+	}
+
+	if !reflect.DeepEqual(got, []update{{"a", 1}, {"b", 1}}) {
+		t.Fatalf("expected [{a 1} {b 1}], got %v", got)
+	}
+
+	if !cq.IsEmpty() || cq.Contains(update{"a", 1}) {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testCoalescingReset(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing([]update{{"a", 1}, {"b", 1}}, updateKey)
+
+	_, _ = cq.Get()
+	_ = cq.Offer(update{"b", 2})
+	_ = cq.Offer(update{"c", 1})
+
+	cq.Reset()
+
+	if got := cq.Clear(); !reflect.DeepEqual(got, []update{{"a", 1}, {"b", 1}}) {
+		t.Fatalf("expected [{a 1} {b 1}], got %v", got)
+	}
+}
+
+func testCoalescingMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCoalescing([]update{{"a", 1}, {"b", 1}, {"a", 2}}, updateKey)
+
+	data, err := cq.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	want := `[{"Key":"a","Version":2},{"Key":"b","Version":1}]`
+
+	if string(data) != want {
+		t.Fatalf("expected %s, got %s", want, data)
+	}
+}
@@ -0,0 +1,370 @@
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// dagState is the state of an element of a DAG.
+type dagState int
+
+const (
+	// dagUnknown elements were not offered yet, but other elements
+	// depend on them.
+	dagUnknown dagState = iota
+	// dagPending elements wait for some of their dependencies.
+	dagPending
+	// dagReady elements can be taken by Get.
+	dagReady
+	// dagRunning elements were taken by Get and are not done yet.
+	dagRunning
+	// dagDone elements were marked done.
+	dagDone
+)
+
+// dagNode is an element of a DAG with its edges.
+type dagNode[T comparable] struct {
+	state dagState
+	// deps are the dependencies the element was offered with that are
+	// not done yet.
+	deps []T
+	// waiting is the number of deps that are not done yet.
+	waiting int
+	// dependents are the elements that wait for this one and are not
+	// done yet.
+	dependents []T
+}
+
+// DAG is a dependency-aware task queue: every element is offered with the
+// elements it depends on, and only becomes available to Get once all of
+// them were marked done with Done, e.g. to run build steps in topological
+// order with a pool of workers.
+//
+// Available elements are returned in the order they became available.
+// A dependency does not have to be offered before the elements that
+// depend on it, or at all: Done can mark any element done, which lets
+// elements depend on work tracked outside the DAG. Offer returns ErrCycle
+// instead of registering an element whose dependencies depend on it.
+//
+// A DAG is not a Queue, as Offer takes dependencies. Done elements are
+// remembered, so that later elements can depend on them, which takes
+// memory for every element ever marked done: a long-running DAG should
+// Forget the done elements nothing will depend on anymore.
+type DAG[T comparable] struct {
+	nodes map[T]*dagNode[T]
+	ready []T
+	// remaining is the number of offered elements that are not done.
+	remaining int
+
+	lock     sync.Mutex
+	notEmpty *sync.Cond
+}
+
+// NewDAG returns a new, empty DAG.
+func NewDAG[T comparable]() *DAG[T] {
+	dq := &DAG[T]{
+		nodes: make(map[T]*dagNode[T]),
+	}
+
+	dq.notEmpty = sync.NewCond(&dq.lock)
+
+	return dq
+}
+
+// ==================================Insertion=================================
+
+// Offer registers the element with the elements it depends on. It becomes
+// available to Get once every dependency is done, right away if there is
+// none.
+// It returns ErrDuplicate if the element was already offered or marked
+// done, and ErrCycle if one of the dependencies depends on the element.
+func (dq *DAG[T]) Offer(elem T, deps ...T) error {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	n, ok := dq.nodes[elem]
+
+	if ok && n.state != dagUnknown {
+		return ErrDuplicate
+	}
+
+	if dq.reaches(deps, elem) {
+		return ErrCycle
+	}
+
+	if !ok {
+		n = &dagNode[T]{}
+		dq.nodes[elem] = n
+	}
+
+	n.state = dagPending
+	n.deps = make([]T, 0, len(deps))
+
+	for _, dep := range deps {
+		d := dq.node(dep)
+
+		if d.state == dagDone || containsElem(n.deps, dep) {
+			continue
+		}
+
+		n.deps = append(n.deps, dep)
+		n.waiting++
+		d.dependents = append(d.dependents, elem)
+	}
+
+	dq.remaining++
+
+	if n.waiting == 0 {
+		dq.release(elem, n)
+	}
+
+	return nil
+}
+
+// Done marks the element as done, making available the elements that only
+// waited for it. The element does not have to be offered: marking an
+// unknown element done satisfies the elements depending on it. An element
+// marked done before Get returned it is never returned. Marking an
+// element done again has no effect.
+func (dq *DAG[T]) Done(elem T) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	n := dq.node(elem)
+
+	switch n.state {
+	case dagDone:
+		return
+	case dagUnknown:
+		// Not offered, so not counted in remaining.
+	case dagReady:
+		dq.unready(elem)
+		dq.remaining--
+	default:
+		dq.remaining--
+	}
+
+	n.state = dagDone
+
+	// The element no longer waits for its dependencies, which only keep
+	// track of elements that are not done.
+	for _, dep := range n.deps {
+		d := dq.nodes[dep]
+
+		d.dependents = removeElem(d.dependents, elem)
+	}
+
+	n.deps = nil
+
+	for _, dependent := range n.dependents {
+		d := dq.nodes[dependent]
+
+		d.deps = removeElem(d.deps, elem)
+		d.waiting--
+
+		if d.waiting == 0 && d.state == dagPending {
+			dq.release(dependent, d)
+		}
+	}
+
+	n.dependents = nil
+}
+
+// Forget drops a done element from the DAG, so that it no longer takes
+// memory. Elements offered afterwards that depend on it wait for it to be
+// marked done again, and it can be offered again. Elements that were
+// already waiting for it are not affected.
+// It reports whether the element was done, and so was forgotten.
+func (dq *DAG[T]) Forget(elem T) bool {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if n, ok := dq.nodes[elem]; !ok || n.state != dagDone {
+		return false
+	}
+
+	delete(dq.nodes, elem)
+
+	return true
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the element that became available first, which
+// the caller must mark done with Done once it has been processed.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (dq *DAG[T]) Get() (v T, _ error) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if len(dq.ready) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return dq.get(), nil
+}
+
+// GetWait is like Get, but waits until an element becomes available. If
+// ctx is done first, it returns the context's error.
+func (dq *DAG[T]) GetWait(ctx context.Context) (v T, _ error) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if len(dq.ready) == 0 {
+		stop := broadcastOnDone(ctx, dq.notEmpty)
+
+		for len(dq.ready) == 0 && ctx.Err() == nil {
+			dq.notEmpty.Wait()
+		}
+
+		stop()
+
+		if len(dq.ready) == 0 {
+			return v, ctx.Err()
+		}
+	}
+
+	return dq.get(), nil
+}
+
+// =================================Examination================================
+
+// Remaining returns, for every element that is not done, the dependencies
+// it still waits for, in the order they were offered. It includes the
+// elements other elements depend on that were never offered, with no
+// dependencies, which makes it useful to diagnose a stalled DAG.
+func (dq *DAG[T]) Remaining() map[T][]T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	remaining := make(map[T][]T)
+
+	for elem, n := range dq.nodes {
+		if n.state == dagDone {
+			continue
+		}
+
+		remaining[elem] = append([]T(nil), n.deps...)
+	}
+
+	return remaining
+}
+
+// Size returns the number of offered elements that are not done yet,
+// whether they are waiting, available or taken by Get.
+func (dq *DAG[T]) Size() int {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	return dq.remaining
+}
+
+// IsEmpty returns true if every offered element is done.
+func (dq *DAG[T]) IsEmpty() bool {
+	return dq.Size() == 0
+}
+
+// ===================================Helpers==================================
+
+// node returns the node of the element, registering an unknown one if
+// needed. Caller must hold the lock.
+func (dq *DAG[T]) node(elem T) *dagNode[T] {
+	n, ok := dq.nodes[elem]
+	if !ok {
+		n = &dagNode[T]{}
+		dq.nodes[elem] = n
+	}
+
+	return n
+}
+
+// reaches reports whether target is one of deps or one of their
+// dependencies that are not done, transitively. Caller must hold the lock.
+func (dq *DAG[T]) reaches(deps []T, target T) bool {
+	visited := make(map[T]struct{})
+	stack := append([]T(nil), deps...)
+
+	for len(stack) > 0 {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if elem == target {
+			return true
+		}
+
+		if _, ok := visited[elem]; ok {
+			continue
+		}
+
+		visited[elem] = struct{}{}
+
+		if n, ok := dq.nodes[elem]; ok && n.state != dagDone {
+			stack = append(stack, n.deps...)
+		}
+	}
+
+	return false
+}
+
+// release makes the element available to Get. Caller must hold the lock.
+func (dq *DAG[T]) release(elem T, n *dagNode[T]) {
+	n.state = dagReady
+	dq.ready = append(dq.ready, elem)
+
+	dq.notEmpty.Broadcast()
+}
+
+// get removes and returns the first available element. Caller must hold
+// the lock and there must be one.
+func (dq *DAG[T]) get() T {
+	elem := dq.ready[0]
+
+	var zero T
+
+	dq.ready[0] = zero
+	dq.ready = dq.ready[1:]
+
+	dq.nodes[elem].state = dagRunning
+
+	return elem
+}
+
+// unready removes the element from the available ones. Caller must hold
+// the lock and the element must be available.
+func (dq *DAG[T]) unready(elem T) {
+	i := 0
+	for dq.ready[i] != elem {
+		i++
+	}
+
+	last := len(dq.ready) - 1
+
+	copy(dq.ready[i:], dq.ready[i+1:])
+
+	var zero T
+
+	dq.ready[last] = zero
+	dq.ready = dq.ready[:last]
+}
+
+// removeElem removes the first occurrence of elem from elems, which must
+// hold it, keeping the order of the others.
+func removeElem[T comparable](elems []T, elem T) []T {
+	i := 0
+	for elems[i] != elem {
+		i++
+	}
+
+	return append(elems[:i], elems[i+1:]...)
+}
+
+// containsElem reports whether elems holds elem.
+func containsElem[T comparable](elems []T, elem T) bool {
+	for _, e := range elems {
+		if e == elem {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,388 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestDAG(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TopologicalOrder", testDAGTopologicalOrder)
+	t.Run("Duplicate", testDAGDuplicate)
+	t.Run("Cycle", testDAGCycle)
+	t.Run("ExternalDependency", testDAGExternalDependency)
+	t.Run("DoneBeforeGet", testDAGDoneBeforeGet)
+	t.Run("Remaining", testDAGRemaining)
+	t.Run("Forget", testDAGForget)
+	t.Run("GetWait", testDAGGetWait)
+	t.Run("GetWaitContextDone", testDAGGetWaitContextDone)
+	t.Run("Workers", testDAGWorkers)
+}
+
+func testDAGTopologicalOrder(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[string]()
+
+	// A diamond, offered out of order, with a repeated dependency.
+	for _, step := range []struct {
+		elem string
+		deps []string
+	}{
+		{"link", []string{"compile", "assets", "compile"}},
+		{"compile", []string{"fetch"}},
+		{"assets", []string{"fetch"}},
+		{"fetch", nil},
+	} {
+		if err := dag.Offer(step.elem, step.deps...); err != nil {
+			t.Fatalf("offer %s: %v", step.elem, err)
+		}
+	}
+
+	if dag.Size() != 4 {
+		t.Fatalf("expected size 4, got %d", dag.Size())
+	}
+
+	for _, wave := range [][]string{{"fetch"}, {"compile", "assets"}, {"link"}} {
+		var got []string
+
+		for {
+			elem, err := dag.Get()
+			if errors.Is(err, queue.ErrNoElementsAvailable) {
+				break
+			}
+
+			got = append(got, elem)
+		}
+
+		if !reflect.DeepEqual(got, wave) {
+			t.Fatalf("expected %v, got %v", wave, got)
+		}
+
+		for _, elem := range got {
+			dag.Done(elem)
+		}
+	}
+
+	if !dag.IsEmpty() {
+		t.Fatalf("expected an empty DAG, got size %d", dag.Size())
+	}
+
+	// Later elements can depend on done ones.
+	_ = dag.Offer("package", "link")
+
+	if got, _ := dag.Get(); got != "package" {
+		t.Fatalf("expected package, got %q", got)
+	}
+}
+
+func testDAGDuplicate(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[int]()
+
+	_ = dag.Offer(1)
+	dag.Done(2)
+
+	for _, elem := range []int{1, 2} {
+		if err := dag.Offer(elem); !errors.Is(err, queue.ErrDuplicate) {
+			t.Fatalf("offer %d: expected ErrDuplicate, got %v", elem, err)
+		}
+	}
+
+	// Marking an element done twice has no effect.
+	dag.Done(1)
+	dag.Done(1)
+
+	if !dag.IsEmpty() {
+		t.Fatalf("expected an empty DAG, got size %d", dag.Size())
+	}
+}
+
+func testDAGCycle(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[int]()
+
+	if err := dag.Offer(1, 1); !errors.Is(err, queue.ErrCycle) {
+		t.Fatalf("self dependency: expected ErrCycle, got %v", err)
+	}
+
+	// 1 -> 2 -> 3 and 1 -> 3: offering 3 depending on 1 closes a cycle,
+	// visiting 3 twice on the way.
+	_ = dag.Offer(1, 2, 3)
+	_ = dag.Offer(2, 3)
+
+	if err := dag.Offer(3, 4, 1); !errors.Is(err, queue.ErrCycle) {
+		t.Fatalf("expected ErrCycle, got %v", err)
+	}
+
+	if err := dag.Offer(3, 4); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	// A done element cannot be part of a cycle.
+	dag.Done(4)
+	dag.Done(3)
+
+	if err := dag.Offer(5, 2); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+}
+
+func testDAGExternalDependency(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[string]()
+
+	_ = dag.Offer("deploy", "approval")
+
+	if _, err := dag.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	dag.Done("approval")
+
+	if got, _ := dag.Get(); got != "deploy" {
+		t.Fatalf("expected deploy, got %q", got)
+	}
+
+	if dag.Size() != 1 {
+		t.Fatalf("expected size 1, got %d", dag.Size())
+	}
+}
+
+func testDAGDoneBeforeGet(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[int]()
+
+	_ = dag.Offer(1)
+	_ = dag.Offer(2)
+	_ = dag.Offer(3)
+	_ = dag.Offer(4, 5)
+
+	// Both the available 2 and the waiting 4 are skipped.
+	dag.Done(2)
+	dag.Done(4)
+	dag.Done(5)
+
+	var got []int
+
+	for {
+		elem, err := dag.Get()
+		if err != nil {
+			break
+		}
+
+		got = append(got, elem)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Fatalf("expected [1 3], got %v", got)
+	}
+
+	if dag.Size() != 2 {
+		t.Fatalf("expected size 2, got %d", dag.Size())
+	}
+}
+
+func testDAGRemaining(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[string]()
+
+	_ = dag.Offer("test", "build", "lint")
+	_ = dag.Offer("lint")
+	_ = dag.Offer("done")
+
+	dag.Done("lint")
+	dag.Done("done")
+
+	want := map[string][]string{
+		"test":  {"build"},
+		"build": nil,
+	}
+
+	if got := dag.Remaining(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func testDAGForget(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[string]()
+
+	_ = dag.Offer("build")
+	_ = dag.Offer("lint")
+	_ = dag.Offer("test", "build", "lint")
+
+	if dag.Forget("build") {
+		t.Fatal("expected an element that is not done to be kept")
+	}
+
+	dag.Done("build")
+
+	if !dag.Forget("build") || dag.Forget("build") {
+		t.Fatal("expected a done element to be forgotten once")
+	}
+
+	want := map[string][]string{
+		"lint": nil,
+		"test": {"lint"},
+	}
+
+	if got := dag.Remaining(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// "test" no longer waits for the forgotten element, so depending on
+	// "test" does not make a cycle.
+	if err := dag.Offer("build", "test"); err != nil {
+		t.Fatalf("offer forgotten element again: %v", err)
+	}
+
+	// Marking "test" done while it waits for "lint", which releases
+	// "build", and offering it again, makes it wait for "lint" only once.
+	dag.Done("test")
+
+	if !dag.Forget("test") {
+		t.Fatal("expected test to be forgotten")
+	}
+
+	if err := dag.Offer("test", "lint"); err != nil {
+		t.Fatalf("offer test again: %v", err)
+	}
+
+	dag.Done("lint")
+
+	for _, want := range []string{"build", "test"} {
+		if got, err := dag.Get(); err != nil || got != want {
+			t.Fatalf("expected %s, got %q, %v", want, got, err)
+		}
+	}
+
+	if got := dag.Size(); got != 2 {
+		t.Fatalf("expected 2 elements, got %d", got)
+	}
+}
+
+func testDAGGetWait(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[int]()
+
+	_ = dag.Offer(1)
+	_ = dag.Offer(2, 1)
+
+	if got, err := dag.GetWait(context.Background()); err != nil || got != 1 {
+		t.Fatalf("expected 1, got %d, %v", got, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+
+		dag.Done(1)
+	}()
+
+	if got, err := dag.GetWait(context.Background()); err != nil || got != 2 {
+		t.Fatalf("expected 2, got %d, %v", got, err)
+	}
+}
+
+func testDAGGetWaitContextDone(t *testing.T) {
+	t.Parallel()
+
+	dag := queue.NewDAG[int]()
+
+	_ = dag.Offer(2, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := dag.GetWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// testDAGWorkers runs a layered DAG with several workers and checks that
+// no element is returned before its dependencies are done.
+func testDAGWorkers(t *testing.T) {
+	t.Parallel()
+
+	const (
+		layers  = 10
+		width   = 10
+		workers = 4
+	)
+
+	dag := queue.NewDAG[int]()
+
+	for elem := 0; elem < layers*width; elem++ {
+		var deps []int
+
+		if layer := elem / width; layer > 0 {
+			for dep := (layer - 1) * width; dep < layer*width; dep++ {
+				deps = append(deps, dep)
+			}
+		}
+
+		_ = dag.Offer(elem, deps...)
+	}
+
+	var (
+		mu   sync.Mutex
+		done = make(map[int]bool)
+		wg   sync.WaitGroup
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				elem, err := dag.GetWait(ctx)
+				if err != nil {
+					return
+				}
+
+				mu.Lock()
+
+				for dep := (elem/width - 1) * width; elem >= width && dep < elem/width*width; dep++ {
+					if !done[dep] {
+						t.Errorf("%d returned before its dependency %d was done", elem, dep)
+					}
+				}
+
+				done[elem] = true
+
+				mu.Unlock()
+
+				dag.Done(elem)
+
+				if dag.IsEmpty() {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(done) != layers*width {
+		t.Fatalf("expected %d elements done, got %d", layers*width, len(done))
+	}
+}
@@ -100,11 +100,21 @@ var _ Queue[any] = (*Delay[any])(nil)
 //
 // Get returns ErrNoElementsAvailable if the queue is empty or the head's
 // deadline has not yet passed; GetWait sleeps until the head becomes due.
+//
+// WithTolerance widens "due" to every deadline within the tolerance of
+// now, so elements with nearby deadlines are released by a single wakeup
+// instead of one wakeup each. DrainDue and DrainDueWait hand over that
+// whole batch at once.
 type Delay[T comparable] struct {
 	deadlineFunc func(T) time.Time
 	items        *delayHeap[T]
 	initial      []T
 	capacity     *int
+	now          func() time.Time
+	tolerance    time.Duration
+	// members is set WithUnique, WithIndex or WithBloomFilter; see
+	// membership.
+	members *membership[T]
 
 	lock     sync.Mutex
 	notEmpty *sync.Cond
@@ -113,7 +123,8 @@ type Delay[T comparable] struct {
 // NewDelay creates a Delay queue. deadlineFunc is called at Offer and
 // Reset time to compute each element's deadline; the deadline is cached
 // per element (not re-evaluated on every Get).
-// Panics if deadlineFunc is nil or WithCapacity is negative.
+// Panics if deadlineFunc is nil, WithCapacity is negative or WithTolerance
+// is negative.
 func NewDelay[T comparable](
 	elems []T,
 	deadlineFunc func(T) time.Time,
@@ -123,7 +134,7 @@ func NewDelay[T comparable](
 		panic("nil deadline func")
 	}
 
-	options := options{capacity: nil}
+	options := options{capacity: nil, clock: time.Now}
 
 	for _, o := range opts {
 		o.apply(&options)
@@ -133,7 +144,15 @@ func NewDelay[T comparable](
 		panic("negative capacity")
 	}
 
+	if options.tolerance < 0 {
+		panic("negative tolerance")
+	}
+
 	effective := elems
+	if options.unique {
+		effective = distinct(effective)
+	}
+
 	if options.capacity != nil && *options.capacity < len(effective) {
 		effective = effective[:*options.capacity]
 	}
@@ -146,9 +165,13 @@ func NewDelay[T comparable](
 		items:        &delayHeap[T]{items: make([]delayed[T], 0, len(effective))},
 		initial:      initial,
 		capacity:     options.capacity,
+		now:          options.clock,
+		tolerance:    options.tolerance,
+		members:      newMembership[T](options),
 	}
 
 	dq.notEmpty = sync.NewCond(&dq.lock)
+	dq.members.reset(initial)
 
 	for _, e := range effective {
 		dq.items.push(delayed[T]{elem: e, deadline: deadlineFunc(e)})
@@ -160,11 +183,16 @@ func NewDelay[T comparable](
 // ==================================Insertion=================================
 
 // Offer inserts elem with deadline = deadlineFunc(elem).
-// Returns ErrQueueIsFull when constructed WithCapacity and already at limit.
+// Returns ErrQueueIsFull when constructed WithCapacity and already at limit,
+// and ErrDuplicate when constructed WithUnique and elem is already queued.
 func (dq *Delay[T]) Offer(elem T) error {
 	dq.lock.Lock()
 	defer dq.lock.Unlock()
 
+	if !dq.members.admits(elem) {
+		return ErrDuplicate
+	}
+
 	if dq.capacity != nil && dq.items.len() >= *dq.capacity {
 		return ErrQueueIsFull
 	}
@@ -173,6 +201,7 @@ func (dq *Delay[T]) Offer(elem T) error {
 		elem:     elem,
 		deadline: dq.deadlineFunc(elem),
 	})
+	dq.members.add(elem)
 
 	dq.notEmpty.Broadcast()
 
@@ -194,6 +223,8 @@ func (dq *Delay[T]) Reset() {
 		})
 	}
 
+	dq.members.reset(dq.initial)
+
 	dq.notEmpty.Broadcast()
 }
 
@@ -209,11 +240,11 @@ func (dq *Delay[T]) Get() (v T, _ error) {
 		return v, ErrNoElementsAvailable
 	}
 
-	if time.Now().Before(dq.items.items[0].deadline) {
+	if dq.untilDue(dq.now()) > 0 {
 		return v, ErrNoElementsAvailable
 	}
 
-	elem := dq.items.pop().elem
+	elem := dq.pop()
 
 	dq.notEmpty.Broadcast()
 
@@ -226,31 +257,37 @@ func (dq *Delay[T]) GetWait() T {
 	dq.lock.Lock()
 	defer dq.lock.Unlock()
 
-	for {
-		if dq.items.len() > 0 {
-			now := time.Now()
-			if !now.Before(dq.items.items[0].deadline) {
-				return dq.items.pop().elem
-			}
+	dq.waitDue()
 
-			// Head is not yet due: schedule a timer that Broadcasts when
-			// the deadline passes, then Wait. Any earlier Offer / Reset /
-			// Clear also Broadcasts, so we re-check on state changes too.
-			remaining := dq.items.items[0].deadline.Sub(now)
-			timer := time.AfterFunc(remaining, func() {
-				dq.lock.Lock()
-				dq.notEmpty.Broadcast()
-				dq.lock.Unlock()
-			})
+	return dq.pop()
+}
 
-			dq.notEmpty.Wait()
-			timer.Stop()
+// DrainDue removes and returns, in deadline order, every element that is
+// due, including those within the tolerance. It returns an empty slice if
+// no element is due. Never blocks.
+func (dq *Delay[T]) DrainDue() []T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
 
-			continue
-		}
+	out := dq.drainDue(dq.now())
 
-		dq.notEmpty.Wait()
+	if len(out) > 0 {
+		dq.notEmpty.Broadcast()
 	}
+
+	return out
+}
+
+// DrainDueWait blocks until the head becomes due, then removes and
+// returns every due element in deadline order, so that one wakeup
+// releases the whole batch. If the queue is empty, waits for an Offer.
+func (dq *Delay[T]) DrainDueWait() []T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	dq.waitDue()
+
+	return dq.drainDue(dq.now())
 }
 
 // Clear removes and returns all elements in deadline order.
@@ -262,7 +299,7 @@ func (dq *Delay[T]) Clear() []T {
 	out := make([]T, n)
 
 	for i := 0; i < n; i++ {
-		out[i] = dq.items.pop().elem
+		out[i] = dq.pop()
 	}
 
 	dq.notEmpty.Broadcast()
@@ -279,7 +316,7 @@ func (dq *Delay[T]) Iterator() <-chan T {
 	ch := make(chan T, dq.items.len())
 
 	for dq.items.len() > 0 {
-		ch <- dq.items.pop().elem
+		ch <- dq.pop()
 	}
 
 	close(ch)
@@ -318,10 +355,15 @@ func (dq *Delay[T]) IsEmpty() bool {
 }
 
 // Contains reports whether the given element is in the queue.
+// See WithIndex and WithBloomFilter for how they speed it up.
 func (dq *Delay[T]) Contains(elem T) bool {
 	dq.lock.Lock()
 	defer dq.lock.Unlock()
 
+	if found, sure := dq.members.lookup(elem); sure {
+		return found
+	}
+
 	for i := range dq.items.items {
 		if dq.items.items[i].elem == elem {
 			return true
@@ -331,6 +373,110 @@ func (dq *Delay[T]) Contains(elem T) bool {
 	return false
 }
 
+// MaybeContains reports whether the queue may contain the given element.
+// It never returns false for an element the queue contains; see
+// WithBloomFilter for when it returns true for one it does not.
+func (dq *Delay[T]) MaybeContains(elem T) bool {
+	if dq.members == nil {
+		return dq.Contains(elem)
+	}
+
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	found, _ := dq.members.lookup(elem)
+
+	return found
+}
+
+// Count returns the number of times the given element is in the queue,
+// due or not. See WithIndex and WithBloomFilter for how they speed it up.
+func (dq *Delay[T]) Count(elem T) int {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if n, sure := dq.members.count(elem); sure {
+		return n
+	}
+
+	n := 0
+
+	for i := range dq.items.items {
+		if dq.items.items[i].elem == elem {
+			n++
+		}
+	}
+
+	return n
+}
+
+// ===================================Helpers==================================
+
+// untilDue returns how long until the head becomes due, taking the
+// tolerance into account; zero or less means the head is due.
+// The queue must be non-empty.
+func (dq *Delay[T]) untilDue(now time.Time) time.Duration {
+	return dq.items.items[0].deadline.Sub(now) - dq.tolerance
+}
+
+// waitDue blocks until the queue is non-empty and its head is due.
+// Caller must hold the lock.
+func (dq *Delay[T]) waitDue() {
+	for {
+		if dq.items.len() > 0 {
+			now := dq.now()
+			if dq.untilDue(now) <= 0 {
+				return
+			}
+
+			// Head is not yet due: schedule a timer that Broadcasts when
+			// the deadline passes, then Wait. Any earlier Offer / Reset /
+			// Clear also Broadcasts, so we re-check on state changes too.
+			// The timer targets the head's own deadline rather than the
+			// start of its tolerance window, so that one wakeup covers
+			// every element due within the tolerance after it.
+			remaining := dq.items.items[0].deadline.Sub(now)
+			timer := time.AfterFunc(remaining, func() {
+				dq.lock.Lock()
+				dq.notEmpty.Broadcast()
+				dq.lock.Unlock()
+			})
+
+			dq.notEmpty.Wait()
+			timer.Stop()
+
+			continue
+		}
+
+		dq.notEmpty.Wait()
+	}
+}
+
+// pop removes and returns the head. The queue must be non-empty and the
+// caller must hold the lock.
+func (dq *Delay[T]) pop() T {
+	elem := dq.items.pop().elem
+	dq.members.remove(elem)
+
+	return elem
+}
+
+// drainDue pops every element that is due at now, in deadline order.
+// Caller must hold the lock.
+func (dq *Delay[T]) drainDue(now time.Time) []T {
+	var out []T
+
+	for dq.items.len() > 0 && dq.untilDue(now) <= 0 {
+		out = append(out, dq.pop())
+	}
+
+	if out == nil {
+		return []T{}
+	}
+
+	return out
+}
+
 // MarshalJSON serializes the Delay queue to JSON in deadline order.
 func (dq *Delay[T]) MarshalJSON() ([]byte, error) {
 	dq.lock.Lock()
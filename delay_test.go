@@ -40,6 +40,155 @@ func TestDelay(t *testing.T) {
 	t.Run("Reset", testDelayReset)
 	t.Run("MarshalJSON", testDelayMarshalJSON)
 	t.Run("CapacityLesserThanLenElems", testDelayCapacityLesserThanLenElems)
+	t.Run("WithClock", testDelayWithClock)
+	t.Run("NegativeTolerance", testDelayNegativeTolerance)
+	t.Run("WithTolerance", testDelayWithTolerance)
+	t.Run("DrainDue", testDelayDrainDue)
+	t.Run("DrainDueWait", testDelayDrainDueWait)
+}
+
+func testDelayNegativeTolerance(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "negative tolerance" {
+			t.Fatalf("expected panic 'negative tolerance', got %v", p)
+		}
+	}()
+
+	_ = queue.NewDelay(nil, delayedDeadline, queue.WithTolerance(-time.Second))
+}
+
+func testDelayWithTolerance(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	delayQueue := queue.NewDelay(
+		[]delayed{
+			{ID: 1, At: clock.Now().Add(4 * time.Millisecond)},
+			{ID: 2, At: clock.Now().Add(6 * time.Millisecond)},
+		},
+		delayedDeadline,
+		queue.WithClock(clock.Now),
+		queue.WithTolerance(5*time.Millisecond),
+	)
+
+	got, err := delayQueue.Get()
+	if err != nil {
+		t.Fatalf("get within tolerance: %v", err)
+	}
+
+	if got.ID != 1 {
+		t.Fatalf("got id=%d want 1", got.ID)
+	}
+
+	if _, err := delayQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable outside tolerance, got %v", err)
+	}
+}
+
+func testDelayDrainDue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithinTolerance", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		now := clock.Now()
+
+		delayQueue := queue.NewDelay(
+			[]delayed{
+				{ID: 3, At: now.Add(10 * time.Millisecond)},
+				{ID: 2, At: now.Add(2 * time.Millisecond)},
+				{ID: 1, At: now.Add(-time.Millisecond)},
+			},
+			delayedDeadline,
+			queue.WithClock(clock.Now),
+			queue.WithTolerance(5*time.Millisecond),
+		)
+
+		ids := delayedIDs(delayQueue.DrainDue())
+
+		expected := []int{1, 2}
+		if !reflect.DeepEqual(expected, ids) {
+			t.Fatalf("expected %v got %v", expected, ids)
+		}
+
+		if delayQueue.Size() != 1 {
+			t.Fatalf("size = %d want 1", delayQueue.Size())
+		}
+	})
+
+	t.Run("NothingDue", func(t *testing.T) {
+		t.Parallel()
+
+		delayQueue := queue.NewDelay(
+			[]delayed{{ID: 1, At: time.Now().Add(time.Hour)}},
+			delayedDeadline,
+		)
+
+		if got := delayQueue.DrainDue(); got == nil || len(got) != 0 {
+			t.Fatalf("expected empty non-nil slice, got %#v", got)
+		}
+	})
+}
+
+func testDelayDrainDueWait(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	delayQueue := queue.NewDelay(
+		[]delayed{
+			{ID: 3, At: now.Add(24 * time.Millisecond)},
+			{ID: 1, At: now.Add(20 * time.Millisecond)},
+			{ID: 2, At: now.Add(22 * time.Millisecond)},
+			{ID: 4, At: now.Add(time.Hour)},
+		},
+		delayedDeadline,
+		queue.WithTolerance(5*time.Millisecond),
+	)
+
+	ids := delayedIDs(delayQueue.DrainDueWait())
+
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(expected, ids) {
+		t.Fatalf("expected one batch %v got %v", expected, ids)
+	}
+
+	if elapsed := time.Since(now); elapsed < 20*time.Millisecond {
+		t.Fatalf("DrainDueWait returned before the head's deadline: %s", elapsed)
+	}
+}
+
+func delayedIDs(elems []delayed) []int {
+	ids := make([]int, len(elems))
+
+	for i, e := range elems {
+		ids[i] = e.ID
+	}
+
+	return ids
+}
+
+func testDelayWithClock(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	delayQueue := queue.NewDelay(
+		[]delayed{{ID: 1, At: clock.Now().Add(time.Hour)}},
+		delayedDeadline,
+		queue.WithClock(clock.Now),
+	)
+
+	if _, err := delayQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	clock.Advance(time.Hour)
+
+	if got := delayQueue.GetWait(); got.ID != 1 {
+		t.Fatalf("got id=%d want 1", got.ID)
+	}
 }
 
 func testDelayNilDeadlineFunc(t *testing.T) {
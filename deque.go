@@ -0,0 +1,452 @@
+package queue
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// minDequeBuf is the smallest ring allocated by a Deque. It must be a
+// power of two.
+const minDequeBuf = 8
+
+// Ensure Deque implements the Queue interface.
+var _ Queue[any] = (*Deque[any])(nil)
+
+// Deque is a double-ended Queue implementation backed by a growable ring
+// buffer: elements can be inserted and removed at both the front and the
+// back in amortized O(1).
+//
+// As a Queue it behaves like a FIFO queue: Offer pushes to the back, Get
+// and Peek work on the front. Every end has a blocking variant that waits
+// for an element, or for a free slot when the deque has a capacity.
+type Deque[T comparable] struct {
+	initialElems []T
+	// buf is the ring; its length is a power of two so that indexes wrap
+	// with a mask. The elements live at buf[head], ..., buf[head+size-1].
+	buf      []T
+	head     int
+	size     int
+	capacity *int
+
+	// synchronization
+	lock         sync.RWMutex
+	notEmptyCond *sync.Cond
+	notFullCond  *sync.Cond
+}
+
+// NewDeque returns a new Deque containing the given elements, front to
+// back. Panics if WithCapacity is negative.
+func NewDeque[T comparable](
+	elems []T,
+	opts ...Option,
+) *Deque[T] {
+	options := options{
+		capacity: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	if options.capacity != nil && len(elems) > *options.capacity {
+		elems = elems[:*options.capacity]
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	dq := &Deque[T]{
+		initialElems: initialElems,
+		capacity:     options.capacity,
+	}
+
+	dq.notEmptyCond = sync.NewCond(&dq.lock)
+	dq.notFullCond = sync.NewCond(&dq.lock)
+
+	dq.reset()
+
+	return dq
+}
+
+// ==================================Insertion=================================
+
+// PushFront inserts the element at the front of the deque.
+// If the deque is full it returns the ErrQueueIsFull error.
+func (dq *Deque[T]) PushFront(elem T) error {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.isFull() {
+		return ErrQueueIsFull
+	}
+
+	dq.pushFront(elem)
+
+	return nil
+}
+
+// PushBack inserts the element at the back of the deque.
+// If the deque is full it returns the ErrQueueIsFull error.
+func (dq *Deque[T]) PushBack(elem T) error {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.isFull() {
+		return ErrQueueIsFull
+	}
+
+	dq.pushBack(elem)
+
+	return nil
+}
+
+// PushFrontWait inserts the element at the front of the deque.
+// It waits for necessary space to become available.
+func (dq *Deque[T]) PushFrontWait(elem T) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	for dq.isFull() {
+		dq.notFullCond.Wait()
+	}
+
+	dq.pushFront(elem)
+}
+
+// PushBackWait inserts the element at the back of the deque.
+// It waits for necessary space to become available.
+func (dq *Deque[T]) PushBackWait(elem T) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	for dq.isFull() {
+		dq.notFullCond.Wait()
+	}
+
+	dq.pushBack(elem)
+}
+
+// Offer inserts the element at the back of the deque, like PushBack.
+// If the deque is full it returns the ErrQueueIsFull error.
+func (dq *Deque[T]) Offer(elem T) error {
+	return dq.PushBack(elem)
+}
+
+// Reset sets the deque to its initial state with the original elements.
+func (dq *Deque[T]) Reset() {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	dq.reset()
+
+	dq.notEmptyCond.Broadcast()
+	dq.notFullCond.Broadcast()
+}
+
+// ===================================Removal==================================
+
+// PopFront removes and returns the front of the deque.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (dq *Deque[T]) PopFront() (v T, _ error) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.size == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return dq.popFront(), nil
+}
+
+// PopBack removes and returns the back of the deque.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (dq *Deque[T]) PopBack() (v T, _ error) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.size == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return dq.popBack(), nil
+}
+
+// PopFrontWait removes and returns the front of the deque.
+// If no element is available it waits until the deque
+// has an element available.
+func (dq *Deque[T]) PopFrontWait() T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	for dq.size == 0 {
+		dq.notEmptyCond.Wait()
+	}
+
+	return dq.popFront()
+}
+
+// PopBackWait removes and returns the back of the deque.
+// If no element is available it waits until the deque
+// has an element available.
+func (dq *Deque[T]) PopBackWait() T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	for dq.size == 0 {
+		dq.notEmptyCond.Wait()
+	}
+
+	return dq.popBack()
+}
+
+// Get removes and returns the front of the deque, like PopFront.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (dq *Deque[T]) Get() (v T, _ error) {
+	return dq.PopFront()
+}
+
+// Clear removes and returns all elements from the deque, front to back.
+func (dq *Deque[T]) Clear() []T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	removed := dq.elems()
+
+	dq.buf = make([]T, minDequeBuf)
+	dq.head = 0
+	dq.size = 0
+
+	dq.notFullCond.Broadcast()
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this deque, front to
+// back. It removes the elements from the deque.
+func (dq *Deque[T]) Iterator() <-chan T {
+	elems := dq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// PeekFront retrieves but does not remove the front of the deque.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (dq *Deque[T]) PeekFront() (v T, _ error) {
+	dq.lock.RLock()
+	defer dq.lock.RUnlock()
+
+	if dq.size == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return dq.buf[dq.head], nil
+}
+
+// PeekBack retrieves but does not remove the back of the deque.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (dq *Deque[T]) PeekBack() (v T, _ error) {
+	dq.lock.RLock()
+	defer dq.lock.RUnlock()
+
+	if dq.size == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return dq.buf[dq.index(dq.size-1)], nil
+}
+
+// PeekFrontWait retrieves but does not remove the front of the deque.
+// If no element is available it waits until the deque
+// has an element available.
+func (dq *Deque[T]) PeekFrontWait() T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	for dq.size == 0 {
+		dq.notEmptyCond.Wait()
+	}
+
+	return dq.buf[dq.head]
+}
+
+// PeekBackWait retrieves but does not remove the back of the deque.
+// If no element is available it waits until the deque
+// has an element available.
+func (dq *Deque[T]) PeekBackWait() T {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	for dq.size == 0 {
+		dq.notEmptyCond.Wait()
+	}
+
+	return dq.buf[dq.index(dq.size-1)]
+}
+
+// Peek retrieves but does not remove the front of the deque, like
+// PeekFront.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (dq *Deque[T]) Peek() (v T, _ error) {
+	return dq.PeekFront()
+}
+
+// Size returns the number of elements in the deque.
+func (dq *Deque[T]) Size() int {
+	dq.lock.RLock()
+	defer dq.lock.RUnlock()
+
+	return dq.size
+}
+
+// IsEmpty returns true if the deque is empty.
+func (dq *Deque[T]) IsEmpty() bool {
+	return dq.Size() == 0
+}
+
+// Contains returns true if the deque contains the given element.
+func (dq *Deque[T]) Contains(elem T) bool {
+	dq.lock.RLock()
+	defer dq.lock.RUnlock()
+
+	for i := 0; i < dq.size; i++ {
+		if dq.buf[dq.index(i)] == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the Deque to JSON, front to back.
+func (dq *Deque[T]) MarshalJSON() ([]byte, error) {
+	dq.lock.RLock()
+	defer dq.lock.RUnlock()
+
+	return json.Marshal(dq.elems())
+}
+
+// ===================================Helpers==================================
+
+// index returns the ring position of the i-th element from the front.
+func (dq *Deque[T]) index(i int) int {
+	return (dq.head + i) & (len(dq.buf) - 1)
+}
+
+// isFull returns true if the deque is at capacity.
+func (dq *Deque[T]) isFull() bool {
+	if dq.capacity == nil {
+		return false
+	}
+
+	return dq.size >= *dq.capacity
+}
+
+// grow doubles the ring when it has no free slot left, moving the
+// elements to the start of the new ring.
+func (dq *Deque[T]) grow() {
+	if dq.size < len(dq.buf) {
+		return
+	}
+
+	buf := make([]T, len(dq.buf)*2) //nolint:mnd // doubling keeps pushes amortized O(1).
+
+	n := copy(buf, dq.buf[dq.head:])
+	copy(buf[n:], dq.buf[:dq.head])
+
+	dq.buf = buf
+	dq.head = 0
+}
+
+// pushFront inserts elem at the front. Caller must hold the lock and have
+// checked the capacity.
+func (dq *Deque[T]) pushFront(elem T) {
+	dq.grow()
+
+	dq.head = (dq.head - 1) & (len(dq.buf) - 1)
+	dq.buf[dq.head] = elem
+	dq.size++
+
+	dq.notEmptyCond.Broadcast()
+}
+
+// pushBack inserts elem at the back. Caller must hold the lock and have
+// checked the capacity.
+func (dq *Deque[T]) pushBack(elem T) {
+	dq.grow()
+
+	dq.buf[dq.index(dq.size)] = elem
+	dq.size++
+
+	dq.notEmptyCond.Broadcast()
+}
+
+// popFront removes and returns the front element. The deque must be
+// non-empty and the caller must hold the lock.
+func (dq *Deque[T]) popFront() T {
+	elem := dq.buf[dq.head]
+
+	// Zero the popped slot so the ring no longer references the element.
+	var zero T
+
+	dq.buf[dq.head] = zero
+	dq.head = dq.index(1)
+	dq.size--
+
+	dq.notFullCond.Broadcast()
+
+	return elem
+}
+
+// popBack removes and returns the back element. The deque must be
+// non-empty and the caller must hold the lock.
+func (dq *Deque[T]) popBack() T {
+	i := dq.index(dq.size - 1)
+	elem := dq.buf[i]
+
+	var zero T
+
+	dq.buf[i] = zero
+	dq.size--
+
+	dq.notFullCond.Broadcast()
+
+	return elem
+}
+
+// elems returns a copy of the elements, front to back.
+func (dq *Deque[T]) elems() []T {
+	out := make([]T, dq.size)
+
+	for i := range out {
+		out[i] = dq.buf[dq.index(i)]
+	}
+
+	return out
+}
+
+// reset replaces the elements with the initial ones. Caller must hold the
+// lock.
+func (dq *Deque[T]) reset() {
+	n := minDequeBuf
+	for n < len(dq.initialElems) {
+		n *= 2
+	}
+
+	dq.buf = make([]T, n)
+	dq.head = 0
+	dq.size = copy(dq.buf, dq.initialElems)
+}
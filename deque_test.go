@@ -0,0 +1,388 @@
+package queue_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestDeque(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NegativeCapacity", testDequeNegativeCapacity)
+	t.Run("BothEnds", testDequeBothEnds)
+	t.Run("Empty", testDequeEmpty)
+	t.Run("QueueInterface", testDequeQueueInterface)
+	t.Run("WithCapacity", testDequeWithCapacity)
+	t.Run("WaitFront", testDequeWaitFront)
+	t.Run("WaitBack", testDequeWaitBack)
+	t.Run("PushWait", testDequePushWait)
+	t.Run("GrowsAcrossWrap", testDequeGrowsAcrossWrap)
+	t.Run("MatchesModel", testDequeMatchesModel)
+	t.Run("Clear", testDequeClear)
+	t.Run("Iterator", testDequeIterator)
+	t.Run("Reset", testDequeReset)
+	t.Run("MarshalJSON", testDequeMarshalJSON)
+}
+
+func testDequeNegativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != negativeCapacityPanic {
+			t.Fatalf("expected panic %q, got %v", negativeCapacityPanic, p)
+		}
+	}()
+
+	_ = queue.NewDeque[int](nil, queue.WithCapacity(-1))
+}
+
+func testDequeBothEnds(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque([]int{2, 3})
+
+	if err := deque.PushFront(1); err != nil {
+		t.Fatalf("push front: %v", err)
+	}
+
+	if err := deque.PushBack(4); err != nil {
+		t.Fatalf("push back: %v", err)
+	}
+
+	if got, _ := deque.PeekFront(); got != 1 {
+		t.Fatalf("peek front: got %d want 1", got)
+	}
+
+	if got, _ := deque.PeekBack(); got != 4 {
+		t.Fatalf("peek back: got %d want 4", got)
+	}
+
+	if got, _ := deque.PopBack(); got != 4 {
+		t.Fatalf("pop back: got %d want 4", got)
+	}
+
+	if got, _ := deque.PopFront(); got != 1 {
+		t.Fatalf("pop front: got %d want 1", got)
+	}
+
+	if got := deque.Clear(); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Fatalf("expected [2 3], got %v", got)
+	}
+}
+
+func testDequeEmpty(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque[int](nil)
+
+	for name, op := range map[string]func() (int, error){
+		"PopFront":  deque.PopFront,
+		"PopBack":   deque.PopBack,
+		"PeekFront": deque.PeekFront,
+		"PeekBack":  deque.PeekBack,
+	} {
+		if _, err := op(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("%s: expected ErrNoElementsAvailable, got %v", name, err)
+		}
+	}
+
+	if !deque.IsEmpty() {
+		t.Fatal("expected empty deque")
+	}
+}
+
+func testDequeQueueInterface(t *testing.T) {
+	t.Parallel()
+
+	var q queue.Queue[int] = queue.NewDeque([]int{1})
+
+	if err := q.Offer(2); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if got, _ := q.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	if !q.Contains(2) || q.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+
+	if q.Size() != 2 {
+		t.Fatalf("expected size 2, got %d", q.Size())
+	}
+
+	for _, want := range []int{1, 2} {
+		got, err := q.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testDequeWithCapacity(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque([]int{1, 2, 3}, queue.WithCapacity(2))
+
+	if err := deque.PushFront(0); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("push front: expected ErrQueueIsFull, got %v", err)
+	}
+
+	if err := deque.PushBack(4); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("push back: expected ErrQueueIsFull, got %v", err)
+	}
+
+	if got := deque.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testDequeWaitFront(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque[int](nil)
+
+	expectDequeWait(t, "PeekFrontWait", deque.PeekFrontWait, func() { deque.PushBackWait(1) }, 1)
+
+	_ = deque.Clear()
+
+	expectDequeWait(t, "PopFrontWait", deque.PopFrontWait, func() { deque.PushBackWait(2) }, 2)
+}
+
+func testDequeWaitBack(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque[int](nil)
+
+	expectDequeWait(t, "PeekBackWait", deque.PeekBackWait, func() { deque.PushFrontWait(1) }, 1)
+
+	_ = deque.Clear()
+
+	expectDequeWait(t, "PopBackWait", deque.PopBackWait, func() { deque.PushFrontWait(2) }, 2)
+}
+
+// expectDequeWait starts wait on an empty deque, unblocks it with push,
+// and fails unless wait returns want shortly after.
+func expectDequeWait(t *testing.T, name string, wait func() int, push func(), want int) {
+	t.Helper()
+
+	result := make(chan int)
+
+	go func() { result <- wait() }()
+
+	time.Sleep(10 * time.Millisecond)
+
+	push()
+
+	select {
+	case got := <-result:
+		if got != want {
+			t.Fatalf("%s: got %d want %d", name, got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("%s did not return after a push", name)
+	}
+}
+
+func testDequePushWait(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque([]int{1}, queue.WithCapacity(1))
+
+	front := make(chan struct{})
+	back := make(chan struct{})
+
+	go func() {
+		defer close(front)
+
+		deque.PushFrontWait(0)
+	}()
+
+	go func() {
+		defer close(back)
+
+		deque.PushBackWait(2)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	// Each pop frees the single slot for one of the waiting pushers.
+	for i := 0; i < 3; i++ {
+		_ = deque.PopFrontWait()
+	}
+
+	for name, ch := range map[string]chan struct{}{"PushFrontWait": front, "PushBackWait": back} {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatalf("%s did not return after a pop", name)
+		}
+	}
+}
+
+func testDequeGrowsAcrossWrap(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque[int](nil)
+
+	// Pushing to the front first wraps the head to the end of the ring,
+	// so the growth that follows has to unwrap the elements.
+	for i := 0; i < 20; i++ {
+		if err := deque.PushFront(-i); err != nil {
+			t.Fatalf("push front: %v", err)
+		}
+
+		if err := deque.PushBack(i + 1); err != nil {
+			t.Fatalf("push back: %v", err)
+		}
+	}
+
+	got := deque.Clear()
+
+	for i := 1; i < len(got); i++ {
+		if got[i] != got[i-1]+1 {
+			t.Fatalf("expected consecutive elements, got %v", got)
+		}
+	}
+
+	if len(got) != 40 {
+		t.Fatalf("expected 40 elements, got %d", len(got))
+	}
+}
+
+func testDequeMatchesModel(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // deterministic test input.
+	deque := queue.NewDeque[int](nil)
+
+	var model []int
+
+	for i := 0; i < 10_000; i++ {
+		switch rng.Intn(4) {
+		case 0:
+			_ = deque.PushFront(i)
+			model = append([]int{i}, model...)
+		case 1:
+			_ = deque.PushBack(i)
+			model = append(model, i)
+		case 2:
+			got, err := deque.PopFront()
+			if len(model) == 0 {
+				if err == nil {
+					t.Fatalf("step %d: expected error on empty deque", i)
+				}
+
+				continue
+			}
+
+			if got != model[0] {
+				t.Fatalf("step %d: pop front got %d want %d", i, got, model[0])
+			}
+
+			model = model[1:]
+		case 3:
+			got, err := deque.PopBack()
+			if len(model) == 0 {
+				if err == nil {
+					t.Fatalf("step %d: expected error on empty deque", i)
+				}
+
+				continue
+			}
+
+			if got != model[len(model)-1] {
+				t.Fatalf("step %d: pop back got %d want %d", i, got, model[len(model)-1])
+			}
+
+			model = model[:len(model)-1]
+		}
+	}
+
+	if got := deque.Clear(); !reflect.DeepEqual(got, append([]int{}, model...)) {
+		t.Fatalf("final contents differ: got %v want %v", got, model)
+	}
+}
+
+func testDequeClear(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque([]int{1, 2, 3})
+
+	if got := deque.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	if !deque.IsEmpty() {
+		t.Fatal("expected empty deque after clear")
+	}
+
+	if err := deque.PushBack(4); err != nil {
+		t.Fatalf("push after clear: %v", err)
+	}
+}
+
+func testDequeIterator(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque([]int{2})
+
+	_ = deque.PushFront(1)
+
+	var got []int
+
+	for e := range deque.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testDequeReset(t *testing.T) {
+	t.Parallel()
+
+	initial := make([]int, 10)
+	for i := range initial {
+		initial[i] = i
+	}
+
+	deque := queue.NewDeque(initial)
+
+	_, _ = deque.PopBack()
+	_ = deque.PushFront(-1)
+
+	deque.Reset()
+
+	if got := deque.Clear(); !reflect.DeepEqual(got, initial) {
+		t.Fatalf("expected %v, got %v", initial, got)
+	}
+}
+
+func testDequeMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	deque := queue.NewDeque([]int{2, 3})
+
+	_ = deque.PushFront(1)
+
+	data, err := deque.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2,3]" {
+		t.Fatalf("expected [1,2,3], got %s", data)
+	}
+}
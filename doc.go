@@ -1,5 +1,5 @@
 // Package queue provides multiple thread-safe generic queue implementations.
-// Currently, there are 4 available implementations:
+// Currently, there are 25 available implementations:
 //
 // A blocking queue, which provides methods that wait for the
 // queue to have available elements when attempting to retrieve an element, and
@@ -17,4 +17,83 @@
 // time complexity for enqueue and dequeue operations. The queue maintains pointers
 // to both the head (front) and tail (end) of the list for efficient operations
 // without the need for traversal.
+//
+// A delay queue, where each element becomes available for retrieval at a
+// deadline computed by a caller-supplied function.
+//
+// A radix priority queue for monotone workloads, keyed by uint64, where
+// extracted keys never decrease. It trades the generality of the priority
+// queue for O(1) insertion and amortized O(log C) extraction.
+//
+// A timing wheel, with the same contract as the delay queue, backed by a
+// hierarchical timing wheel instead of a heap. Insertion and cancellation
+// are O(1) at the cost of a configurable tick resolution.
+//
+// An expiring queue, a FIFO queue where every element has a time to live
+// and is silently discarded once it elapses, optionally reporting the
+// discarded elements to a callback.
+//
+// A stack, a LIFO queue returning the most recently offered element, with
+// the same blocking methods as the blocking queue.
+//
+// A deque, a double-ended queue backed by a growable ring buffer, where
+// elements can be pushed, popped and peeked at both ends, with blocking
+// variants for each.
+//
+// A synchronous queue without any capacity, where every element is handed
+// directly from a producer to a waiting consumer.
+//
+// A transfer queue, with the semantics of the blocking queue, where a
+// producer can additionally wait until a consumer receives its element.
+//
+// A lock-free queue, an unbounded FIFO queue based on the Michael-Scott
+// algorithm, where producers and consumers synchronize through atomic
+// compare-and-swap operations instead of a mutex.
+//
+// A single-producer/single-consumer queue, a bounded ring buffer for
+// exactly one producer and one consumer goroutine, coordinated through
+// two atomic indices without locks or compare-and-swap loops.
+//
+// A multi-producer/single-consumer queue, an unbounded FIFO queue for
+// actor mailboxes, where producers enqueue with a single atomic swap and
+// the single consumer can wait for elements or drain them in batches.
+//
+// A sharded queue, which spreads elements over several linked queues with
+// their own locks to reduce contention, at the price of only keeping FIFO
+// order within each shard.
+//
+// A work-stealing deque based on the Chase-Lev algorithm, where the owner
+// pushes and pops tasks without locks and other goroutines steal the
+// oldest ones, along with a Pool that runs workers scheduling tasks by
+// stealing from each other.
+//
+// A relaxed concurrent priority queue, a MultiQueue that spreads elements
+// over several heaps and removes the better head of two random heaps,
+// trading strict priority order for throughput that scales with cores.
+//
+// A skip list priority queue, ordered like the priority queue but backed
+// by a concurrent skip list with fine-grained locks, offering ordered
+// non-destructive iteration, range queries and O(log n) removal of
+// arbitrary elements.
+//
+// A persistent queue, an immutable real-time queue where Offer and Get
+// return new versions in O(1) and every old version stays valid, along
+// with Ref, a thread-safe queue that swaps versions atomically and hands
+// out O(1) snapshots.
+//
+// A random queue, whose Get removes a uniformly random element in O(1),
+// for spreading load or sampling fairly.
+//
+// A reservoir, which keeps a uniform or weighted random sample of bounded
+// size out of an unbounded stream of elements.
+//
+// A resequencer, which releases elements strictly in the order of their
+// sequence numbers, holding back the elements after a missing one until it
+// arrives or, optionally, until a gap timeout skips it.
+//
+// A DAG task queue, where every element is offered with the elements it
+// depends on and only becomes available once they are all done.
+//
+// A coalescing queue, a FIFO queue where an element replaces, or is merged
+// into, the queued element with the same key, keeping its position.
 package queue
@@ -12,4 +12,33 @@ var (
 	// ErrQueueIsFull is an error returned whenever the queue is full and there
 	// is an attempt to add an element to it.
 	ErrQueueIsFull = errors.New("queue is full")
+
+	// ErrMonotoneViolation is an error returned whenever an element is
+	// offered to a monotone queue with a key smaller than the key of the
+	// last extracted element.
+	ErrMonotoneViolation = errors.New("key is smaller than the last extracted key")
+
+	// ErrElementDiscarded is an error returned whenever an element is
+	// dropped from a queue, for example by Reset, before a consumer
+	// received it.
+	ErrElementDiscarded = errors.New("element discarded before being received")
+
+	// ErrWindowOverflow is an error returned whenever an element is
+	// offered to a Resequencer with a sequence number too far ahead of the
+	// next expected one to fit in its window.
+	ErrWindowOverflow = errors.New("sequence number is beyond the window")
+
+	// ErrDuplicateSequence is an error returned whenever an element is
+	// offered to a Resequencer with a sequence number that is already
+	// buffered, or that was already released or skipped.
+	ErrDuplicateSequence = errors.New("sequence number already offered")
+
+	// ErrCycle is an error returned whenever an element is offered to a
+	// DAG with dependencies that, directly or not, depend on the element.
+	ErrCycle = errors.New("dependencies form a cycle")
+
+	// ErrDuplicate is an error returned whenever an element is offered to
+	// a queue created WithUnique that already contains it, or to a DAG
+	// that already had it offered or marked done.
+	ErrDuplicate = errors.New("element already offered")
 )
@@ -0,0 +1,406 @@
+package queue
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// expiring pairs an element with the time it stops being deliverable.
+type expiring[T any] struct {
+	elem      T
+	expiresAt time.Time
+}
+
+// Ensure Expiring implements the Queue interface.
+var _ Queue[any] = (*Expiring[any])(nil)
+
+// Expiring is a FIFO Queue implementation where every element carries a
+// time to live. Elements that are not consumed before their TTL elapses
+// are silently discarded: Get, Peek, Size, Contains and the other methods
+// only ever observe live elements. It is the opposite of Delay, which
+// holds elements back until a deadline; Expiring drops them after one.
+//
+// Cleanup is lazy: expired elements are discarded by the next operation
+// that touches the queue. WithSweepInterval additionally starts a
+// background sweeper, so that expirations are reported even when the
+// queue is idle; Close stops it.
+//
+// Discarded elements are reported to the callback registered with
+// WithOnExpire, which is called without the queue lock held.
+type Expiring[T comparable] struct {
+	initialElems []T
+	elems        []expiring[T]
+	ttl          time.Duration
+	capacity     *int
+	now          func() time.Time
+	onExpire     func(T)
+
+	// nextExpiry is a lower bound of the expiry times in elems, so that
+	// operations can skip the scan while nothing can have expired.
+	nextExpiry time.Time
+
+	// synchronization
+	lock      sync.Mutex
+	stopSweep chan struct{}
+	closeOnce sync.Once
+}
+
+// NewExpiring returns a new Expiring queue containing the given elements,
+// each of them living for ttl from construction.
+// Panics if ttl is not positive, WithCapacity is negative, or the
+// WithOnExpire callback is not a func(T).
+func NewExpiring[T comparable](
+	elems []T,
+	ttl time.Duration,
+	opts ...Option,
+) *Expiring[T] {
+	if ttl <= 0 {
+		panic("ttl must be positive")
+	}
+
+	options := options{capacity: nil, clock: time.Now}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	var onExpire func(T)
+
+	if options.onExpire != nil {
+		fn, ok := options.onExpire.(func(T))
+		if !ok {
+			panic("expiry callback does not match the element type")
+		}
+
+		onExpire = fn
+	}
+
+	if options.capacity != nil && len(elems) > *options.capacity {
+		elems = elems[:*options.capacity]
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	eq := &Expiring[T]{
+		initialElems: initialElems,
+		ttl:          ttl,
+		capacity:     options.capacity,
+		now:          options.clock,
+		onExpire:     onExpire,
+	}
+
+	eq.reset()
+
+	if options.sweepInterval > 0 {
+		eq.stopSweep = make(chan struct{})
+
+		go eq.sweep(options.sweepInterval)
+	}
+
+	return eq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element to the tail of the queue, living for the
+// queue's default TTL.
+// If the queue is full it returns the ErrQueueIsFull error.
+func (eq *Expiring[T]) Offer(elem T) error {
+	return eq.OfferWithTTL(elem, eq.ttl)
+}
+
+// OfferWithTTL inserts the element to the tail of the queue, living for
+// ttl instead of the queue's default TTL. A non-positive ttl makes the
+// element expire right away.
+// If the queue is full it returns the ErrQueueIsFull error.
+func (eq *Expiring[T]) OfferWithTTL(elem T, ttl time.Duration) error {
+	var expired []T
+
+	defer func() { eq.report(expired) }()
+
+	eq.lock.Lock()
+	defer eq.lock.Unlock()
+
+	now := eq.now()
+	expired = eq.purge(now)
+
+	if eq.capacity != nil && len(eq.elems) >= *eq.capacity {
+		return ErrQueueIsFull
+	}
+
+	eq.push(elem, now.Add(ttl))
+
+	return nil
+}
+
+// Reset sets the queue to its initial state with the original elements,
+// each of them living for the default TTL from now. Elements dropped by
+// Reset are not reported as expired.
+func (eq *Expiring[T]) Reset() {
+	eq.lock.Lock()
+	defer eq.lock.Unlock()
+
+	eq.reset()
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the oldest live element.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (eq *Expiring[T]) Get() (v T, _ error) {
+	var expired []T
+
+	defer func() { eq.report(expired) }()
+
+	eq.lock.Lock()
+	defer eq.lock.Unlock()
+
+	expired = eq.purge(eq.now())
+
+	if len(eq.elems) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	elem := eq.elems[0].elem
+
+	var zero expiring[T]
+
+	eq.elems[0] = zero
+	eq.elems = eq.elems[1:]
+
+	return elem, nil
+}
+
+// Clear removes and returns all live elements from the queue.
+func (eq *Expiring[T]) Clear() []T {
+	var expired []T
+
+	defer func() { eq.report(expired) }()
+
+	eq.lock.Lock()
+	defer eq.lock.Unlock()
+
+	expired = eq.purge(eq.now())
+
+	return eq.drain()
+}
+
+// Iterator returns an iterator over the live elements in this queue.
+// It removes the elements from the queue.
+func (eq *Expiring[T]) Iterator() <-chan T {
+	elems := eq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// Close stops the background sweeper started by WithSweepInterval.
+// It is safe to call Close more than once, and on a queue without a
+// sweeper. The queue stays usable, with lazy cleanup only.
+func (eq *Expiring[T]) Close() {
+	eq.closeOnce.Do(func() {
+		if eq.stopSweep != nil {
+			close(eq.stopSweep)
+		}
+	})
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the oldest live element.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (eq *Expiring[T]) Peek() (v T, _ error) {
+	var expired []T
+
+	defer func() { eq.report(expired) }()
+
+	eq.lock.Lock()
+	defer eq.lock.Unlock()
+
+	expired = eq.purge(eq.now())
+
+	if len(eq.elems) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return eq.elems[0].elem, nil
+}
+
+// Size returns the number of live elements in the queue.
+func (eq *Expiring[T]) Size() int {
+	var expired []T
+
+	defer func() { eq.report(expired) }()
+
+	eq.lock.Lock()
+	defer eq.lock.Unlock()
+
+	expired = eq.purge(eq.now())
+
+	return len(eq.elems)
+}
+
+// IsEmpty returns true if the queue holds no live elements.
+func (eq *Expiring[T]) IsEmpty() bool {
+	return eq.Size() == 0
+}
+
+// Contains returns true if the queue contains the given live element.
+func (eq *Expiring[T]) Contains(elem T) bool {
+	var expired []T
+
+	defer func() { eq.report(expired) }()
+
+	eq.lock.Lock()
+	defer eq.lock.Unlock()
+
+	expired = eq.purge(eq.now())
+
+	for i := range eq.elems {
+		if eq.elems[i].elem == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the live elements of the Expiring queue to JSON.
+func (eq *Expiring[T]) MarshalJSON() ([]byte, error) {
+	var expired []T
+
+	defer func() { eq.report(expired) }()
+
+	eq.lock.Lock()
+
+	expired = eq.purge(eq.now())
+
+	output := make([]T, len(eq.elems))
+	for i := range eq.elems {
+		output[i] = eq.elems[i].elem
+	}
+
+	eq.lock.Unlock()
+
+	return json.Marshal(output)
+}
+
+// ===================================Helpers==================================
+
+// reset replaces the elements with the initial ones, expiring ttl from now.
+// Caller must hold the lock.
+func (eq *Expiring[T]) reset() {
+	eq.elems = make([]expiring[T], 0, len(eq.initialElems))
+
+	expiresAt := eq.now().Add(eq.ttl)
+
+	for _, e := range eq.initialElems {
+		eq.push(e, expiresAt)
+	}
+}
+
+// push appends elem to the tail. Caller must hold the lock.
+func (eq *Expiring[T]) push(elem T, expiresAt time.Time) {
+	if len(eq.elems) == 0 || expiresAt.Before(eq.nextExpiry) {
+		eq.nextExpiry = expiresAt
+	}
+
+	eq.elems = append(eq.elems, expiring[T]{elem: elem, expiresAt: expiresAt})
+}
+
+// purge discards every element that expired at or before now, keeping the
+// others in FIFO order, and returns the discarded elements.
+// Caller must hold the lock.
+func (eq *Expiring[T]) purge(now time.Time) []T {
+	if len(eq.elems) == 0 || now.Before(eq.nextExpiry) {
+		return nil
+	}
+
+	var expired []T
+
+	live := eq.elems[:0]
+
+	for _, e := range eq.elems {
+		if !now.Before(e.expiresAt) {
+			expired = append(expired, e.elem)
+
+			continue
+		}
+
+		if len(live) == 0 || e.expiresAt.Before(eq.nextExpiry) {
+			eq.nextExpiry = e.expiresAt
+		}
+
+		live = append(live, e)
+	}
+
+	// Zero the slots past the live elements so the backing array no
+	// longer references discarded elements.
+	var zero expiring[T]
+
+	for i := len(live); i < len(eq.elems); i++ {
+		eq.elems[i] = zero
+	}
+
+	eq.elems = live
+
+	return expired
+}
+
+// drain removes and returns all elements. Caller must hold the lock.
+func (eq *Expiring[T]) drain() []T {
+	out := make([]T, len(eq.elems))
+
+	for i := range eq.elems {
+		out[i] = eq.elems[i].elem
+	}
+
+	eq.elems = nil
+
+	return out
+}
+
+// report hands expired elements to the expiry callback, if any.
+// It must be called without the lock held, so the callback may use the
+// queue.
+func (eq *Expiring[T]) report(expired []T) {
+	if eq.onExpire == nil {
+		return
+	}
+
+	for _, e := range expired {
+		eq.onExpire(e)
+	}
+}
+
+// sweep periodically purges expired elements until Close is called.
+func (eq *Expiring[T]) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-eq.stopSweep:
+			return
+		case <-ticker.C:
+			eq.lock.Lock()
+			expired := eq.purge(eq.now())
+			eq.lock.Unlock()
+
+			eq.report(expired)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+// expiryRecorder collects the elements reported by WithOnExpire.
+type expiryRecorder struct {
+	mu    sync.Mutex
+	elems []int
+}
+
+func (r *expiryRecorder) record(elem int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.elems = append(r.elems, elem)
+}
+
+func (r *expiryRecorder) recorded() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]int(nil), r.elems...)
+}
+
+func TestExpiring(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InvalidArguments", testExpiringInvalidArguments)
+	t.Run("Get", testExpiringGet)
+	t.Run("OfferWithTTL", testExpiringOfferWithTTL)
+	t.Run("Offer", testExpiringOffer)
+	t.Run("Peek", testExpiringPeek)
+	t.Run("SizeAndContains", testExpiringSizeAndContains)
+	t.Run("Clear", testExpiringClear)
+	t.Run("Iterator", testExpiringIterator)
+	t.Run("Reset", testExpiringReset)
+	t.Run("MarshalJSON", testExpiringMarshalJSON)
+	t.Run("OnExpire", testExpiringOnExpire)
+	t.Run("CallbackMayUseQueue", testExpiringCallbackMayUseQueue)
+	t.Run("SweepInterval", testExpiringSweepInterval)
+	t.Run("CapacityLesserThanLenElems", testExpiringCapacityLesserThanLenElems)
+}
+
+func testExpiringInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		panicMsg string
+		create   func()
+	}{
+		{
+			name:     "NonPositiveTTL",
+			panicMsg: "ttl must be positive",
+			create: func() {
+				queue.NewExpiring[int](nil, 0)
+			},
+		},
+		{
+			name:     "NegativeCapacity",
+			panicMsg: negativeCapacityPanic,
+			create: func() {
+				queue.NewExpiring[int](nil, time.Second, queue.WithCapacity(-1))
+			},
+		},
+		{
+			name:     "CallbackTypeMismatch",
+			panicMsg: "expiry callback does not match the element type",
+			create: func() {
+				queue.NewExpiring[int](nil, time.Second, queue.WithOnExpire(func(string) {}))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if p := recover(); p != tc.panicMsg {
+					t.Fatalf("expected panic %q, got %v", tc.panicMsg, p)
+				}
+			}()
+
+			tc.create()
+		})
+	}
+}
+
+func testExpiringGet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Live", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		expQueue := queue.NewExpiring([]int{1, 2}, time.Second, queue.WithClock(clock.Now))
+
+		for _, want := range []int{1, 2} {
+			got, err := expQueue.Get()
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+
+			if got != want {
+				t.Fatalf("got %d want %d", got, want)
+			}
+		}
+
+		if _, err := expQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+		}
+	})
+
+	t.Run("SkipsExpired", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		expQueue := queue.NewExpiring([]int{1}, time.Second, queue.WithClock(clock.Now))
+
+		clock.Advance(500 * time.Millisecond)
+
+		if err := expQueue.Offer(2); err != nil {
+			t.Fatalf("offer: %v", err)
+		}
+
+		clock.Advance(500 * time.Millisecond)
+
+		got, err := expQueue.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != 2 {
+			t.Fatalf("got %d want 2", got)
+		}
+	})
+
+	t.Run("AllExpired", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		expQueue := queue.NewExpiring([]int{1, 2}, time.Second, queue.WithClock(clock.Now))
+
+		clock.Advance(time.Second)
+
+		if _, err := expQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+		}
+	})
+}
+
+func testExpiringOfferWithTTL(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	expQueue := queue.NewExpiring[int](nil, time.Minute, queue.WithClock(clock.Now))
+
+	if err := expQueue.OfferWithTTL(1, time.Second); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if err := expQueue.Offer(2); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if err := expQueue.OfferWithTTL(3, 0); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if got := expQueue.Size(); got != 2 {
+		t.Fatalf("expected size 2 after zero ttl, got %d", got)
+	}
+
+	clock.Advance(time.Second)
+
+	if got := expQueue.Clear(); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("expected [2], got %v", got)
+	}
+}
+
+func testExpiringOffer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Full", func(t *testing.T) {
+		t.Parallel()
+
+		expQueue := queue.NewExpiring([]int{1}, time.Second, queue.WithCapacity(1))
+
+		if err := expQueue.Offer(2); !errors.Is(err, queue.ErrQueueIsFull) {
+			t.Fatalf("expected ErrQueueIsFull, got %v", err)
+		}
+	})
+
+	t.Run("ExpiryFreesCapacity", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		expQueue := queue.NewExpiring(
+			[]int{1},
+			time.Second,
+			queue.WithCapacity(1),
+			queue.WithClock(clock.Now),
+		)
+
+		clock.Advance(time.Second)
+
+		if err := expQueue.Offer(2); err != nil {
+			t.Fatalf("offer after expiry: %v", err)
+		}
+	})
+}
+
+func testExpiringPeek(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	expQueue := queue.NewExpiring([]int{1}, time.Second, queue.WithClock(clock.Now))
+
+	got, err := expQueue.Peek()
+	if err != nil {
+		t.Fatalf("peek: %v", err)
+	}
+
+	if got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	clock.Advance(time.Second)
+
+	if _, err := expQueue.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+
+func testExpiringSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	expQueue := queue.NewExpiring([]int{1}, time.Second, queue.WithClock(clock.Now))
+
+	clock.Advance(500 * time.Millisecond)
+
+	if err := expQueue.Offer(2); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if !expQueue.Contains(1) || expQueue.Size() != 2 {
+		t.Fatalf("expected both elements live, size %d", expQueue.Size())
+	}
+
+	clock.Advance(500 * time.Millisecond)
+
+	if expQueue.Contains(1) {
+		t.Fatal("expected expired element to be gone")
+	}
+
+	if expQueue.Size() != 1 || expQueue.IsEmpty() {
+		t.Fatalf("expected size 1, got %d", expQueue.Size())
+	}
+
+	clock.Advance(500 * time.Millisecond)
+
+	if !expQueue.IsEmpty() {
+		t.Fatalf("expected empty queue, got size %d", expQueue.Size())
+	}
+}
+
+func testExpiringClear(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	expQueue := queue.NewExpiring([]int{1, 2}, time.Second, queue.WithClock(clock.Now))
+
+	clock.Advance(time.Millisecond)
+
+	if err := expQueue.Offer(3); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	clock.Advance(999 * time.Millisecond)
+
+	if got := expQueue.Clear(); !reflect.DeepEqual(got, []int{3}) {
+		t.Fatalf("expected [3], got %v", got)
+	}
+
+	if !expQueue.IsEmpty() {
+		t.Fatal("expected empty queue after clear")
+	}
+}
+
+func testExpiringIterator(t *testing.T) {
+	t.Parallel()
+
+	expQueue := queue.NewExpiring([]int{1, 2, 3}, time.Minute)
+
+	var got []int
+
+	for e := range expQueue.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	if !expQueue.IsEmpty() {
+		t.Fatal("expected empty queue after iteration")
+	}
+}
+
+func testExpiringReset(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	recorder := &expiryRecorder{}
+	expQueue := queue.NewExpiring(
+		[]int{1, 2},
+		time.Second,
+		queue.WithClock(clock.Now),
+		queue.WithOnExpire(recorder.record),
+	)
+
+	if err := expQueue.Offer(3); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	clock.Advance(time.Second)
+
+	expQueue.Reset()
+
+	if got := expQueue.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2] after reset, got %v", got)
+	}
+
+	if got := recorder.recorded(); len(got) != 0 {
+		t.Fatalf("expected no expirations reported by reset, got %v", got)
+	}
+}
+
+func testExpiringMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	expQueue := queue.NewExpiring([]int{1}, time.Second, queue.WithClock(clock.Now))
+
+	clock.Advance(500 * time.Millisecond)
+
+	if err := expQueue.Offer(2); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+
+	data, err := expQueue.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[2]" {
+		t.Fatalf("expected [2], got %s", data)
+	}
+}
+
+func testExpiringOnExpire(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	recorder := &expiryRecorder{}
+	expQueue := queue.NewExpiring(
+		[]int{1, 2},
+		time.Second,
+		queue.WithClock(clock.Now),
+		queue.WithOnExpire(recorder.record),
+	)
+
+	if err := expQueue.OfferWithTTL(3, time.Minute); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	clock.Advance(time.Second)
+
+	if got := expQueue.Size(); got != 1 {
+		t.Fatalf("expected size 1, got %d", got)
+	}
+
+	if got := recorder.recorded(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2] reported, got %v", got)
+	}
+
+	// Already reported elements are not reported again.
+	_ = expQueue.Size()
+
+	if got := recorder.recorded(); len(got) != 2 {
+		t.Fatalf("expected 2 reports, got %v", got)
+	}
+}
+
+func testExpiringCallbackMayUseQueue(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+
+	var expQueue *queue.Expiring[int]
+
+	requeued := false
+
+	expQueue = queue.NewExpiring(
+		[]int{1},
+		time.Second,
+		queue.WithClock(clock.Now),
+		queue.WithOnExpire(func(elem int) {
+			requeued = true
+
+			_ = expQueue.OfferWithTTL(elem+10, time.Minute)
+		}),
+	)
+
+	clock.Advance(time.Second)
+
+	if _, err := expQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !requeued {
+		t.Fatal("expected callback to run")
+	}
+
+	if !expQueue.Contains(11) {
+		t.Fatal("expected element offered by the callback")
+	}
+}
+
+func testExpiringSweepInterval(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	expired := make(chan int, 1)
+	expQueue := queue.NewExpiring(
+		[]int{1},
+		time.Second,
+		queue.WithClock(clock.Now),
+		queue.WithSweepInterval(time.Millisecond),
+		queue.WithOnExpire(func(elem int) { expired <- elem }),
+	)
+
+	defer expQueue.Close()
+
+	clock.Advance(time.Second)
+
+	select {
+	case got := <-expired:
+		if got != 1 {
+			t.Fatalf("got %d want 1", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not report the expired element")
+	}
+
+	expQueue.Close()
+}
+
+func testExpiringCapacityLesserThanLenElems(t *testing.T) {
+	t.Parallel()
+
+	expQueue := queue.NewExpiring([]int{1, 2, 3}, time.Second, queue.WithCapacity(2))
+
+	if got := expQueue.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
@@ -0,0 +1,156 @@
+package queue
+
+// membership tracks the elements of a queue, updated by the queue on every
+// insertion and removal, so that the queue answers membership questions
+// without a scan.
+//
+// Created WithUnique or WithIndex, it counts how many times every element
+// is in the queue, which answers Contains and Count in O(1) and, if
+// unique, rejects duplicates. Created WithBloomFilter only, it keeps a
+// counting Bloom filter instead, which answers in O(1) for the elements
+// the queue does not contain and leaves the queue to scan for the others.
+//
+// A nil *membership is valid and tracks nothing, so queues call its
+// methods unconditionally.
+type membership[T comparable] struct {
+	counts map[T]int
+	unique bool
+
+	// filter and hash are set instead of counts WithBloomFilter.
+	filter *countingBloom
+	hash   func(T) uint64
+}
+
+// newMembership returns the membership for a queue created with the given
+// options, or nil if the queue does not need one.
+// Panics if WithBloomFilter is given a non-positive number of elements or
+// a false positive rate outside (0, 1), or if WithHasher does not take T.
+func newMembership[T comparable](opts options) *membership[T] {
+	if opts.unique || opts.index {
+		return &membership[T]{
+			counts: make(map[T]int),
+			unique: opts.unique,
+		}
+	}
+
+	if opts.bloom == nil {
+		return nil
+	}
+
+	if opts.bloom.expected <= 0 {
+		panic("bloom filter expected elements must be positive")
+	}
+
+	if !(opts.bloom.fpRate > 0 && opts.bloom.fpRate < 1) {
+		panic("bloom filter false positive rate must be in (0, 1)")
+	}
+
+	hash := newHasher[T]()
+
+	if opts.hasher != nil {
+		fn, ok := opts.hasher.(func(T) uint64)
+		if !ok {
+			panic("hasher func does not match the element type")
+		}
+
+		hash = fn
+	}
+
+	return &membership[T]{
+		filter: newCountingBloom(opts.bloom.expected, opts.bloom.fpRate),
+		hash:   hash,
+	}
+}
+
+// admits reports whether the element may be added to the queue.
+func (m *membership[T]) admits(elem T) bool {
+	return m == nil || !m.unique || m.counts[elem] == 0
+}
+
+// add records an element added to the queue.
+func (m *membership[T]) add(elem T) {
+	switch {
+	case m == nil:
+	case m.filter != nil:
+		m.filter.add(m.hash(elem))
+	default:
+		m.counts[elem]++
+	}
+}
+
+// remove records an element removed from the queue.
+func (m *membership[T]) remove(elem T) {
+	switch {
+	case m == nil:
+	case m.filter != nil:
+		m.filter.remove(m.hash(elem))
+	default:
+		m.counts[elem]--
+
+		if m.counts[elem] == 0 {
+			delete(m.counts, elem)
+		}
+	}
+}
+
+// reset records that the queue now holds exactly elems.
+func (m *membership[T]) reset(elems []T) {
+	switch {
+	case m == nil:
+		return
+	case m.filter != nil:
+		m.filter.clear()
+	default:
+		m.counts = make(map[T]int, len(elems))
+	}
+
+	for _, elem := range elems {
+		m.add(elem)
+	}
+}
+
+// lookup reports whether the queue may hold the element, and whether that
+// answer is certain. If it is not, the queue has to scan its elements.
+func (m *membership[T]) lookup(elem T) (found, sure bool) {
+	switch {
+	case m == nil:
+		return false, false
+	case m.filter != nil:
+		found = m.filter.test(m.hash(elem))
+
+		return found, !found
+	default:
+		return m.counts[elem] > 0, true
+	}
+}
+
+// count returns how many times the queue holds the element, and whether
+// that count is certain. If it is not, the queue has to scan its elements.
+func (m *membership[T]) count(elem T) (n int, sure bool) {
+	switch {
+	case m == nil:
+		return 0, false
+	case m.filter != nil:
+		return 0, !m.filter.test(m.hash(elem))
+	default:
+		return m.counts[elem], true
+	}
+}
+
+// distinct returns the elements without repetitions, keeping the first
+// occurrence of each, in order.
+func distinct[T comparable](elems []T) []T {
+	seen := make(map[T]struct{}, len(elems))
+	out := make([]T, 0, len(elems))
+
+	for _, elem := range elems {
+		if _, ok := seen[elem]; ok {
+			continue
+		}
+
+		seen[elem] = struct{}{}
+		out = append(out, elem)
+	}
+
+	return out
+}
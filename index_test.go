@@ -0,0 +1,458 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+// dueNow is a Delay deadline func making every element due right away.
+func dueNow(int) time.Time {
+	return time.Time{}
+}
+
+// countingQueue is a Queue that can count the occurrences of an element.
+type countingQueue interface {
+	queue.Queue[int]
+	Count(elem int) int
+	MaybeContains(elem int) bool
+}
+
+// uniqueQueues returns a new queue created WithUnique, and the given
+// options, for every implementation that supports it.
+func uniqueQueues(elems []int, opts ...queue.Option) map[string]queue.Queue[int] {
+	opts = append(opts, queue.WithUnique())
+
+	return map[string]queue.Queue[int]{
+		"Blocking": queue.NewBlocking(elems, opts...),
+		"Priority": queue.NewPriority(elems, lessInt, opts...),
+		"Delay":    queue.NewDelay(elems, dueNow, opts...),
+	}
+}
+
+// countingConstructors holds, for every implementation that supports
+// WithIndex, a func creating one. The Circular queue has a capacity of 10
+// unless set WithCapacity.
+var countingConstructors = map[string]func(elems []int, opts ...queue.Option) countingQueue{
+	"Blocking": func(elems []int, opts ...queue.Option) countingQueue {
+		return queue.NewBlocking(elems, opts...)
+	},
+	"Linked": func(elems []int, opts ...queue.Option) countingQueue {
+		return queue.NewLinked(elems, opts...)
+	},
+	"Circular": func(elems []int, opts ...queue.Option) countingQueue {
+		return queue.NewCircular(elems, 10, opts...)
+	},
+	"Priority": func(elems []int, opts ...queue.Option) countingQueue {
+		return queue.NewPriority(elems, lessInt, opts...)
+	},
+	"Delay": func(elems []int, opts ...queue.Option) countingQueue {
+		return queue.NewDelay(elems, dueNow, opts...)
+	},
+}
+
+// countingQueues returns a new queue created with the given options for
+// every implementation that supports WithIndex.
+func countingQueues(elems []int, opts ...queue.Option) map[string]countingQueue {
+	queues := make(map[string]countingQueue, len(countingConstructors))
+
+	for name, newQueue := range countingConstructors {
+		queues[name] = newQueue(elems, opts...)
+	}
+
+	return queues
+}
+
+func TestWithUnique(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InitialElements", testWithUniqueInitialElements)
+	t.Run("Offer", testWithUniqueOffer)
+	t.Run("Contains", testWithUniqueContains)
+	t.Run("ClearAndReset", testWithUniqueClearAndReset)
+	t.Run("Iterator", testWithUniqueIterator)
+	t.Run("BlockingOfferWait", testWithUniqueBlockingOfferWait)
+	t.Run("DelayDrainDue", testWithUniqueDelayDrainDue)
+	t.Run("LinkedAndCircular", testWithUniqueLinkedAndCircular)
+}
+
+func testWithUniqueInitialElements(t *testing.T) {
+	t.Parallel()
+
+	// Duplicates are dropped before the capacity is applied.
+	for name, q := range uniqueQueues([]int{1, 1, 2, 1, 3, 4}, queue.WithCapacity(3)) {
+		got := q.Clear()
+		sort.Ints(got)
+
+		if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+			t.Fatalf("%s: expected [1 2 3], got %v", name, got)
+		}
+	}
+}
+
+func testWithUniqueOffer(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range uniqueQueues([]int{1}, queue.WithCapacity(2)) {
+		if err := q.Offer(1); !errors.Is(err, queue.ErrDuplicate) {
+			t.Fatalf("%s: expected ErrDuplicate, got %v", name, err)
+		}
+
+		if err := q.Offer(2); err != nil {
+			t.Fatalf("%s: offer: %v", name, err)
+		}
+
+		// A duplicate is reported even when the queue is full.
+		if err := q.Offer(2); !errors.Is(err, queue.ErrDuplicate) {
+			t.Fatalf("%s: expected ErrDuplicate, got %v", name, err)
+		}
+
+		if err := q.Offer(3); !errors.Is(err, queue.ErrQueueIsFull) {
+			t.Fatalf("%s: expected ErrQueueIsFull, got %v", name, err)
+		}
+
+		// Once taken, an element can be offered again.
+		elem, _ := q.Get()
+
+		if err := q.Offer(elem); err != nil {
+			t.Fatalf("%s: offer %d again: %v", name, elem, err)
+		}
+	}
+}
+
+func testWithUniqueContains(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range uniqueQueues([]int{1, 2}) {
+		if !q.Contains(1) || !q.Contains(2) || q.Contains(3) {
+			t.Fatalf("%s: unexpected Contains result", name)
+		}
+
+		elem, _ := q.Get()
+
+		if q.Contains(elem) {
+			t.Fatalf("%s: expected %d to be removed", name, elem)
+		}
+	}
+}
+
+func testWithUniqueClearAndReset(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range uniqueQueues([]int{1, 2}) {
+		_ = q.Offer(3)
+		_ = q.Clear()
+
+		if q.Contains(1) {
+			t.Fatalf("%s: expected an empty queue", name)
+		}
+
+		_ = q.Offer(3)
+		q.Reset()
+
+		if q.Contains(3) || !q.Contains(1) {
+			t.Fatalf("%s: expected the initial elements", name)
+		}
+
+		if err := q.Offer(2); !errors.Is(err, queue.ErrDuplicate) {
+			t.Fatalf("%s: expected ErrDuplicate, got %v", name, err)
+		}
+	}
+}
+
+func testWithUniqueIterator(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range uniqueQueues([]int{1, 2}) {
+		if got := len(q.Iterator()); got != 2 {
+			t.Fatalf("%s: expected 2 elements, got %d", name, got)
+		}
+
+		if err := q.Offer(1); err != nil {
+			t.Fatalf("%s: offer: %v", name, err)
+		}
+	}
+}
+
+func testWithUniqueBlockingOfferWait(t *testing.T) {
+	t.Parallel()
+
+	bq := queue.NewBlocking([]int{1}, queue.WithCapacity(2), queue.WithUnique())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		bq.OfferWait(1)
+	}()
+
+	// The duplicate waits for 1 to be removed, although there is room.
+	time.Sleep(10 * time.Millisecond)
+
+	select {
+	case <-done:
+		t.Fatal("expected OfferWait to wait for the duplicate to be removed")
+	default:
+	}
+
+	if got := bq.GetWait(); got != 1 {
+		t.Fatalf("expected 1, got %d", got)
+	}
+
+	<-done
+
+	if got := bq.Clear(); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("expected [1], got %v", got)
+	}
+}
+
+func testWithUniqueDelayDrainDue(t *testing.T) {
+	t.Parallel()
+
+	dq := queue.NewDelay([]int{1, 2}, dueNow, queue.WithUnique())
+
+	if got := dq.DrainDue(); len(got) != 2 {
+		t.Fatalf("expected 2 elements, got %v", got)
+	}
+
+	_ = dq.Offer(1)
+
+	if got := dq.GetWait(); got != 1 {
+		t.Fatalf("expected 1, got %d", got)
+	}
+
+	if err := dq.Offer(1); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+}
+
+func testWithUniqueLinkedAndCircular(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range map[string]queue.Queue[int]{
+		"Linked":   queue.NewLinked([]int{1, 1, 2}, queue.WithUnique()),
+		"Circular": queue.NewCircular([]int{1, 1, 2}, 3, queue.WithUnique()),
+	} {
+		if got := q.Size(); got != 2 {
+			t.Fatalf("%s: expected the duplicate to be dropped, got size %d", name, got)
+		}
+
+		if err := q.Offer(2); !errors.Is(err, queue.ErrDuplicate) {
+			t.Fatalf("%s: expected ErrDuplicate, got %v", name, err)
+		}
+
+		if err := q.Offer(3); err != nil {
+			t.Fatalf("%s: offer: %v", name, err)
+		}
+
+		// Once taken, an element can be offered again.
+		elem, _ := q.Get()
+
+		if err := q.Offer(elem); err != nil {
+			t.Fatalf("%s: offer %d again: %v", name, elem, err)
+		}
+
+		q.Reset()
+
+		if err := q.Offer(3); err != nil || !q.Contains(1) {
+			t.Fatalf("%s: expected the initial elements, got %v", name, err)
+		}
+	}
+
+	// A full Circular queue rejects a duplicate without overwriting its
+	// oldest element, and frees the elements it overwrites.
+	cq := queue.NewCircular([]int{1, 2}, 2, queue.WithUnique())
+
+	if err := cq.Offer(2); !errors.Is(err, queue.ErrDuplicate) || !cq.Contains(1) {
+		t.Fatalf("expected ErrDuplicate and 1 kept, got %v", err)
+	}
+
+	_ = cq.Offer(3)
+
+	if err := cq.Offer(1); err != nil {
+		t.Fatalf("offer overwritten element: %v", err)
+	}
+}
+
+func TestWithIndex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Count", testWithIndexCount)
+	t.Run("Removal", testWithIndexRemoval)
+	t.Run("ClearAndReset", testWithIndexClearAndReset)
+	t.Run("Iterator", testWithIndexIterator)
+	t.Run("CircularOverwrite", testWithIndexCircularOverwrite)
+}
+
+func testWithIndexCount(t *testing.T) {
+	t.Parallel()
+
+	// Without the index, Count and Contains scan the queue.
+	for _, opts := range [][]queue.Option{nil, {queue.WithIndex()}} {
+		for name, q := range countingQueues([]int{1, 2, 1}, opts...) {
+			_ = q.Offer(1)
+
+			if got := q.Count(1); got != 3 {
+				t.Fatalf("%s: expected 3, got %d", name, got)
+			}
+
+			if got := q.Count(3); got != 0 {
+				t.Fatalf("%s: expected 0, got %d", name, got)
+			}
+
+			if !q.Contains(2) || q.Contains(3) {
+				t.Fatalf("%s: unexpected Contains result", name)
+			}
+		}
+	}
+}
+
+func testWithIndexRemoval(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range countingQueues([]int{1, 1}, queue.WithIndex()) {
+		_, _ = q.Get()
+
+		if got := q.Count(1); got != 1 {
+			t.Fatalf("%s: expected 1, got %d", name, got)
+		}
+
+		_, _ = q.Get()
+
+		if q.Contains(1) {
+			t.Fatalf("%s: expected 1 to be removed", name)
+		}
+	}
+}
+
+func testWithIndexClearAndReset(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range countingQueues([]int{1, 1}, queue.WithIndex()) {
+		_ = q.Offer(2)
+		_ = q.Clear()
+
+		if q.Contains(1) || q.Contains(2) {
+			t.Fatalf("%s: expected an empty queue", name)
+		}
+
+		_ = q.Offer(2)
+		q.Reset()
+
+		if got := q.Count(1); got != 2 || q.Contains(2) {
+			t.Fatalf("%s: expected the initial elements, got %d ones", name, got)
+		}
+	}
+}
+
+func testWithIndexIterator(t *testing.T) {
+	t.Parallel()
+
+	for name, q := range countingQueues([]int{1, 2}, queue.WithIndex()) {
+		if got := len(q.Iterator()); got != 2 {
+			t.Fatalf("%s: expected 2 elements, got %d", name, got)
+		}
+
+		if q.Contains(1) {
+			t.Fatalf("%s: expected an empty queue", name)
+		}
+	}
+}
+
+func testWithIndexCircularOverwrite(t *testing.T) {
+	t.Parallel()
+
+	cq := queue.NewCircular([]int{1, 2, 3}, 3, queue.WithIndex())
+
+	// Overwrites the oldest element, 1.
+	_ = cq.Offer(4)
+
+	if cq.Contains(1) || !cq.Contains(4) {
+		t.Fatal("expected 1 to be overwritten by 4")
+	}
+
+	// Snapshots do not affect the index.
+	_ = cq.Snapshot()
+	_ = cq.Offer(3)
+
+	if got := cq.Count(3); got != 2 || cq.Contains(2) {
+		t.Fatalf("expected 2 to be overwritten by 3, got %d threes", got)
+	}
+}
+
+// BenchmarkIndex compares, on queues of 100k distinct elements, Contains
+// scanning the queue with Contains using WithIndex or WithBloomFilter,
+// along with the cost they add to Offer and Get and the memory they take.
+func BenchmarkIndex(b *testing.B) {
+	const size = 100_000
+
+	elems := make([]int, size)
+	for i := range elems {
+		elems[i] = i
+	}
+
+	modes := []struct {
+		name string
+		opts []queue.Option
+	}{
+		{"Scan", nil},
+		{"Index", []queue.Option{queue.WithIndex()}},
+		{"Bloom", []queue.Option{queue.WithBloomFilter(size, 0.01)}},
+	}
+
+	for _, name := range []string{"Blocking", "Linked", "Circular", "Priority", "Delay"} {
+		name := name
+		construct := countingConstructors[name]
+
+		for _, mode := range modes {
+			mode := mode
+
+			newQueue := func() countingQueue {
+				// Circular must have room for an extra element, lest
+				// Get_Offer overwrite one.
+				opts := append([]queue.Option{queue.WithCapacity(size + 1)}, mode.opts...)
+
+				return construct(elems, opts...)
+			}
+
+			b.Run(name+"/Contains/"+mode.name, func(b *testing.B) {
+				q := newQueue()
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				// Look up missing elements, as a dedup check mostly does,
+				// which makes Scan walk the whole queue.
+				for i := 0; i < b.N; i++ {
+					_ = q.Contains(-i - 1)
+				}
+			})
+
+			b.Run(name+"/Get_Offer/"+mode.name, func(b *testing.B) {
+				q := newQueue()
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					elem, _ := q.Get()
+
+					_ = q.Offer(elem)
+				}
+			})
+
+			b.Run(name+"/New/"+mode.name, func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					_ = newQueue()
+				}
+			})
+		}
+	}
+}
@@ -28,18 +28,35 @@ type Linked[T comparable] struct {
 	// can't cause unbounded retention.
 	free    *node[T]
 	freeLen int
+	// shared is the number of nodes, from head, that a Snapshot may
+	// still read. They are not recycled.
+	shared int
+	// members is set WithUnique, WithIndex or WithBloomFilter; see
+	// membership.
+	members *membership[T]
 }
 
 // freeCap is the maximum number of nodes cached for reuse.
 const freeCap = 64
 
 // NewLinked creates a new Linked containing the given elements.
-func NewLinked[T comparable](elements []T) *Linked[T] {
+func NewLinked[T comparable](elements []T, opts ...Option) *Linked[T] {
+	options := options{}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.unique {
+		elements = distinct(elements)
+	}
+
 	queue := &Linked[T]{
 		head:            nil,
 		tail:            nil,
 		size:            0,
 		initialElements: make([]T, len(elements)),
+		members:         newMembership[T](options),
 	}
 
 	copy(queue.initialElements, elements)
@@ -65,17 +82,24 @@ func (lq *Linked[T]) Get() (elem T, _ error) {
 
 	lq.head = popped.next
 	lq.size--
+	lq.members.remove(value)
 
 	if lq.isEmpty() {
 		lq.tail = nil
 	}
 
-	lq.recycle(popped)
+	if lq.shared > 0 {
+		lq.shared--
+	} else {
+		lq.recycle(popped)
+	}
 
 	return value, nil
 }
 
 // Offer inserts the element into the queue.
+// If the queue was created WithUnique and already contains the element,
+// it returns the ErrDuplicate error.
 func (lq *Linked[T]) Offer(value T) error {
 	lq.lock.Lock()
 	defer lq.lock.Unlock()
@@ -85,6 +109,10 @@ func (lq *Linked[T]) Offer(value T) error {
 
 // offer inserts the element into the queue.
 func (lq *Linked[T]) offer(value T) error {
+	if !lq.members.admits(value) {
+		return ErrDuplicate
+	}
+
 	newNode := lq.acquireNode()
 	newNode.value = value
 
@@ -96,6 +124,7 @@ func (lq *Linked[T]) offer(value T) error {
 
 	lq.tail = newNode
 	lq.size++
+	lq.members.add(value)
 
 	return nil
 }
@@ -137,6 +166,8 @@ func (lq *Linked[T]) Reset() {
 	lq.head = nil
 	lq.tail = nil
 	lq.size = 0
+	lq.shared = 0
+	lq.members.reset(nil)
 
 	for _, element := range lq.initialElements {
 		_ = lq.offer(element)
@@ -144,10 +175,15 @@ func (lq *Linked[T]) Reset() {
 }
 
 // Contains returns true if the queue contains the element.
+// See WithIndex and WithBloomFilter for how they speed it up.
 func (lq *Linked[T]) Contains(value T) bool {
 	lq.lock.RLock()
 	defer lq.lock.RUnlock()
 
+	if found, sure := lq.members.lookup(value); sure {
+		return found
+	}
+
 	current := lq.head
 	for current != nil {
 		if current.value == value {
@@ -160,6 +196,43 @@ func (lq *Linked[T]) Contains(value T) bool {
 	return false
 }
 
+// MaybeContains reports whether the queue may contain the given element.
+// It never returns false for an element the queue contains; see
+// WithBloomFilter for when it returns true for one it does not.
+func (lq *Linked[T]) MaybeContains(elem T) bool {
+	if lq.members == nil {
+		return lq.Contains(elem)
+	}
+
+	lq.lock.RLock()
+	defer lq.lock.RUnlock()
+
+	found, _ := lq.members.lookup(elem)
+
+	return found
+}
+
+// Count returns the number of times the element is in the queue.
+// See WithIndex and WithBloomFilter for how they speed it up.
+func (lq *Linked[T]) Count(value T) int {
+	lq.lock.RLock()
+	defer lq.lock.RUnlock()
+
+	if n, sure := lq.members.count(value); sure {
+		return n
+	}
+
+	n := 0
+
+	for current := lq.head; current != nil; current = current.next {
+		if current.value == value {
+			n++
+		}
+	}
+
+	return n
+}
+
 // Peek retrieves but does not remove the head of the queue.
 func (lq *Linked[T]) Peek() (elem T, _ error) {
 	lq.lock.RLock()
@@ -172,6 +245,19 @@ func (lq *Linked[T]) Peek() (elem T, _ error) {
 	return lq.head.value, nil
 }
 
+// Snapshot returns an immutable, point-in-time view of the elements in
+// the queue. It runs in O(1) and reading the snapshot does not take the
+// queue lock: the snapshot shares the nodes of the queue, which Get then
+// leaves to the garbage collector instead of recycling them.
+func (lq *Linked[T]) Snapshot() *Snapshot[T] {
+	lq.lock.Lock()
+	defer lq.lock.Unlock()
+
+	lq.shared = lq.size
+
+	return &Snapshot[T]{head: lq.head, nodes: lq.size, size: lq.size}
+}
+
 // Size returns the number of elements in the queue.
 func (lq *Linked[T]) Size() int {
 	lq.lock.RLock()
@@ -234,6 +320,8 @@ func (lq *Linked[T]) drainLocked() []T {
 	lq.head = nil
 	lq.tail = nil
 	lq.size = 0
+	lq.shared = 0
+	lq.members.reset(nil)
 
 	return elements
 }
@@ -0,0 +1,222 @@
+package queue
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// cacheLineSize is the padding used to keep fields that are written by
+// different goroutines on separate cache lines, avoiding false sharing.
+const cacheLineSize = 64
+
+// lfNode is an individual element of the LockFree linked list. Its value
+// is nil once the element has been dequeued.
+type lfNode[T any] struct {
+	value atomic.Pointer[T]
+	next  atomic.Pointer[lfNode[T]]
+}
+
+// Ensure LockFree implements the Queue interface.
+var _ Queue[any] = (*LockFree[any])(nil)
+
+// LockFree is an unbounded multi-producer, multi-consumer FIFO Queue
+// implementation that uses no locks, based on the Michael-Scott queue.
+// Producers and consumers only synchronize through compare-and-swap
+// operations on the tail and head of a singly linked list, so throughput
+// keeps scaling where the mutex-based queues serialize.
+//
+// Nodes are never recycled: the garbage collector only frees a node once
+// no goroutine can reach it, which rules out the ABA problem that manual
+// memory reuse would have to guard against. The dequeued node stays as
+// the sentinel until the next Get, so Get takes the element out of it,
+// which costs an allocation per Offer for the element, but does not keep
+// a dequeued element reachable.
+//
+// The methods that look at more than one element (Size, Contains, Clear,
+// Iterator, Reset and MarshalJSON) are not atomic with respect to
+// concurrent Offer and Get calls; they observe a state the queue was in
+// at some point during the call, or, for Size, an approximation.
+type LockFree[T comparable] struct {
+	// head points to a sentinel node; the first element is head.next.
+	head atomic.Pointer[lfNode[T]]
+	_    [cacheLineSize]byte
+	tail atomic.Pointer[lfNode[T]]
+	_    [cacheLineSize]byte
+	size atomic.Int64
+
+	initialElems []T
+}
+
+// NewLockFree returns a new LockFree queue containing the given elements.
+func NewLockFree[T comparable](elems []T) *LockFree[T] {
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	lq := &LockFree[T]{initialElems: initialElems}
+
+	sentinel := &lfNode[T]{}
+	lq.head.Store(sentinel)
+	lq.tail.Store(sentinel)
+
+	for _, e := range initialElems {
+		_ = lq.Offer(e)
+	}
+
+	return lq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element to the tail of the queue. It never fails.
+func (lq *LockFree[T]) Offer(elem T) error {
+	n := &lfNode[T]{}
+	n.value.Store(&elem)
+
+	for {
+		tail := lq.tail.Load()
+
+		linked := tail.next.CompareAndSwap(nil, n)
+
+		// Swing the tail forward to the node linked after tail: n if
+		// linked, or else the node of another producer that linked first,
+		// which this helps. The swing fails if the tail already moved on.
+		lq.tail.CompareAndSwap(tail, tail.next.Load())
+
+		if linked {
+			lq.size.Add(1)
+
+			return nil
+		}
+	}
+}
+
+// Reset sets the queue to its initial state with the original elements,
+// by draining it and offering the original elements again.
+func (lq *LockFree[T]) Reset() {
+	_ = lq.Clear()
+
+	for _, e := range lq.initialElems {
+		_ = lq.Offer(e)
+	}
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+//
+// Unlike the original algorithm, Get never swings a lagging tail: the
+// tail only has to be fixed up before a node is freed, and here nodes are
+// only freed by the garbage collector once nothing points to them.
+func (lq *LockFree[T]) Get() (v T, _ error) {
+	for {
+		head := lq.head.Load()
+		next := head.next.Load()
+
+		if next == nil {
+			return v, ErrNoElementsAvailable
+		}
+
+		// Only the consumer that makes next the sentinel takes its
+		// value, so it is still set.
+		if lq.head.CompareAndSwap(head, next) {
+			lq.size.Add(-1)
+
+			return *next.value.Swap(nil), nil
+		}
+	}
+}
+
+// Clear removes and returns all elements from the queue.
+func (lq *LockFree[T]) Clear() []T {
+	var removed []T
+
+	for {
+		elem, err := lq.Get()
+		if err != nil {
+			break
+		}
+
+		removed = append(removed, elem)
+	}
+
+	if removed == nil {
+		return []T{}
+	}
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this queue.
+// It removes the elements from the queue.
+func (lq *LockFree[T]) Iterator() <-chan T {
+	elems := lq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (lq *LockFree[T]) Peek() (v T, _ error) {
+	for {
+		next := lq.head.Load().next.Load()
+
+		if next == nil {
+			return v, ErrNoElementsAvailable
+		}
+
+		// A nil value means a consumer took the element in the meantime.
+		if p := next.value.Load(); p != nil {
+			return *p, nil
+		}
+	}
+}
+
+// Size returns the number of elements in the queue. While Offer and Get
+// run concurrently it is an approximation.
+func (lq *LockFree[T]) Size() int {
+	if n := lq.size.Load(); n > 0 {
+		return int(n)
+	}
+
+	return 0
+}
+
+// IsEmpty returns true if the queue is empty.
+func (lq *LockFree[T]) IsEmpty() bool {
+	return lq.head.Load().next.Load() == nil
+}
+
+// Contains returns true if the queue contains the given element.
+func (lq *LockFree[T]) Contains(elem T) bool {
+	for n := lq.head.Load().next.Load(); n != nil; n = n.next.Load() {
+		if p := n.value.Load(); p != nil && *p == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the LockFree queue to JSON.
+func (lq *LockFree[T]) MarshalJSON() ([]byte, error) {
+	output := []T{}
+
+	for n := lq.head.Load().next.Load(); n != nil; n = n.next.Load() {
+		if p := n.value.Load(); p != nil {
+			output = append(output, *p)
+		}
+	}
+
+	return json.Marshal(output)
+}
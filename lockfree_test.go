@@ -0,0 +1,421 @@
+package queue_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestLockFree(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FIFO", testLockFreeFIFO)
+	t.Run("Empty", testLockFreeEmpty)
+	t.Run("Peek", testLockFreePeek)
+	t.Run("SizeAndContains", testLockFreeSizeAndContains)
+	t.Run("Clear", testLockFreeClear)
+	t.Run("Iterator", testLockFreeIterator)
+	t.Run("Reset", testLockFreeReset)
+	t.Run("MarshalJSON", testLockFreeMarshalJSON)
+	t.Run("NewDoesNotAliasCallerSlice", testLockFreeNewDoesNotAliasCallerSlice)
+	t.Run("ConcurrentProducersConsumers", testLockFreeConcurrentProducersConsumers)
+	t.Run("GetReleasesReference", testLockFreeGetReleasesReference)
+}
+
+func testLockFreeFIFO(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree([]int{1, 2})
+
+	if err := lockFree.Offer(3); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	for want := 1; want <= 3; want++ {
+		got, err := lockFree.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testLockFreeEmpty(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree[int](nil)
+
+	if _, err := lockFree.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := lockFree.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !lockFree.IsEmpty() || lockFree.Size() != 0 {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testLockFreePeek(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree([]int{1, 2})
+
+	got, err := lockFree.Peek()
+	if err != nil {
+		t.Fatalf("peek: %v", err)
+	}
+
+	if got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	if lockFree.Size() != 2 {
+		t.Fatalf("expected peek to keep the element, size %d", lockFree.Size())
+	}
+}
+
+func testLockFreeSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree([]int{1, 2})
+
+	if !lockFree.Contains(2) || lockFree.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+
+	_, _ = lockFree.Get()
+
+	if lockFree.Contains(1) {
+		t.Fatal("expected dequeued element to be gone")
+	}
+
+	if lockFree.Size() != 1 || lockFree.IsEmpty() {
+		t.Fatalf("expected size 1, got %d", lockFree.Size())
+	}
+}
+
+func testLockFreeClear(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree([]int{1, 2, 3})
+
+	if got := lockFree.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	if got := lockFree.Clear(); len(got) != 0 || got == nil {
+		t.Fatalf("expected an empty slice, got %#v", got)
+	}
+}
+
+func testLockFreeIterator(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree([]int{1, 2, 3})
+
+	var got []int
+
+	for e := range lockFree.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	if !lockFree.IsEmpty() {
+		t.Fatal("expected empty queue after iteration")
+	}
+}
+
+func testLockFreeReset(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree([]int{1, 2})
+
+	_, _ = lockFree.Get()
+	_ = lockFree.Offer(3)
+
+	lockFree.Reset()
+
+	if got := lockFree.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testLockFreeMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	lockFree := queue.NewLockFree([]int{1, 2})
+
+	data, err := lockFree.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2]" {
+		t.Fatalf("expected [1,2], got %s", data)
+	}
+
+	data, err = queue.NewLockFree[int](nil).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[]" {
+		t.Fatalf("expected [], got %s", data)
+	}
+}
+
+func testLockFreeNewDoesNotAliasCallerSlice(t *testing.T) {
+	t.Parallel()
+
+	elems := []int{1, 2}
+	lockFree := queue.NewLockFree(elems)
+
+	elems[0] = 42
+
+	lockFree.Reset()
+
+	if got, _ := lockFree.Peek(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+}
+
+// testLockFreeConcurrentProducersConsumers checks that under contention
+// every element is delivered exactly once and that the elements of each
+// producer are received in the order they were offered.
+func testLockFreeConcurrentProducersConsumers(t *testing.T) {
+	t.Parallel()
+
+	const (
+		producers = 8
+		consumers = 8
+		perProd   = 5_000
+	)
+
+	lockFree := queue.NewLockFree[[2]int](nil)
+
+	var producersWG sync.WaitGroup
+
+	producersWG.Add(producers)
+
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			defer producersWG.Done()
+
+			for i := 0; i < perProd; i++ {
+				_ = lockFree.Offer([2]int{p, i})
+			}
+		}(p)
+	}
+
+	received := make([][][2]int, consumers)
+	done := make(chan struct{})
+
+	var consumersWG sync.WaitGroup
+
+	consumersWG.Add(consumers)
+
+	for c := 0; c < consumers; c++ {
+		go func(c int) {
+			defer consumersWG.Done()
+
+			for {
+				elem, err := lockFree.Get()
+				if err == nil {
+					received[c] = append(received[c], elem)
+
+					continue
+				}
+
+				select {
+				case <-done:
+					if lockFree.IsEmpty() {
+						return
+					}
+				default:
+				}
+			}
+		}(c)
+	}
+
+	producersWG.Wait()
+	close(done)
+	consumersWG.Wait()
+
+	seen := make(map[[2]int]bool, producers*perProd)
+
+	for _, elems := range received {
+		last := make(map[int]int, producers)
+
+		for _, e := range elems {
+			if seen[e] {
+				t.Fatalf("element %v received twice", e)
+			}
+
+			seen[e] = true
+
+			if prev, ok := last[e[0]]; ok && e[1] <= prev {
+				t.Fatalf("producer %d: got %d after %d", e[0], e[1], prev)
+			}
+
+			last[e[0]] = e[1]
+		}
+	}
+
+	if len(seen) != producers*perProd {
+		t.Fatalf("expected %d elements, got %d", producers*perProd, len(seen))
+	}
+}
+
+func testLockFreeGetReleasesReference(t *testing.T) {
+	t.Parallel()
+
+	// payload is 16 bytes so that it is not batched with other objects
+	// by the tiny allocator, which would delay its finalizer.
+	type payload struct {
+		id int
+		_  int
+	}
+
+	lockFree := queue.NewLockFree[*payload](nil)
+
+	finalized := make(chan struct{}, 1)
+
+	// Create the payload in a nested scope so that nothing but the queue
+	// can keep it alive after the offer/get cycle.
+	func() {
+		p := &payload{id: 42}
+		runtime.SetFinalizer(p, func(*payload) {
+			finalized <- struct{}{}
+		})
+
+		_ = lockFree.Offer(p)
+
+		if got, err := lockFree.Get(); err != nil || got != p {
+			t.Fatalf("get: %v, %v", got, err)
+		}
+	}()
+
+	// The node of the dequeued element is now the sentinel, which must
+	// not keep the element reachable.
+	deadline := time.After(time.Second)
+
+	for {
+		runtime.GC() //nolint:revive // explicit GC needed to drive finalizer
+
+		select {
+		case <-finalized:
+			runtime.KeepAlive(lockFree)
+			return
+		case <-deadline:
+			runtime.KeepAlive(lockFree)
+			t.Fatal("dequeued element not finalized; the sentinel still holds it")
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func BenchmarkLockFreeQueue(b *testing.B) {
+	b.Run("Peek", func(b *testing.B) {
+		lockFree := queue.NewLockFree([]int{1})
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i <= b.N; i++ {
+			_, _ = lockFree.Peek()
+		}
+	})
+
+	b.Run("Get_Offer", func(b *testing.B) {
+		lockFree := queue.NewLockFree([]int{1})
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i <= b.N; i++ {
+			_, _ = lockFree.Get()
+
+			_ = lockFree.Offer(1)
+		}
+	})
+
+	b.Run("Offer", func(b *testing.B) {
+		lockFree := queue.NewLockFree[int](nil)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i <= b.N; i++ {
+			_ = lockFree.Offer(i)
+		}
+	})
+}
+
+// BenchmarkContention compares the throughput of paired Offer and Get
+// calls from 1 to 64 goroutines across the unbounded FIFO queues.
+func BenchmarkContention(b *testing.B) {
+	queues := []struct {
+		name string
+		new  func() queue.Queue[int]
+	}{
+		{name: "LockFree", new: func() queue.Queue[int] { return queue.NewLockFree[int](nil) }},
+		{name: "Sharded", new: func() queue.Queue[int] { return queue.NewSharded[int](nil) }},
+		{name: "Linked", new: func() queue.Queue[int] { return queue.NewLinked[int](nil) }},
+		{name: "MultiPriority", new: func() queue.Queue[int] { return queue.NewMultiPriority[int](nil, lessInt) }},
+		{name: "Priority", new: func() queue.Queue[int] { return queue.NewPriority[int](nil, lessInt) }},
+		{name: "Blocking", new: func() queue.Queue[int] { return queue.NewBlocking[int](nil) }},
+	}
+
+	for _, q := range queues {
+		for _, goroutines := range []int{1, 2, 4, 8, 16, 32, 64} {
+			q, goroutines := q, goroutines
+
+			b.Run(fmt.Sprintf("%s/goroutines=%d", q.name, goroutines), func(b *testing.B) {
+				benchmarkContention(b, q.new(), goroutines)
+			})
+		}
+	}
+}
+
+// benchmarkContention splits b.N Offer and Get pairs across goroutines.
+func benchmarkContention(b *testing.B, q queue.Queue[int], goroutines int) {
+	b.Helper()
+
+	perGoroutine := b.N/goroutines + 1
+
+	var wg sync.WaitGroup
+
+	wg.Add(goroutines)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for g := 0; g < goroutines; g++ {
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < perGoroutine; i++ {
+				_ = q.Offer(i)
+				_, _ = q.Get()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
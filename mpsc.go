@@ -0,0 +1,270 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+)
+
+// mpscNode is an individual element of the MPSC linked list.
+type mpscNode[T any] struct {
+	value T
+	next  atomic.Pointer[mpscNode[T]]
+}
+
+// Ensure MPSC implements the Queue interface.
+var _ Queue[any] = (*MPSC[any])(nil)
+
+// MPSC is an unbounded FIFO Queue implementation for any number of
+// producer goroutines and a single consumer goroutine, such as an actor
+// mailbox. It is based on Dmitry Vyukov's MPSC queue: Offer links a node
+// with a single atomic swap and never retries, and the consumer walks the
+// list without any atomic read-modify-write at all.
+//
+// Offer may be called by any goroutine. Every other method except Size
+// and IsEmpty may only be called by the consumer. When built with -race,
+// overlapping calls from two consumers panic instead of silently
+// corrupting the queue.
+//
+// A producer links its node in two steps, so an element offered while
+// another producer is between these steps only becomes visible once that
+// producer completes: the queue may briefly report no element although
+// Offer already returned in another goroutine. GetWait covers this gap.
+type MPSC[T comparable] struct {
+	// Producer side: tail is the most recently offered node.
+	tail atomic.Pointer[mpscNode[T]]
+	_    [cacheLineSize]byte
+
+	// Consumer side: head is a sentinel node; the first element is
+	// head.next.
+	head *mpscNode[T]
+	_    [cacheLineSize]byte
+
+	size atomic.Int64
+
+	notEmpty parker // the consumer parks here
+
+	// consuming detects concurrent consumer calls when the race detector
+	// is on.
+	consuming atomic.Bool
+
+	initialElems []T
+}
+
+// NewMPSC returns a new MPSC queue containing the given elements.
+func NewMPSC[T comparable](elems []T) *MPSC[T] {
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	mq := &MPSC[T]{initialElems: initialElems}
+
+	sentinel := &mpscNode[T]{}
+	mq.head = sentinel
+	mq.tail.Store(sentinel)
+
+	mq.notEmpty.init()
+
+	for _, e := range initialElems {
+		_ = mq.Offer(e)
+	}
+
+	return mq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element to the tail of the queue. It never fails.
+// Any goroutine may call Offer.
+func (mq *MPSC[T]) Offer(elem T) error {
+	n := &mpscNode[T]{value: elem}
+
+	// Swapping the tail orders the producers; linking the previous tail
+	// to n then makes n reachable for the consumer.
+	prev := mq.tail.Swap(n)
+	prev.next.Store(n)
+
+	mq.size.Add(1)
+	mq.notEmpty.wake()
+
+	return nil
+}
+
+// Reset sets the queue to its initial state with the original elements,
+// by draining it and offering the original elements again. Elements
+// offered concurrently by producers may end up before the original ones.
+// Only the consumer may call Reset.
+func (mq *MPSC[T]) Reset() {
+	_ = mq.Clear()
+
+	for _, e := range mq.initialElems {
+		_ = mq.Offer(e)
+	}
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+// Only the consumer may call Get.
+func (mq *MPSC[T]) Get() (v T, _ error) {
+	enterSide(&mq.consuming, "consumer")
+	defer exitSide(&mq.consuming)
+
+	if !mq.available() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return mq.pop(), nil
+}
+
+// GetWait removes and returns the head of the queue.
+// If no element is available it spins briefly and then parks until a
+// producer offers one, or returns the context's error once ctx is done.
+// Only the consumer may call GetWait.
+func (mq *MPSC[T]) GetWait(ctx context.Context) (v T, _ error) {
+	enterSide(&mq.consuming, "consumer")
+	defer exitSide(&mq.consuming)
+
+	if err := mq.notEmpty.wait(ctx, mq.available); err != nil {
+		return v, err
+	}
+
+	return mq.pop(), nil
+}
+
+// DrainTo removes up to len(buf) elements from the head of the queue,
+// stores them in buf in order and returns how many it removed. It never
+// waits. Only the consumer may call DrainTo.
+func (mq *MPSC[T]) DrainTo(buf []T) int {
+	enterSide(&mq.consuming, "consumer")
+	defer exitSide(&mq.consuming)
+
+	n := 0
+
+	for n < len(buf) && mq.available() {
+		buf[n] = mq.pop()
+		n++
+	}
+
+	return n
+}
+
+// Clear removes and returns all elements from the queue.
+// Only the consumer may call Clear.
+func (mq *MPSC[T]) Clear() []T {
+	enterSide(&mq.consuming, "consumer")
+	defer exitSide(&mq.consuming)
+
+	removed := make([]T, 0, mq.Size())
+
+	for mq.available() {
+		removed = append(removed, mq.pop())
+	}
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this queue.
+// It removes the elements from the queue.
+// Only the consumer may call Iterator.
+func (mq *MPSC[T]) Iterator() <-chan T {
+	elems := mq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+// Only the consumer may call Peek.
+func (mq *MPSC[T]) Peek() (v T, _ error) {
+	enterSide(&mq.consuming, "consumer")
+	defer exitSide(&mq.consuming)
+
+	if !mq.available() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return mq.head.next.Load().value, nil
+}
+
+// Size returns the number of elements in the queue. While producers run
+// it is an approximation. Any goroutine may call Size.
+func (mq *MPSC[T]) Size() int {
+	if n := mq.size.Load(); n > 0 {
+		return int(n)
+	}
+
+	return 0
+}
+
+// IsEmpty returns true if the queue is empty. Any goroutine may call
+// IsEmpty.
+func (mq *MPSC[T]) IsEmpty() bool {
+	return mq.Size() == 0
+}
+
+// Contains returns true if the queue contains the given element.
+// Only the consumer may call Contains.
+func (mq *MPSC[T]) Contains(elem T) bool {
+	enterSide(&mq.consuming, "consumer")
+	defer exitSide(&mq.consuming)
+
+	for n := mq.head.next.Load(); n != nil; n = n.next.Load() {
+		if n.value == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the MPSC queue to JSON.
+// Only the consumer may call MarshalJSON.
+func (mq *MPSC[T]) MarshalJSON() ([]byte, error) {
+	enterSide(&mq.consuming, "consumer")
+
+	output := []T{}
+
+	for n := mq.head.next.Load(); n != nil; n = n.next.Load() {
+		output = append(output, n.value)
+	}
+
+	exitSide(&mq.consuming)
+
+	return json.Marshal(output)
+}
+
+// ===================================Helpers==================================
+
+// available reports whether an element can be read. Consumer side.
+func (mq *MPSC[T]) available() bool {
+	return mq.head.next.Load() != nil
+}
+
+// pop removes the head element, which must be available, and returns it.
+// Consumer side.
+func (mq *MPSC[T]) pop() T {
+	next := mq.head.next.Load()
+	elem := next.value
+
+	// next becomes the new sentinel. Producers only ever write the next
+	// field of a node, so its value can be cleared to release the element.
+	var zero T
+
+	next.value = zero
+	mq.head = next
+
+	mq.size.Add(-1)
+
+	return elem
+}
@@ -0,0 +1,60 @@
+//go:build race
+
+package queue_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestMPSCConcurrentConsumers(t *testing.T) {
+	t.Parallel()
+
+	const want = "queue: concurrent consumers on a single-consumer queue"
+
+	mpsc := queue.NewMPSC[int](nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first consumer stays inside GetWait until an element arrives,
+	// unless it is the one to detect the second consumer, by entering
+	// GetWait while a Get below runs.
+	first := make(chan any, 1)
+
+	go func() {
+		first <- panicValue(func() { _, _ = mpsc.GetWait(ctx) })
+	}()
+
+	// The queue is empty, so Get fails without a side effect until the
+	// first consumer is inside GetWait, and then panics.
+	for {
+		select {
+		case p := <-first:
+			if p != want {
+				t.Fatalf("expected panic %q, got %v", want, p)
+			}
+
+			return
+		default:
+		}
+
+		if p := panicValue(func() { _, _ = mpsc.Get() }); p != nil {
+			if p != want {
+				t.Fatalf("expected panic %q, got %v", want, p)
+			}
+
+			// Let the first consumer complete.
+			cancel()
+
+			<-first
+
+			return
+		}
+
+		runtime.Gosched()
+	}
+}
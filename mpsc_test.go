@@ -0,0 +1,302 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestMPSC(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FIFO", testMPSCFIFO)
+	t.Run("Empty", testMPSCEmpty)
+	t.Run("GetWait", testMPSCGetWait)
+	t.Run("GetWaitContextCancelled", testMPSCGetWaitContextCancelled)
+	t.Run("DrainTo", testMPSCDrainTo)
+	t.Run("SizeAndContains", testMPSCSizeAndContains)
+	t.Run("ClearAndIterator", testMPSCClearAndIterator)
+	t.Run("Reset", testMPSCReset)
+	t.Run("MarshalJSON", testMPSCMarshalJSON)
+	t.Run("ConcurrentProducers", testMPSCConcurrentProducers)
+}
+
+func testMPSCFIFO(t *testing.T) {
+	t.Parallel()
+
+	mpsc := queue.NewMPSC([]int{1, 2})
+
+	if err := mpsc.Offer(3); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if got, _ := mpsc.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	for want := 1; want <= 3; want++ {
+		got, err := mpsc.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testMPSCEmpty(t *testing.T) {
+	t.Parallel()
+
+	mpsc := queue.NewMPSC[int](nil)
+
+	if _, err := mpsc.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := mpsc.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !mpsc.IsEmpty() || mpsc.Size() != 0 {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testMPSCGetWait(t *testing.T) {
+	t.Parallel()
+
+	mpsc := queue.NewMPSC[int](nil)
+
+	go func() {
+		// Give the consumer time to spin and park.
+		time.Sleep(10 * time.Millisecond)
+
+		_ = mpsc.Offer(1)
+	}()
+
+	got, err := mpsc.GetWait(context.Background())
+	if err != nil {
+		t.Fatalf("get wait: %v", err)
+	}
+
+	if got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+}
+
+func testMPSCGetWaitContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	mpsc := queue.NewMPSC[int](nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := mpsc.GetWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	// The consumer can wait again after a cancelled wait.
+	_ = mpsc.Offer(1)
+
+	if got, err := mpsc.GetWait(context.Background()); err != nil || got != 1 {
+		t.Fatalf("expected 1, got %d (%v)", got, err)
+	}
+}
+
+func testMPSCDrainTo(t *testing.T) {
+	t.Parallel()
+
+	mpsc := queue.NewMPSC([]int{1, 2, 3, 4, 5})
+
+	buf := make([]int, 3)
+
+	if n := mpsc.DrainTo(buf); n != 3 || !reflect.DeepEqual(buf, []int{1, 2, 3}) {
+		t.Fatalf("expected 3 elements [1 2 3], got %d %v", n, buf)
+	}
+
+	if n := mpsc.DrainTo(buf); n != 2 || !reflect.DeepEqual(buf[:n], []int{4, 5}) {
+		t.Fatalf("expected 2 elements [4 5], got %d %v", n, buf[:n])
+	}
+
+	if n := mpsc.DrainTo(buf); n != 0 {
+		t.Fatalf("expected an empty drain, got %d", n)
+	}
+}
+
+func testMPSCSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	mpsc := queue.NewMPSC([]int{1, 2})
+
+	if mpsc.Size() != 2 || mpsc.IsEmpty() {
+		t.Fatalf("expected size 2, got %d", mpsc.Size())
+	}
+
+	if !mpsc.Contains(2) || mpsc.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testMPSCClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	mpsc := queue.NewMPSC([]int{1, 2, 3})
+
+	if got := mpsc.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	_ = mpsc.Offer(4)
+
+	var got []int
+
+	for e := range mpsc.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{4}) {
+		t.Fatalf("expected [4], got %v", got)
+	}
+}
+
+func testMPSCReset(t *testing.T) {
+	t.Parallel()
+
+	elems := []int{1, 2}
+
+	mpsc := queue.NewMPSC(elems)
+
+	// Mutating the caller's slice must not leak into the queue.
+	elems[0] = 100
+
+	_, _ = mpsc.Get()
+	_ = mpsc.Offer(3)
+
+	mpsc.Reset()
+
+	if got := mpsc.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testMPSCMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewMPSC([]int{1, 2}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2]" {
+		t.Fatalf("expected [1,2], got %s", data)
+	}
+
+	data, err = queue.NewMPSC[int](nil).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[]" {
+		t.Fatalf("expected [], got %s", data)
+	}
+}
+
+func testMPSCConcurrentProducers(t *testing.T) {
+	t.Parallel()
+
+	const (
+		producers   = 8
+		perProducer = 5_000
+	)
+
+	mpsc := queue.NewMPSC[int](nil)
+
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			for i := 0; i < perProducer; i++ {
+				_ = mpsc.Offer(p*perProducer + i)
+			}
+		}(p)
+	}
+
+	// Every element arrives exactly once and in order per producer.
+	next := make([]int, producers)
+	buf := make([]int, 64)
+
+	for received := 0; received < producers*perProducer; {
+		elem, err := mpsc.GetWait(context.Background())
+		if err != nil {
+			t.Fatalf("get wait: %v", err)
+		}
+
+		n := mpsc.DrainTo(buf)
+
+		for _, e := range append([]int{elem}, buf[:n]...) {
+			p, i := e/perProducer, e%perProducer
+
+			if i != next[p] {
+				t.Fatalf("producer %d: got element %d want %d", p, i, next[p])
+			}
+
+			next[p]++
+			received++
+		}
+	}
+}
+
+// BenchmarkMailbox fans elements in from several producers to a single
+// consumer.
+func BenchmarkMailbox(b *testing.B) {
+	queues := []struct {
+		name string
+		new  func() queue.Queue[int]
+	}{
+		{name: "MPSC", new: func() queue.Queue[int] { return queue.NewMPSC[int](nil) }},
+		{name: "Linked", new: func() queue.Queue[int] { return queue.NewLinked[int](nil) }},
+	}
+
+	for _, q := range queues {
+		for _, producers := range []int{1, 4, 16} {
+			q, producers := q, producers
+
+			b.Run(fmt.Sprintf("%s/producers=%d", q.name, producers), func(b *testing.B) {
+				mailbox := q.new()
+				perProducer := b.N/producers + 1
+
+				var wg sync.WaitGroup
+
+				wg.Add(producers)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for p := 0; p < producers; p++ {
+					go func() {
+						defer wg.Done()
+
+						for i := 0; i < perProducer; i++ {
+							_ = mailbox.Offer(i)
+						}
+					}()
+				}
+
+				for received := 0; received < producers*perProducer; {
+					if _, err := mailbox.Get(); err == nil {
+						received++
+					}
+				}
+
+				wg.Wait()
+			})
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package queue
+
+import (
+	"container/heap"
+	"encoding/json"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// multiPriorityFactor is the number of heaps a MultiPriority queue keeps
+// per processor unless WithShards is given.
+const multiPriorityFactor = 2
+
+// mpHeap is one of the heaps of a MultiPriority queue, padded so that the
+// locks of neighbouring heaps do not share a cache line.
+type mpHeap[T comparable] struct {
+	lock sync.Mutex
+	heap priorityHeap[T]
+	_    [cacheLineSize]byte
+}
+
+// peek returns the head of the heap, if any.
+func (h *mpHeap[T]) peek() (elem T, ok bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.heap.Len() == 0 {
+		return elem, false
+	}
+
+	return h.heap.elems[0], true
+}
+
+// pop removes and returns the head of the heap, if any.
+func (h *mpHeap[T]) pop() (elem T, ok bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.heap.Len() == 0 {
+		return elem, false
+	}
+
+	// nolint: forcetypeassert, revive // the heap only ever holds T values.
+	return heap.Pop(&h.heap).(T), true
+}
+
+// len returns the number of elements in the heap.
+func (h *mpHeap[T]) len() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.heap.Len()
+}
+
+// contains reports whether the heap holds elem.
+func (h *mpHeap[T]) contains(elem T) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, e := range h.heap.elems {
+		if e == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ensure MultiPriority implements the Queue interface.
+var _ Queue[any] = (*MultiPriority[any])(nil)
+
+// MultiPriority is a relaxed concurrent priority Queue implementation, a
+// MultiQueue: it spreads its elements over several heaps, each with its
+// own lock, ordered by the same lessFunc as Priority.
+//
+// Offer pushes the element to a randomly chosen heap. Get looks at the
+// heads of two distinct, randomly chosen heaps and pops the one with the
+// higher priority; only if both are empty does it fall back to the other
+// heaps. Concurrent Offer and Get calls thus rarely contend on the same
+// lock and throughput scales with the number of goroutines, at the price
+// of strict priority order.
+//
+// Relaxation: with n heaps, Get returns one of the O(n) highest priority
+// elements in expectation, and returns the highest priority element with
+// a probability of about 2/n. The expected rank does not grow with the
+// number of elements. With a single heap, set with WithShards(1),
+// MultiPriority is an exact priority queue. Peek, Clear, Iterator and
+// MarshalJSON are not relaxed: they look at every heap.
+//
+// The number of heaps defaults to twice runtime.GOMAXPROCS(0).
+// MultiPriority is unbounded.
+type MultiPriority[T comparable] struct {
+	heaps        []mpHeap[T]
+	lessFunc     func(elem, otherElem T) bool
+	initialElems []T
+}
+
+// NewMultiPriority returns a new MultiPriority queue containing the given
+// elements, ordered by lessFunc as in NewPriority.
+// Panics if lessFunc is nil or if WithShards is not positive.
+func NewMultiPriority[T comparable](
+	elems []T,
+	lessFunc func(elem, otherElem T) bool,
+	opts ...Option,
+) *MultiPriority[T] {
+	if lessFunc == nil {
+		panic("nil less func")
+	}
+
+	options := options{
+		shards: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	n := multiPriorityFactor * runtime.GOMAXPROCS(0)
+
+	if options.shards != nil {
+		if *options.shards <= 0 {
+			panic("shard count must be positive")
+		}
+
+		n = *options.shards
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	mq := &MultiPriority[T]{
+		heaps:        make([]mpHeap[T], n),
+		lessFunc:     lessFunc,
+		initialElems: initialElems,
+	}
+
+	for i := range mq.heaps {
+		mq.heaps[i].heap.lessFunc = lessFunc
+	}
+
+	mq.reset()
+
+	return mq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element into a randomly chosen heap. It never fails.
+func (mq *MultiPriority[T]) Offer(elem T) error {
+	h := &mq.heaps[rand.Intn(len(mq.heaps))] //nolint:gosec // load balancing only
+
+	h.lock.Lock()
+	heap.Push(&h.heap, elem)
+	h.lock.Unlock()
+
+	return nil
+}
+
+// Reset sets the queue to its initial state with the original elements.
+func (mq *MultiPriority[T]) Reset() {
+	mq.lockAll()
+	defer mq.unlockAll()
+
+	mq.reset()
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the higher priority head of two distinct,
+// randomly chosen heaps, or of the only heap; see MultiPriority for how
+// far it may be from the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (mq *MultiPriority[T]) Get() (v T, _ error) {
+	n := len(mq.heaps)
+
+	i := rand.Intn(n) //nolint:gosec // load balancing only
+	j := i
+
+	// Draw j from the other n-1 heaps, so that two heaps are compared.
+	if n > 1 {
+		j = rand.Intn(n - 1) //nolint:gosec // load balancing only
+		if j >= i {
+			j++
+		}
+	}
+
+	a, okA := mq.heaps[i].peek()
+	b, okB := mq.heaps[j].peek()
+
+	if okB && (!okA || mq.lessFunc(b, a)) {
+		i = j
+	}
+
+	// The chosen heap may have been drained in the meantime, in which
+	// case any element will do.
+	if elem, ok := mq.heaps[i].pop(); ok {
+		return elem, nil
+	}
+
+	for k := 1; k < len(mq.heaps); k++ {
+		if elem, ok := mq.heaps[(i+k)%len(mq.heaps)].pop(); ok {
+			return elem, nil
+		}
+	}
+
+	return v, ErrNoElementsAvailable
+}
+
+// Clear removes and returns all elements from the queue, in priority
+// order.
+func (mq *MultiPriority[T]) Clear() []T {
+	mq.lockAll()
+	defer mq.unlockAll()
+
+	elems := mq.sorted()
+
+	for i := range mq.heaps {
+		mq.heaps[i].heap.elems = nil
+	}
+
+	return elems
+}
+
+// Iterator returns an iterator over the elements in the queue, in
+// priority order.
+// It removes the elements from the queue.
+func (mq *MultiPriority[T]) Iterator() <-chan T {
+	elems := mq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the highest priority element of the
+// queue. A subsequent Get may return a different element.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (mq *MultiPriority[T]) Peek() (v T, _ error) {
+	found := false
+
+	for i := range mq.heaps {
+		if elem, ok := mq.heaps[i].peek(); ok && (!found || mq.lessFunc(elem, v)) {
+			v, found = elem, true
+		}
+	}
+
+	if !found {
+		return v, ErrNoElementsAvailable
+	}
+
+	return v, nil
+}
+
+// Size returns the number of elements in the queue. As the heaps are
+// visited one after the other, it is exact only when no other goroutine
+// uses the queue.
+func (mq *MultiPriority[T]) Size() int {
+	size := 0
+
+	for i := range mq.heaps {
+		size += mq.heaps[i].len()
+	}
+
+	return size
+}
+
+// IsEmpty returns true if the queue is empty.
+func (mq *MultiPriority[T]) IsEmpty() bool {
+	return mq.Size() == 0
+}
+
+// Contains returns true if the queue contains the given element.
+func (mq *MultiPriority[T]) Contains(elem T) bool {
+	for i := range mq.heaps {
+		if mq.heaps[i].contains(elem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the MultiPriority queue to JSON in priority
+// order.
+func (mq *MultiPriority[T]) MarshalJSON() ([]byte, error) {
+	mq.lockAll()
+	output := mq.sorted()
+	mq.unlockAll()
+
+	return json.Marshal(output)
+}
+
+// ===================================Helpers==================================
+
+// lockAll locks every heap, in order.
+func (mq *MultiPriority[T]) lockAll() {
+	for i := range mq.heaps {
+		mq.heaps[i].lock.Lock()
+	}
+}
+
+// unlockAll unlocks every heap.
+func (mq *MultiPriority[T]) unlockAll() {
+	for i := range mq.heaps {
+		mq.heaps[i].lock.Unlock()
+	}
+}
+
+// sorted returns the elements of every heap in priority order. Caller
+// must hold every lock.
+func (mq *MultiPriority[T]) sorted() []T {
+	elems := []T{}
+
+	for i := range mq.heaps {
+		elems = append(elems, mq.heaps[i].heap.elems...)
+	}
+
+	sort.Slice(elems, func(i, j int) bool {
+		return mq.lessFunc(elems[i], elems[j])
+	})
+
+	return elems
+}
+
+// reset spreads the initial elements over the heaps in round-robin order.
+// Caller must hold every lock.
+func (mq *MultiPriority[T]) reset() {
+	for i := range mq.heaps {
+		mq.heaps[i].heap.elems = nil
+	}
+
+	for i, e := range mq.initialElems {
+		h := &mq.heaps[i%len(mq.heaps)].heap
+
+		h.elems = append(h.elems, e)
+	}
+
+	for i := range mq.heaps {
+		heap.Init(&mq.heaps[i].heap)
+	}
+}
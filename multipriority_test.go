@@ -0,0 +1,313 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestMultiPriority(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NilLessFunc", testMultiPriorityNilLessFunc)
+	t.Run("NonPositiveShards", testMultiPriorityNonPositiveShards)
+	t.Run("SingleHeapIsExact", testMultiPrioritySingleHeapIsExact)
+	t.Run("TwoHeapsAreExact", testMultiPriorityTwoHeapsAreExact)
+	t.Run("GetReturnsEveryElement", testMultiPriorityGetReturnsEveryElement)
+	t.Run("RelaxedOrder", testMultiPriorityRelaxedOrder)
+	t.Run("Empty", testMultiPriorityEmpty)
+	t.Run("PeekIsExact", testMultiPriorityPeekIsExact)
+	t.Run("SizeAndContains", testMultiPrioritySizeAndContains)
+	t.Run("ClearAndIterator", testMultiPriorityClearAndIterator)
+	t.Run("Reset", testMultiPriorityReset)
+	t.Run("MarshalJSON", testMultiPriorityMarshalJSON)
+	t.Run("Concurrent", testMultiPriorityConcurrent)
+}
+
+func testMultiPriorityNilLessFunc(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "nil less func" {
+			t.Fatalf("expected panic 'nil less func', got %v", p)
+		}
+	}()
+
+	_ = queue.NewMultiPriority[int](nil, nil)
+}
+
+func testMultiPriorityNonPositiveShards(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "shard count must be positive" {
+			t.Fatalf("expected panic 'shard count must be positive', got %v", p)
+		}
+	}()
+
+	_ = queue.NewMultiPriority[int](nil, lessInt, queue.WithShards(-1))
+}
+
+func testMultiPrioritySingleHeapIsExact(t *testing.T) {
+	t.Parallel()
+
+	multiPriority := queue.NewMultiPriority([]int{5, 1, 4}, lessInt, queue.WithShards(1))
+
+	_ = multiPriority.Offer(2)
+	_ = multiPriority.Offer(3)
+
+	for want := 1; want <= 5; want++ {
+		got, err := multiPriority.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testMultiPriorityTwoHeapsAreExact(t *testing.T) {
+	t.Parallel()
+
+	const n = 100
+
+	multiPriority := queue.NewMultiPriority[int](nil, lessInt, queue.WithShards(2))
+
+	for i := n - 1; i >= 0; i-- {
+		_ = multiPriority.Offer(i)
+	}
+
+	// Get compares the heads of two distinct heaps, which with two heaps
+	// are the heads of both, so it never relaxes the order.
+	for want := 0; want < n; want++ {
+		if got, err := multiPriority.Get(); err != nil || got != want {
+			t.Fatalf("expected %d, got %d (%v)", want, got, err)
+		}
+	}
+}
+
+func testMultiPriorityGetReturnsEveryElement(t *testing.T) {
+	t.Parallel()
+
+	multiPriority := queue.NewMultiPriority[int](nil, lessInt, queue.WithShards(8))
+
+	// A lone element is usually on neither of the two heaps Get looks at
+	// first, which makes it fall back to the others.
+	for i := 0; i < 50; i++ {
+		_ = multiPriority.Offer(i)
+
+		got, err := multiPriority.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != i {
+			t.Fatalf("got %d want %d", got, i)
+		}
+	}
+}
+
+func testMultiPriorityRelaxedOrder(t *testing.T) {
+	t.Parallel()
+
+	const (
+		heaps = 4
+		n     = 10_000
+	)
+
+	multiPriority := queue.NewMultiPriority[int](nil, lessInt, queue.WithShards(heaps))
+
+	for i := n - 1; i >= 0; i-- {
+		_ = multiPriority.Offer(i)
+	}
+
+	// Every Get returns an element close to the head: the mean rank of
+	// the returned elements stays in the order of the number of heaps.
+	remaining := make([]bool, n)
+	for i := range remaining {
+		remaining[i] = true
+	}
+
+	head, totalRank := 0, 0
+
+	for i := 0; i < n; i++ {
+		got, err := multiPriority.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		for rank := head; rank < got; rank++ {
+			if remaining[rank] {
+				totalRank++
+			}
+		}
+
+		remaining[got] = false
+
+		for head < n && !remaining[head] {
+			head++
+		}
+	}
+
+	if mean := float64(totalRank) / n; mean > 4*heaps {
+		t.Fatalf("mean rank %.1f too large for %d heaps", mean, heaps)
+	}
+}
+
+func testMultiPriorityEmpty(t *testing.T) {
+	t.Parallel()
+
+	multiPriority := queue.NewMultiPriority[int](nil, lessInt, queue.WithShards(3))
+
+	if _, err := multiPriority.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := multiPriority.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !multiPriority.IsEmpty() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testMultiPriorityPeekIsExact(t *testing.T) {
+	t.Parallel()
+
+	multiPriority := queue.NewMultiPriority([]int{7, 3, 9, 1, 8}, lessInt, queue.WithShards(3))
+
+	if got, err := multiPriority.Peek(); err != nil || got != 1 {
+		t.Fatalf("expected 1, got %d (%v)", got, err)
+	}
+}
+
+func testMultiPrioritySizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	multiPriority := queue.NewMultiPriority([]int{1, 2, 3}, lessInt, queue.WithShards(2))
+
+	if multiPriority.Size() != 3 || multiPriority.IsEmpty() {
+		t.Fatalf("expected size 3, got %d", multiPriority.Size())
+	}
+
+	if !multiPriority.Contains(3) || multiPriority.Contains(4) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testMultiPriorityClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	multiPriority := queue.NewMultiPriority([]int{3, 1, 2}, lessInt)
+
+	if got := multiPriority.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	if !multiPriority.IsEmpty() {
+		t.Fatal("expected an empty queue after Clear")
+	}
+
+	_ = multiPriority.Offer(5)
+	_ = multiPriority.Offer(4)
+
+	var got []int
+
+	for e := range multiPriority.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{4, 5}) {
+		t.Fatalf("expected [4 5], got %v", got)
+	}
+}
+
+func testMultiPriorityReset(t *testing.T) {
+	t.Parallel()
+
+	elems := []int{3, 1, 2}
+
+	multiPriority := queue.NewMultiPriority(elems, lessInt, queue.WithShards(2))
+
+	elems[0] = 100
+
+	_, _ = multiPriority.Get()
+	_ = multiPriority.Offer(4)
+
+	multiPriority.Reset()
+
+	if got := multiPriority.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+}
+
+func testMultiPriorityMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewMultiPriority([]int{3, 1, 2}, lessInt).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2,3]" {
+		t.Fatalf("expected [1,2,3], got %s", data)
+	}
+}
+
+func testMultiPriorityConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goroutines   = 8
+		perGoroutine = 1_000
+	)
+
+	multiPriority := queue.NewMultiPriority[int](nil, lessInt)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		received []int
+	)
+
+	wg.Add(goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			var local []int
+
+			for i := 0; i < perGoroutine; i++ {
+				_ = multiPriority.Offer(g*perGoroutine + i)
+
+				if elem, err := multiPriority.Get(); err == nil {
+					local = append(local, elem)
+				}
+			}
+
+			mu.Lock()
+			received = append(received, local...)
+			mu.Unlock()
+		}(g)
+	}
+
+	wg.Wait()
+
+	received = append(received, multiPriority.Clear()...)
+
+	sort.Ints(received)
+
+	for i, e := range received {
+		if e != i {
+			t.Fatalf("element %d missing or duplicated, got %d", i, e)
+		}
+	}
+}
@@ -1,7 +1,39 @@
 package queue
 
+import (
+	"math/rand"
+	"time"
+)
+
 type options struct {
-	capacity *int
+	capacity  *int
+	clock     func() time.Time
+	tolerance time.Duration
+
+	// onExpire holds a func(T) for the queue's element type T; the
+	// constructor asserts it, since Option itself is not generic.
+	onExpire      any
+	sweepInterval time.Duration
+	shards        *int
+	rand          *rand.Rand
+
+	gapTimeout time.Duration
+	window     *int
+
+	// weight holds a func(T) float64 for the queue's element type T,
+	// asserted by the constructor like onExpire.
+	weight any
+
+	unique bool
+	index  bool
+	bloom  *bloomOptions
+	// hasher holds a func(T) uint64 for the queue's element type T,
+	// asserted by the constructor like onExpire.
+	hasher any
+
+	// merge holds a func(T, T) T for the queue's element type T,
+	// asserted by the constructor like onExpire.
+	merge any
 }
 
 // An Option configures a Queue using the functional options paradigm.
@@ -21,3 +53,228 @@ func (c capacityOption) apply(opts *options) {
 func WithCapacity(capacity int) Option {
 	return capacityOption(capacity)
 }
+
+type clockOption func() time.Time
+
+func (c clockOption) apply(opts *options) {
+	opts.clock = c
+}
+
+// WithClock replaces time.Now as the source of the current time for the
+// time-based queues (Delay, TimingWheel, Expiring and Resequencer). It is
+// mostly useful to drive those queues deterministically in tests. Timers
+// armed by the blocking methods still run on the wall clock, for the
+// duration computed from the injected clock.
+func WithClock(now func() time.Time) Option {
+	return clockOption(now)
+}
+
+type toleranceOption time.Duration
+
+func (t toleranceOption) apply(opts *options) {
+	opts.tolerance = time.Duration(t)
+}
+
+// WithTolerance makes the Delay queue treat every element whose deadline
+// is within d of now as due. Blocking waits still wake at the head's
+// deadline, and that wakeup releases every element due up to d after it,
+// so deadlines close together cost one wakeup instead of one each, at the
+// price of releasing elements up to d early.
+func WithTolerance(d time.Duration) Option {
+	return toleranceOption(d)
+}
+
+type onExpireOption[T any] func(T)
+
+func (fn onExpireOption[T]) apply(opts *options) {
+	opts.onExpire = (func(T))(fn)
+}
+
+// WithOnExpire registers fn to be called with every element the Expiring
+// queue discards because its TTL elapsed. T must be the queue's element
+// type, otherwise NewExpiring panics. fn is called without the queue lock
+// held, so it may use the queue.
+func WithOnExpire[T any](fn func(T)) Option {
+	return onExpireOption[T](fn)
+}
+
+type sweepIntervalOption time.Duration
+
+func (s sweepIntervalOption) apply(opts *options) {
+	opts.sweepInterval = time.Duration(s)
+}
+
+// WithSweepInterval makes the Expiring queue purge expired elements every
+// d in a background goroutine, in addition to the lazy cleanup done by
+// every operation. The goroutine runs until the queue's Close method is
+// called.
+func WithSweepInterval(d time.Duration) Option {
+	return sweepIntervalOption(d)
+}
+
+type shardsOption int
+
+func (s shardsOption) apply(opts *options) {
+	n := int(s)
+
+	opts.shards = &n
+}
+
+// WithShards sets the number of shards of a Sharded queue. It defaults to
+// runtime.GOMAXPROCS(0).
+func WithShards(n int) Option {
+	return shardsOption(n)
+}
+
+type randOption struct {
+	rand *rand.Rand
+}
+
+func (r randOption) apply(opts *options) {
+	opts.rand = r.rand
+}
+
+// WithRand makes the Random queue and the Reservoir draw their random
+// numbers from r instead of the global source of math/rand, so that a
+// seeded r makes them deterministic, e.g. in tests. They only use r while
+// holding their lock, so r must not be shared with other code.
+func WithRand(r *rand.Rand) Option {
+	return randOption{rand: r}
+}
+
+type weightOption[T any] func(T) float64
+
+func (fn weightOption[T]) apply(opts *options) {
+	opts.weight = (func(T) float64)(fn)
+}
+
+// WithWeight makes the Reservoir sample weighted: every element is kept
+// with a probability proportional to weight(elem). Elements whose weight
+// is not positive are never kept. T must be the reservoir's element type,
+// otherwise NewReservoir panics.
+func WithWeight[T any](weight func(T) float64) Option {
+	return weightOption[T](weight)
+}
+
+type gapTimeoutOption time.Duration
+
+func (g gapTimeoutOption) apply(opts *options) {
+	opts.gapTimeout = time.Duration(g)
+}
+
+// WithGapTimeout makes the Resequencer give up on a missing sequence
+// number once later elements have waited d for it: Get then skips to the
+// lowest buffered sequence number. By default it waits forever.
+func WithGapTimeout(d time.Duration) Option {
+	return gapTimeoutOption(d)
+}
+
+type windowOption int
+
+func (w windowOption) apply(opts *options) {
+	n := int(w)
+
+	opts.window = &n
+}
+
+// WithWindow bounds how far ahead of the next expected sequence number
+// the Resequencer accepts elements: Offer returns ErrWindowOverflow for
+// sequence numbers n or more past it. This also bounds the buffer to n
+// elements. By default the window is unbounded.
+func WithWindow(n int) Option {
+	return windowOption(n)
+}
+
+type mergeOption[T any] func(queued, offered T) T
+
+func (fn mergeOption[T]) apply(opts *options) {
+	opts.merge = (func(T, T) T)(fn)
+}
+
+// WithMerge makes the Coalescing queue combine an offered element with the
+// queued element of the same key, instead of replacing it: the queued
+// element becomes merge(queued, offered), keeping its position. T must be
+// the queue's element type, otherwise NewCoalescing panics.
+func WithMerge[T any](merge func(queued, offered T) T) Option {
+	return mergeOption[T](merge)
+}
+
+type uniqueOption struct{}
+
+func (uniqueOption) apply(opts *options) {
+	opts.unique = true
+}
+
+// WithUnique makes a Blocking, Linked, Circular, Priority or Delay queue
+// reject elements it already contains: Offer returns ErrDuplicate for
+// them, Blocking.OfferWait waits for them to be removed, and duplicates
+// among the initial elements are dropped. The queue keeps a hash set of
+// its elements alongside its storage, which also makes Contains O(1).
+func WithUnique() Option {
+	return uniqueOption{}
+}
+
+type indexOption struct{}
+
+func (indexOption) apply(opts *options) {
+	opts.index = true
+}
+
+// WithIndex makes a Blocking, Linked, Circular, Priority or Delay queue
+// keep a map from each element to the number of times it is queued,
+// updated on every insertion and removal. It makes Contains, Count and
+// MaybeContains O(1) and exact instead of a scan under the lock, at the
+// cost of a map entry per distinct element and a map update per Offer and
+// Get. WithUnique keeps the same map.
+func WithIndex() Option {
+	return indexOption{}
+}
+
+// bloomOptions sizes the counting Bloom filter of WithBloomFilter.
+type bloomOptions struct {
+	expected int
+	fpRate   float64
+}
+
+func (b bloomOptions) apply(opts *options) {
+	opts.bloom = &b
+}
+
+// WithBloomFilter makes a Blocking, Linked, Circular, Priority or Delay
+// queue keep a counting Bloom filter of its elements, sized for expectedN
+// elements with a false positive rate of fpRate, updated on every
+// insertion and removal. MaybeContains then runs in O(1), and may return
+// true for an element the queue does not contain, at about fpRate, but
+// never false for one it contains. Contains and Count only scan the queue
+// when the filter reports a possible match.
+//
+// Unlike WithIndex, it takes about a byte per counter whatever the
+// elements, but the false positive rate grows past expectedN elements.
+// It has no effect with WithIndex or WithUnique, which track elements
+// exactly. Without any of the three, MaybeContains is Contains.
+//
+// Elements are hashed with the WithHasher func if given, or else with
+// hash/maphash, field by field for structs and arrays. Pointers, channels
+// and other values compared by identity are hashed by address, not by
+// what they point to, so mutating a queued pointee is safe.
+//
+// The queue constructor panics if expectedN is not positive or fpRate is
+// not in (0, 1).
+func WithBloomFilter(expectedN int, fpRate float64) Option {
+	return bloomOptions{expected: expectedN, fpRate: fpRate}
+}
+
+type hasherOption struct {
+	hasher any
+}
+
+func (h hasherOption) apply(opts *options) {
+	opts.hasher = h.hasher
+}
+
+// WithHasher sets the func WithBloomFilter hashes elements with. Equal
+// elements must have equal hashes. The queue constructor panics if the
+// func does not take the element type of the queue.
+func WithHasher[T any](hasher func(T) uint64) Option {
+	return hasherOption{hasher: hasher}
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+)
+
+// parkSpins is the number of times a parker re-checks its condition,
+// yielding the processor in between, before it parks the goroutine.
+const parkSpins = 64
+
+// parker lets a single goroutine wait for a condition that other
+// goroutines make true without holding a lock, as used by the lock-free
+// queues. The waiter spins briefly, since the condition is usually met
+// within a few hundred nanoseconds on a busy queue, and then parks on a
+// channel until wake is called.
+//
+// Only one goroutine may wait on a parker at a time; any number of
+// goroutines may call wake.
+type parker struct {
+	parked atomic.Bool
+	wakeCh chan struct{}
+}
+
+// init prepares the parker for use.
+func (p *parker) init() {
+	p.wakeCh = make(chan struct{}, 1)
+}
+
+// wait returns once ready reports true, or with the context's error once
+// ctx is done.
+func (p *parker) wait(ctx context.Context, ready func() bool) error {
+	defer p.parked.Store(false)
+
+	for spins := 0; !ready(); spins++ {
+		if spins < parkSpins {
+			runtime.Gosched()
+
+			continue
+		}
+
+		if !p.parked.Swap(true) {
+			// Re-check ready after publishing the intent to park: a waker
+			// that makes ready true from now on sees parked and leaves a
+			// token in wakeCh.
+			continue
+		}
+
+		select {
+		case <-p.wakeCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// wake unparks the waiting goroutine, if any. It must be called after the
+// change that makes the waiter's condition true.
+func (p *parker) wake() {
+	if !p.parked.Load() {
+		return
+	}
+
+	select {
+	case p.wakeCh <- struct{}{}:
+	default:
+		// A wake-up is already pending.
+	}
+}
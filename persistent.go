@@ -0,0 +1,237 @@
+package queue
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// lazyList is a memoized lazy list: its first cell is computed by thunk
+// the first time it is forced, at most once, even when several
+// goroutines force it at the same time. A nil *lazyList is empty.
+type lazyList[T any] struct {
+	once  sync.Once
+	thunk func() *lazyCell[T]
+	cell  *lazyCell[T]
+}
+
+// lazyCell is an evaluated cell of a lazyList.
+type lazyCell[T any] struct {
+	head T
+	tail *lazyList[T]
+}
+
+// consLazy returns the already evaluated list with head followed by tail.
+func consLazy[T any](head T, tail *lazyList[T]) *lazyList[T] {
+	return &lazyList[T]{cell: &lazyCell[T]{head: head, tail: tail}}
+}
+
+// force evaluates the first cell of the list and returns it, or nil if
+// the list is empty.
+func (l *lazyList[T]) force() *lazyCell[T] {
+	if l == nil {
+		return nil
+	}
+
+	l.once.Do(func() {
+		if l.thunk != nil {
+			l.cell = l.thunk()
+			l.thunk = nil
+		}
+	})
+
+	return l.cell
+}
+
+// rearList is an immutable singly linked list holding the rear of a
+// Persistent queue, newest element first. A nil *rearList is empty.
+type rearList[T any] struct {
+	head T
+	tail *rearList[T]
+}
+
+// rotate lazily returns front ++ reverse(rear) ++ acc. rear must be one
+// element longer than front. Every forced cell performs O(1) work.
+func rotate[T any](front *lazyList[T], rear *rearList[T], acc *lazyList[T]) *lazyList[T] {
+	return &lazyList[T]{thunk: func() *lazyCell[T] {
+		c := front.force()
+
+		if c == nil {
+			return &lazyCell[T]{head: rear.head, tail: acc}
+		}
+
+		return &lazyCell[T]{
+			head: c.head,
+			tail: rotate(c.tail, rear.tail, consLazy(rear.head, acc)),
+		}
+	}}
+}
+
+// Persistent is an immutable FIFO queue with structural sharing, Okasaki's
+// real-time queue. Offer and Get never modify a queue: they return a new
+// version, in O(1) worst-case time, which shares almost all of its
+// structure with the old one. Every version stays valid and unchanged, so
+// keeping an old version is a free point-in-time snapshot.
+//
+// A Persistent queue is safe for concurrent use by multiple goroutines.
+// It is not a Queue itself, as its methods return new versions; Ref wraps
+// it into a mutable, thread-safe Queue.
+//
+// The zero value is an empty queue.
+type Persistent[T comparable] struct {
+	// The elements are front ++ reverse(rear). front is a lazy list
+	// whose unevaluated suffix is sched: forcing one cell of sched per
+	// operation keeps front evaluated by the time it is reached.
+	front    *lazyList[T]
+	rear     *rearList[T]
+	sched    *lazyList[T]
+	frontLen int
+	rearLen  int
+}
+
+// NewPersistent returns a new Persistent queue containing the given
+// elements.
+func NewPersistent[T comparable](elems []T) *Persistent[T] {
+	pq := &Persistent[T]{}
+
+	for _, e := range elems {
+		pq = pq.Offer(e)
+	}
+
+	return pq
+}
+
+// ==================================Insertion=================================
+
+// Offer returns a new version of the queue with the element inserted at
+// the tail. The receiver is left unchanged.
+func (pq *Persistent[T]) Offer(elem T) *Persistent[T] {
+	return makePersistent(
+		pq.front, pq.frontLen,
+		&rearList[T]{head: elem, tail: pq.rear}, pq.rearLen+1,
+		pq.sched,
+	)
+}
+
+// ===================================Removal==================================
+
+// Get returns the head of the queue and a new version of the queue
+// without it. The receiver is left unchanged.
+// If no element is available it returns an ErrNoElementsAvailable error
+// and the receiver.
+func (pq *Persistent[T]) Get() (v T, _ *Persistent[T], _ error) {
+	c := pq.front.force()
+
+	if c == nil {
+		return v, pq, ErrNoElementsAvailable
+	}
+
+	return c.head, makePersistent(c.tail, pq.frontLen-1, pq.rear, pq.rearLen, pq.sched), nil
+}
+
+// =================================Examination================================
+
+// Peek returns the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (pq *Persistent[T]) Peek() (v T, _ error) {
+	c := pq.front.force()
+
+	if c == nil {
+		return v, ErrNoElementsAvailable
+	}
+
+	return c.head, nil
+}
+
+// Size returns the number of elements in the queue.
+func (pq *Persistent[T]) Size() int {
+	return pq.frontLen + pq.rearLen
+}
+
+// IsEmpty returns true if the queue is empty.
+func (pq *Persistent[T]) IsEmpty() bool {
+	return pq.Size() == 0
+}
+
+// All returns an iterator over the elements in the queue, from head to
+// tail. Iteration stops when yield returns false.
+// With Go 1.23 or later, the result can be ranged over directly.
+func (pq *Persistent[T]) All() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for c := pq.front.force(); c != nil; c = c.tail.force() {
+			if !yield(c.head) {
+				return
+			}
+		}
+
+		rear := make([]T, 0, pq.rearLen)
+		for r := pq.rear; r != nil; r = r.tail {
+			rear = append(rear, r.head)
+		}
+
+		for i := len(rear) - 1; i >= 0; i-- {
+			if !yield(rear[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Elems returns the elements in the queue, from head to tail.
+func (pq *Persistent[T]) Elems() []T {
+	elems := make([]T, 0, pq.Size())
+
+	pq.All()(func(elem T) bool {
+		elems = append(elems, elem)
+
+		return true
+	})
+
+	return elems
+}
+
+// Contains returns true if the queue contains the given element.
+func (pq *Persistent[T]) Contains(elem T) bool {
+	found := false
+
+	pq.All()(func(e T) bool {
+		found = e == elem
+
+		return !found
+	})
+
+	return found
+}
+
+// MarshalJSON serializes the Persistent queue to JSON.
+func (pq *Persistent[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pq.Elems())
+}
+
+// ===================================Helpers==================================
+
+// makePersistent returns the queue front ++ reverse(rear) and advances the
+// schedule by one cell, rotating rear into front once the schedule is
+// exhausted, which happens exactly when rear becomes longer than front.
+func makePersistent[T comparable](
+	front *lazyList[T], frontLen int,
+	rear *rearList[T], rearLen int,
+	sched *lazyList[T],
+) *Persistent[T] {
+	if sched != nil {
+		return &Persistent[T]{
+			front:    front,
+			rear:     rear,
+			sched:    sched.force().tail,
+			frontLen: frontLen,
+			rearLen:  rearLen,
+		}
+	}
+
+	front = rotate(front, rear, nil)
+
+	return &Persistent[T]{
+		front:    front,
+		sched:    front,
+		frontLen: frontLen + rearLen,
+	}
+}
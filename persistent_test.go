@@ -0,0 +1,227 @@
+package queue_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestPersistent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ZeroValueIsEmpty", testPersistentZeroValueIsEmpty)
+	t.Run("FIFO", testPersistentFIFO)
+	t.Run("OldVersionsStayValid", testPersistentOldVersionsStayValid)
+	t.Run("Model", testPersistentModel)
+	t.Run("All", testPersistentAll)
+	t.Run("Contains", testPersistentContains)
+	t.Run("MarshalJSON", testPersistentMarshalJSON)
+	t.Run("SharedVersionAcrossGoroutines", testPersistentSharedVersionAcrossGoroutines)
+}
+
+func testPersistentZeroValueIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	var persistent queue.Persistent[int]
+
+	if !persistent.IsEmpty() || persistent.Size() != 0 {
+		t.Fatal("expected an empty queue")
+	}
+
+	if _, err := persistent.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	_, rest, err := persistent.Get()
+	if !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if rest != &persistent {
+		t.Fatal("expected a failed Get to return the receiver")
+	}
+}
+
+func testPersistentFIFO(t *testing.T) {
+	t.Parallel()
+
+	persistent := queue.NewPersistent([]int{1, 2}).Offer(3)
+
+	if got, _ := persistent.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	for want := 1; want <= 3; want++ {
+		var (
+			got int
+			err error
+		)
+
+		got, persistent, err = persistent.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+
+	if !persistent.IsEmpty() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testPersistentOldVersionsStayValid(t *testing.T) {
+	t.Parallel()
+
+	v1 := queue.NewPersistent([]int{1, 2, 3})
+	v2 := v1.Offer(4)
+	_, v3, _ := v2.Get()
+
+	// Both branches off v2 must see their own history only.
+	v4a := v3.Offer(5)
+	v4b := v3.Offer(6)
+
+	for _, tc := range []struct {
+		version *queue.Persistent[int]
+		want    []int
+	}{
+		{v1, []int{1, 2, 3}},
+		{v2, []int{1, 2, 3, 4}},
+		{v3, []int{2, 3, 4}},
+		{v4a, []int{2, 3, 4, 5}},
+		{v4b, []int{2, 3, 4, 6}},
+	} {
+		if got := tc.version.Elems(); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("expected %v, got %v", tc.want, got)
+		}
+
+		if tc.version.Size() != len(tc.want) {
+			t.Fatalf("expected size %d, got %d", len(tc.want), tc.version.Size())
+		}
+	}
+}
+
+func testPersistentModel(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+
+	// Random operations on random old versions, checked against slices.
+	versions := []*queue.Persistent[int]{{}}
+	models := [][]int{{}}
+
+	for i := 0; i < 5_000; i++ {
+		k := rnd.Intn(len(versions))
+		version, model := versions[k], models[k]
+
+		if rnd.Intn(3) > 0 || len(model) == 0 {
+			version = version.Offer(i)
+			model = append(append([]int{}, model...), i)
+		} else {
+			got, rest, err := version.Get()
+			if err != nil || got != model[0] {
+				t.Fatalf("get: expected %d, got %d (%v)", model[0], got, err)
+			}
+
+			version, model = rest, model[1:]
+		}
+
+		versions = append(versions, version)
+		models = append(models, model)
+	}
+
+	for k := range versions {
+		if got := versions[k].Elems(); !reflect.DeepEqual(got, models[k]) {
+			t.Fatalf("version %d: expected %v, got %v", k, models[k], got)
+		}
+	}
+}
+
+func testPersistentAll(t *testing.T) {
+	t.Parallel()
+
+	// The first elements are in the evaluated front, the last ones still
+	// in the rear.
+	persistent := queue.NewPersistent([]int{1, 2, 3, 4, 5, 6, 7})
+
+	for limit := 1; limit <= 7; limit++ {
+		var got []int
+
+		persistent.All()(func(elem int) bool {
+			got = append(got, elem)
+
+			return len(got) < limit
+		})
+
+		if len(got) != limit || got[limit-1] != limit {
+			t.Fatalf("limit %d: got %v", limit, got)
+		}
+	}
+}
+
+func testPersistentContains(t *testing.T) {
+	t.Parallel()
+
+	persistent := queue.NewPersistent([]int{1, 2, 3, 4, 5, 6, 7})
+
+	if !persistent.Contains(1) || !persistent.Contains(7) || persistent.Contains(8) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testPersistentMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewPersistent([]int{1, 2, 3}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2,3]" {
+		t.Fatalf("expected [1,2,3], got %s", data)
+	}
+}
+
+func testPersistentSharedVersionAcrossGoroutines(t *testing.T) {
+	t.Parallel()
+
+	elems := make([]int, 1_000)
+	for i := range elems {
+		elems[i] = i
+	}
+
+	shared := queue.NewPersistent(elems)
+
+	var wg sync.WaitGroup
+
+	wg.Add(8)
+
+	// Draining the same version concurrently forces its lazy cells from
+	// several goroutines at once.
+	for g := 0; g < 8; g++ {
+		go func() {
+			defer wg.Done()
+
+			version := shared
+
+			for want := range elems {
+				got, rest, err := version.Get()
+				if err != nil || got != want {
+					t.Errorf("expected %d, got %d (%v)", want, got, err)
+
+					return
+				}
+
+				version = rest.Offer(want)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
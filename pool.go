@@ -0,0 +1,202 @@
+package queue
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pool runs tasks on a fixed set of worker goroutines that schedule work
+// by stealing. Every worker owns a WorkStealing deque: tasks a worker
+// spawns while handling a task go to its own deque and are handled by it
+// in LIFO order, while idle workers steal the oldest tasks from the
+// deques of randomly chosen victims. Tasks submitted from outside the
+// pool go through a shared queue that every worker polls.
+//
+// Idle workers sleep until a task is submitted or spawned.
+type Pool[T any] struct {
+	deques []*WorkStealing[T]
+	handle func(task T, spawn func(T))
+
+	// injected holds the tasks submitted from outside the pool.
+	injectLock sync.Mutex
+	injected   []T
+
+	// queued is the number of tasks waiting in a deque or in injected;
+	// sleepers is the number of workers about to sleep or sleeping on
+	// idleCond.
+	queued   atomic.Int64
+	sleepers atomic.Int64
+	closed   atomic.Bool
+	idleLock sync.Mutex
+	idleCond *sync.Cond
+
+	// pending counts the tasks submitted or spawned and not yet handled;
+	// workers counts the running workers.
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+// NewPool starts a Pool of the given number of workers calling handle for
+// every task. handle may call spawn, only from the goroutine it runs on,
+// to schedule further tasks on the current worker.
+// Panics if workers is not positive.
+func NewPool[T any](workers int, handle func(task T, spawn func(T))) *Pool[T] {
+	if workers <= 0 {
+		panic("worker count must be positive")
+	}
+
+	p := &Pool[T]{
+		deques: make([]*WorkStealing[T], workers),
+		handle: handle,
+	}
+
+	p.idleCond = sync.NewCond(&p.idleLock)
+
+	for i := range p.deques {
+		p.deques[i] = NewWorkStealing[T]()
+	}
+
+	p.workers.Add(workers)
+
+	for i := range p.deques {
+		go p.work(i)
+	}
+
+	return p
+}
+
+// Submit schedules the task on the pool. It must not be called after
+// Close.
+func (p *Pool[T]) Submit(task T) {
+	p.pending.Add(1)
+
+	p.injectLock.Lock()
+	p.injected = append(p.injected, task)
+	p.injectLock.Unlock()
+
+	p.enqueued()
+}
+
+// Wait blocks until every submitted task, and every task they spawned,
+// has been handled. It must not be called concurrently with Submit.
+func (p *Pool[T]) Wait() {
+	p.pending.Wait()
+}
+
+// Close waits for every task to be handled, like Wait, and then stops the
+// workers.
+func (p *Pool[T]) Close() {
+	p.Wait()
+
+	p.closed.Store(true)
+
+	p.idleLock.Lock()
+	p.idleCond.Broadcast()
+	p.idleLock.Unlock()
+
+	p.workers.Wait()
+}
+
+// ===================================Helpers==================================
+
+// work is the loop of the worker owning p.deques[i].
+func (p *Pool[T]) work(i int) {
+	defer p.workers.Done()
+
+	own := p.deques[i]
+
+	spawn := func(task T) {
+		p.pending.Add(1)
+		own.Push(task)
+		p.enqueued()
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i))) //nolint:gosec // victim selection only
+
+	for {
+		task, ok := p.find(own, rnd)
+		if ok {
+			p.handle(task, spawn)
+			p.pending.Done()
+
+			continue
+		}
+
+		if !p.idle() {
+			return
+		}
+	}
+}
+
+// find takes a task from the worker's own deque, then from the submitted
+// tasks, then from the other deques starting at a random victim.
+func (p *Pool[T]) find(own *WorkStealing[T], rnd *rand.Rand) (task T, _ bool) {
+	task, err := own.Pop()
+
+	if err != nil {
+		task, err = p.takeInjected()
+	}
+
+	for start, i := rnd.Intn(len(p.deques)), 0; err != nil && i < len(p.deques); i++ {
+		task, err = p.deques[(start+i)%len(p.deques)].Steal()
+	}
+
+	if err != nil {
+		return task, false
+	}
+
+	p.queued.Add(-1)
+
+	return task, true
+}
+
+// takeInjected removes and returns the oldest submitted task.
+func (p *Pool[T]) takeInjected() (task T, _ error) {
+	p.injectLock.Lock()
+	defer p.injectLock.Unlock()
+
+	if len(p.injected) == 0 {
+		return task, ErrNoElementsAvailable
+	}
+
+	task = p.injected[0]
+
+	var zero T
+
+	p.injected[0] = zero
+	p.injected = p.injected[1:]
+
+	return task, nil
+}
+
+// enqueued accounts for a newly queued task and wakes a sleeping worker.
+func (p *Pool[T]) enqueued() {
+	p.queued.Add(1)
+
+	// A worker increments sleepers before checking queued, so either it
+	// sees the task or it is seen here.
+	if p.sleepers.Load() > 0 {
+		p.idleLock.Lock()
+		p.idleCond.Signal()
+		p.idleLock.Unlock()
+	}
+}
+
+// idle puts the worker to sleep until a task is queued, and reports
+// whether the worker should keep running.
+func (p *Pool[T]) idle() bool {
+	p.idleLock.Lock()
+	defer p.idleLock.Unlock()
+
+	p.sleepers.Add(1)
+
+	for p.queued.Load() <= 0 && !p.closed.Load() {
+		p.idleCond.Wait()
+	}
+
+	p.sleepers.Add(-1)
+
+	return !p.closed.Load()
+}
@@ -0,0 +1,137 @@
+package queue_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NonPositiveWorkers", testPoolNonPositiveWorkers)
+	t.Run("RunsSubmittedTasks", testPoolRunsSubmittedTasks)
+	t.Run("SpawnedTasks", testPoolSpawnedTasks)
+	t.Run("IdleWorkersSteal", testPoolIdleWorkersSteal)
+	t.Run("SubmitAfterIdle", testPoolSubmitAfterIdle)
+}
+
+func testPoolNonPositiveWorkers(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "worker count must be positive" {
+			t.Fatalf("expected panic 'worker count must be positive', got %v", p)
+		}
+	}()
+
+	_ = queue.NewPool(0, func(int, func(int)) {})
+}
+
+func testPoolRunsSubmittedTasks(t *testing.T) {
+	t.Parallel()
+
+	var sum atomic.Int64
+
+	pool := queue.NewPool(4, func(task int, _ func(int)) {
+		sum.Add(int64(task))
+	})
+
+	for i := 1; i <= 100; i++ {
+		pool.Submit(i)
+	}
+
+	pool.Close()
+
+	if got := sum.Load(); got != 5050 {
+		t.Fatalf("expected sum 5050, got %d", got)
+	}
+}
+
+func testPoolSpawnedTasks(t *testing.T) {
+	t.Parallel()
+
+	var leaves atomic.Int64
+
+	// Every task n > 1 splits into two tasks, so a task n produces n
+	// leaves.
+	pool := queue.NewPool(3, func(task int, spawn func(int)) {
+		if task == 1 {
+			leaves.Add(1)
+
+			return
+		}
+
+		spawn(task / 2)
+		spawn(task - task/2)
+	})
+
+	pool.Submit(1000)
+	pool.Wait()
+
+	if got := leaves.Load(); got != 1000 {
+		t.Fatalf("expected 1000 leaves, got %d", got)
+	}
+
+	pool.Close()
+}
+
+func testPoolIdleWorkersSteal(t *testing.T) {
+	t.Parallel()
+
+	stolen := make(chan struct{}, 1)
+
+	// The root task spawns children onto its own worker and blocks until
+	// one of them runs, which only another worker stealing it can do.
+	pool := queue.NewPool(2, func(task int, spawn func(int)) {
+		if task != 0 {
+			select {
+			case stolen <- struct{}{}:
+			default:
+			}
+
+			return
+		}
+
+		for i := 1; i <= 10; i++ {
+			spawn(i)
+		}
+
+		select {
+		case <-stolen:
+		case <-time.After(time.Second):
+			t.Error("no spawned task was stolen")
+		}
+	})
+
+	pool.Submit(0)
+	pool.Close()
+}
+
+func testPoolSubmitAfterIdle(t *testing.T) {
+	t.Parallel()
+
+	handled := make(chan int, 1)
+
+	pool := queue.NewPool(2, func(task int, _ func(int)) {
+		handled <- task
+	})
+
+	// Let the workers go to sleep before submitting.
+	time.Sleep(10 * time.Millisecond)
+
+	pool.Submit(7)
+
+	select {
+	case got := <-handled:
+		if got != 7 {
+			t.Fatalf("got %d want 7", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sleeping workers were not woken by Submit")
+	}
+
+	pool.Close()
+}
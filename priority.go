@@ -73,6 +73,9 @@ type Priority[T comparable] struct {
 	elements        *priorityHeap[T]
 
 	capacity *int
+	// members is set WithUnique, WithIndex or WithBloomFilter; see
+	// membership.
+	members *membership[T]
 
 	// synchronization
 	lock sync.RWMutex
@@ -102,6 +105,10 @@ func NewPriority[T comparable](
 		panic("negative capacity")
 	}
 
+	if options.unique {
+		elems = distinct(elems)
+	}
+
 	heapElems := make([]T, len(elems))
 
 	copy(heapElems, elems)
@@ -131,24 +138,33 @@ func NewPriority[T comparable](
 		initialElements: initialElems,
 		elements:        elementsHeap,
 		capacity:        options.capacity,
+		members:         newMembership[T](options),
 	}
 
+	pq.members.reset(initialElems)
+
 	return pq
 }
 
 // ==================================Insertion=================================
 
 // Offer inserts the element into the queue.
-// If the queue is full it returns the ErrQueueIsFull error.
+// If the queue is full it returns the ErrQueueIsFull error, and if it was
+// created WithUnique and already contains the element, ErrDuplicate.
 func (pq *Priority[T]) Offer(elem T) error {
 	pq.lock.Lock()
 	defer pq.lock.Unlock()
 
+	if !pq.members.admits(elem) {
+		return ErrDuplicate
+	}
+
 	if pq.capacity != nil && pq.elements.Len() >= *pq.capacity {
 		return ErrQueueIsFull
 	}
 
 	heap.Push(pq.elements, elem)
+	pq.members.add(elem)
 
 	return nil
 }
@@ -165,6 +181,7 @@ func (pq *Priority[T]) Reset() {
 	// copying it back preserves the heap invariant without a re-Init.
 	pq.elements.elems = make([]T, len(pq.initialElements))
 	copy(pq.elements.elems, pq.initialElements)
+	pq.members.reset(pq.initialElements)
 }
 
 // ===================================Removal==================================
@@ -182,7 +199,10 @@ func (pq *Priority[T]) Get() (elem T, _ error) {
 	// nolint: forcetypeassert, revive // since the heap package does not yet support
 	// generic types it has to use the `any` type. In this case, by design,
 	// type of the items available in the pq.elements collection is always T.
-	return heap.Pop(pq.elements).(T), nil
+	elem = heap.Pop(pq.elements).(T)
+	pq.members.remove(elem)
+
+	return elem, nil
 }
 
 // Clear removes all elements from the queue.
@@ -202,6 +222,8 @@ func (pq *Priority[T]) Clear() []T {
 		elems[i] = heap.Pop(pq.elements).(T)
 	}
 
+	pq.members.reset(nil)
+
 	return elems
 }
 
@@ -223,6 +245,8 @@ func (pq *Priority[T]) Iterator() <-chan T {
 		iteratorCh <- heap.Pop(pq.elements).(T)
 	}
 
+	pq.members.reset(nil)
+
 	close(iteratorCh)
 
 	return iteratorCh
@@ -239,10 +263,15 @@ func (pq *Priority[T]) IsEmpty() bool {
 }
 
 // Contains returns true if the queue contains the element, false otherwise.
+// See WithIndex and WithBloomFilter for how they speed it up.
 func (pq *Priority[T]) Contains(a T) bool {
 	pq.lock.RLock()
 	defer pq.lock.RUnlock()
 
+	if found, sure := pq.members.lookup(a); sure {
+		return found
+	}
+
 	for i := range pq.elements.elems {
 		if pq.elements.elems[i] == a {
 			return true
@@ -252,6 +281,43 @@ func (pq *Priority[T]) Contains(a T) bool {
 	return false
 }
 
+// MaybeContains reports whether the queue may contain the given element.
+// It never returns false for an element the queue contains; see
+// WithBloomFilter for when it returns true for one it does not.
+func (pq *Priority[T]) MaybeContains(elem T) bool {
+	if pq.members == nil {
+		return pq.Contains(elem)
+	}
+
+	pq.lock.RLock()
+	defer pq.lock.RUnlock()
+
+	found, _ := pq.members.lookup(elem)
+
+	return found
+}
+
+// Count returns the number of times the element is in the queue.
+// See WithIndex and WithBloomFilter for how they speed it up.
+func (pq *Priority[T]) Count(a T) int {
+	pq.lock.RLock()
+	defer pq.lock.RUnlock()
+
+	if n, sure := pq.members.count(a); sure {
+		return n
+	}
+
+	n := 0
+
+	for i := range pq.elements.elems {
+		if pq.elements.elems[i] == a {
+			n++
+		}
+	}
+
+	return n
+}
+
 // Peek retrieves but does not return the head of the queue.
 func (pq *Priority[T]) Peek() (elem T, _ error) {
 	pq.lock.RLock()
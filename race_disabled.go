@@ -0,0 +1,8 @@
+//go:build !race
+
+package queue
+
+// raceEnabled reports whether the package is built with the race
+// detector, which turns on the misuse checks of the single-producer and
+// single-consumer queues.
+const raceEnabled = false
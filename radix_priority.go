@@ -0,0 +1,352 @@
+package queue
+
+import (
+	"encoding/json"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// radixBuckets is the number of buckets of a radix heap over uint64 keys:
+// one for keys equal to the last extracted key, plus one per bit position.
+const radixBuckets = 65
+
+// keyed pairs an element with its cached key.
+type keyed[T any] struct {
+	elem T
+	key  uint64
+}
+
+// Ensure RadixPriority implements the Queue interface.
+var _ Queue[any] = (*RadixPriority[any])(nil)
+
+// RadixPriority is a monotone priority Queue implementation backed by a
+// radix heap. Elements are ordered by an uint64 key computed at Offer time;
+// the head of the queue is always the element with the smallest key.
+//
+// The queue is monotone: the key of an offered element must not be smaller
+// than the key of the last element removed with Get. Offers violating this
+// invariant are rejected with ErrMonotoneViolation. This matches workloads
+// such as Dijkstra's shortest path or discrete-event simulation, where the
+// extracted keys never decrease, and in exchange Offer is O(1) and Get is
+// amortized O(log C), where C is the spread between the largest and smallest
+// key in the queue.
+//
+// ! If capacity is provided and is less than the number of elements provided,
+// the elements with the smallest keys are kept.
+type RadixPriority[T comparable] struct {
+	initialElems []keyed[T]
+	keyFunc      func(T) uint64
+	buckets      [radixBuckets][]keyed[T]
+	last         uint64
+	size         int
+	capacity     *int
+
+	// synchronization
+	lock sync.RWMutex
+}
+
+// NewRadixPriority creates a new RadixPriority queue containing the given
+// elements. keyFunc is called once per element, at construction and at
+// Offer time, and its result is cached.
+// It panics if keyFunc is nil or WithCapacity is negative.
+func NewRadixPriority[T comparable](
+	elems []T,
+	keyFunc func(T) uint64,
+	opts ...Option,
+) *RadixPriority[T] {
+	if keyFunc == nil {
+		panic("nil key func")
+	}
+
+	options := options{
+		capacity: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	initialElems := make([]keyed[T], len(elems))
+	for i, e := range elems {
+		initialElems[i] = keyed[T]{elem: e, key: keyFunc(e)}
+	}
+
+	// Keep the smallest keys when the initial elements exceed capacity,
+	// mirroring NewPriority.
+	if options.capacity != nil && *options.capacity < len(initialElems) {
+		sort.SliceStable(initialElems, func(i, j int) bool {
+			return initialElems[i].key < initialElems[j].key
+		})
+
+		initialElems = initialElems[:*options.capacity]
+	}
+
+	rq := &RadixPriority[T]{
+		initialElems: initialElems,
+		keyFunc:      keyFunc,
+		capacity:     options.capacity,
+	}
+
+	rq.reset()
+
+	return rq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element into the queue.
+// If the element's key is smaller than the key of the last element
+// removed it returns the ErrMonotoneViolation error.
+// If the queue is full it returns the ErrQueueIsFull error.
+func (rq *RadixPriority[T]) Offer(elem T) error {
+	key := rq.keyFunc(elem)
+
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if key < rq.last {
+		return ErrMonotoneViolation
+	}
+
+	if rq.capacity != nil && rq.size >= *rq.capacity {
+		return ErrQueueIsFull
+	}
+
+	rq.push(keyed[T]{elem: elem, key: key})
+
+	return nil
+}
+
+// Reset sets the queue to its initial state, by replacing the current
+// elements with the elements provided at creation. The monotone floor is
+// reset as well, so keys smaller than the last removed key are accepted
+// again.
+func (rq *RadixPriority[T]) Reset() {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	rq.reset()
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the element with the smallest key.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (rq *RadixPriority[T]) Get() (elem T, _ error) {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if rq.size == 0 {
+		return elem, ErrNoElementsAvailable
+	}
+
+	return rq.pop().elem, nil
+}
+
+// Clear removes and returns all elements from the queue in key order.
+func (rq *RadixPriority[T]) Clear() []T {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	elems := make([]T, 0, rq.size)
+
+	for rq.size > 0 {
+		elems = append(elems, rq.pop().elem)
+	}
+
+	return elems
+}
+
+// Iterator returns an iterator over the elements in the queue, in key order.
+// It removes the elements from the queue.
+func (rq *RadixPriority[T]) Iterator() <-chan T {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	// use a buffered channel to avoid blocking the iterator.
+	iteratorCh := make(chan T, rq.size)
+
+	for rq.size > 0 {
+		iteratorCh <- rq.pop().elem
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the element with the smallest key.
+// If no element is available it returns an ErrNoElementsAvailable error.
+//
+// Unlike Get, Peek does not redistribute buckets, so it does not move the
+// monotone floor; it scans the lowest non-empty bucket instead.
+func (rq *RadixPriority[T]) Peek() (elem T, _ error) {
+	rq.lock.RLock()
+	defer rq.lock.RUnlock()
+
+	if rq.size == 0 {
+		return elem, ErrNoElementsAvailable
+	}
+
+	bucket := rq.buckets[rq.lowestBucket()]
+
+	return bucket[minKeyIndex(bucket)].elem, nil
+}
+
+// Size returns the number of elements in the queue.
+func (rq *RadixPriority[T]) Size() int {
+	rq.lock.RLock()
+	defer rq.lock.RUnlock()
+
+	return rq.size
+}
+
+// IsEmpty returns true if the queue is empty, false otherwise.
+func (rq *RadixPriority[T]) IsEmpty() bool {
+	return rq.Size() == 0
+}
+
+// Contains returns true if the queue contains the element, false otherwise.
+func (rq *RadixPriority[T]) Contains(elem T) bool {
+	rq.lock.RLock()
+	defer rq.lock.RUnlock()
+
+	for i := range rq.buckets {
+		for j := range rq.buckets[i] {
+			if rq.buckets[i][j].elem == elem {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the RadixPriority queue to JSON in key order.
+func (rq *RadixPriority[T]) MarshalJSON() ([]byte, error) {
+	rq.lock.RLock()
+
+	snapshot := make([]keyed[T], 0, rq.size)
+	for i := range rq.buckets {
+		snapshot = append(snapshot, rq.buckets[i]...)
+	}
+
+	rq.lock.RUnlock()
+
+	sort.SliceStable(snapshot, func(i, j int) bool {
+		return snapshot[i].key < snapshot[j].key
+	})
+
+	output := make([]T, len(snapshot))
+	for i := range snapshot {
+		output[i] = snapshot[i].elem
+	}
+
+	return json.Marshal(output)
+}
+
+// ===================================Helpers==================================
+
+// reset restores the initial elements and the monotone floor.
+// Caller must hold the write lock.
+func (rq *RadixPriority[T]) reset() {
+	// Fresh bucket slices so references to removed elements are released
+	// with the old backing arrays.
+	rq.buckets = [radixBuckets][]keyed[T]{}
+	rq.last = 0
+	rq.size = 0
+
+	for _, e := range rq.initialElems {
+		rq.push(e)
+	}
+}
+
+// bucketFor returns the bucket index for key relative to the last
+// extracted key: the position of the highest bit in which they differ.
+func (rq *RadixPriority[T]) bucketFor(key uint64) int {
+	return bits.Len64(key ^ rq.last)
+}
+
+// push inserts e into its bucket. e.key must not be smaller than rq.last.
+func (rq *RadixPriority[T]) push(e keyed[T]) {
+	b := rq.bucketFor(e.key)
+	rq.buckets[b] = append(rq.buckets[b], e)
+	rq.size++
+}
+
+// pop removes and returns the element with the smallest key.
+// The queue must be non-empty.
+func (rq *RadixPriority[T]) pop() keyed[T] {
+	if len(rq.buckets[0]) == 0 {
+		rq.redistribute()
+	}
+
+	n := len(rq.buckets[0]) - 1
+	e := rq.buckets[0][n]
+
+	var zero keyed[T]
+
+	rq.buckets[0][n] = zero
+	rq.buckets[0] = rq.buckets[0][:n]
+	rq.size--
+
+	return e
+}
+
+// redistribute advances the monotone floor to the smallest key held in the
+// lowest non-empty bucket and spreads that bucket's elements over the
+// lower buckets. Every element moves to a strictly lower bucket, which is
+// what bounds the amortized cost of Get.
+func (rq *RadixPriority[T]) redistribute() {
+	b := rq.lowestBucket()
+	bucket := rq.buckets[b]
+
+	rq.last = bucket[minKeyIndex(bucket)].key
+	rq.size -= len(bucket)
+
+	// Every element lands in a lower bucket, so none of them is appended
+	// back onto this slice while it is being walked.
+	var zero keyed[T]
+
+	for i := range bucket {
+		rq.push(bucket[i])
+		bucket[i] = zero
+	}
+
+	// Keep the backing array for reuse; the slots were zeroed above so it
+	// no longer references the moved elements.
+	rq.buckets[b] = bucket[:0]
+}
+
+// lowestBucket returns the index of the lowest non-empty bucket.
+// The queue must be non-empty.
+func (rq *RadixPriority[T]) lowestBucket() int {
+	b := 0
+	for len(rq.buckets[b]) == 0 {
+		b++
+	}
+
+	return b
+}
+
+// minKeyIndex returns the index of the last element with the smallest key
+// in a non-empty bucket. Taking the last one keeps Peek consistent with
+// pop, which takes from the end of bucket 0 after redistribution.
+func minKeyIndex[T any](bucket []keyed[T]) int {
+	minIdx := 0
+
+	for i := 1; i < len(bucket); i++ {
+		if bucket[i].key <= bucket[minIdx].key {
+			minIdx = i
+		}
+	}
+
+	return minIdx
+}
@@ -0,0 +1,510 @@
+package queue_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func identityKey(elem int) uint64 {
+	return uint64(elem)
+}
+
+func TestRadixPriority(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NilKeyFunc", testRadixPriorityNilKeyFunc)
+	t.Run("NegativeCapacity", testRadixPriorityNegativeCapacity)
+	t.Run("CapacityLesserThanLenElems", testRadixPriorityCapacityLesserThanLenElems)
+	t.Run("Get", testRadixPriorityGet)
+	t.Run("Offer", testRadixPriorityOffer)
+	t.Run("Peek", testRadixPriorityPeek)
+	t.Run("Contains", testRadixPriorityContains)
+	t.Run("Clear", testRadixPriorityClear)
+	t.Run("Iterator", testRadixPriorityIterator)
+	t.Run("Reset", testRadixPriorityReset)
+	t.Run("MarshalJSON", testRadixPriorityMarshalJSON)
+	t.Run("MatchesSortedOrder", testRadixPriorityMatchesSortedOrder)
+}
+
+func testRadixPriorityNilKeyFunc(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "nil key func" {
+			t.Fatalf("expected panic 'nil key func', got %v", p)
+		}
+	}()
+
+	queue.NewRadixPriority[int](nil, nil)
+}
+
+func testRadixPriorityNegativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != negativeCapacityPanic {
+			t.Fatalf("expected panic %q, got %v", negativeCapacityPanic, p)
+		}
+	}()
+
+	queue.NewRadixPriority[int](nil, identityKey, queue.WithCapacity(-1))
+}
+
+func testRadixPriorityCapacityLesserThanLenElems(t *testing.T) {
+	t.Parallel()
+
+	radixQueue := queue.NewRadixPriority(
+		[]int{5, 3, 9, 1},
+		identityKey,
+		queue.WithCapacity(2),
+	)
+
+	expected := []int{1, 3}
+
+	if got := radixQueue.Clear(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+}
+
+func testRadixPriorityGet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority([]int{4, 1, 2}, identityKey)
+
+		elem, err := radixQueue.Get()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if elem != 1 {
+			t.Fatalf("expected elem to be 1, got %d", elem)
+		}
+
+		if radixQueue.Size() != 2 {
+			t.Fatalf("expected size to be 2, got %d", radixQueue.Size())
+		}
+	})
+
+	t.Run("ErrNoElementsAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority[int](nil, identityKey)
+
+		if _, err := radixQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected error to be %v, got %v", queue.ErrNoElementsAvailable, err)
+		}
+	})
+}
+
+func testRadixPriorityOffer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority([]int{10}, identityKey)
+
+		if _, err := radixQueue.Get(); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		// Equal to the last extracted key is allowed.
+		if err := radixQueue.Offer(10); err != nil {
+			t.Fatalf("offer: %v", err)
+		}
+
+		if err := radixQueue.Offer(11); err != nil {
+			t.Fatalf("offer: %v", err)
+		}
+
+		if radixQueue.Size() != 2 {
+			t.Fatalf("expected size to be 2, got %d", radixQueue.Size())
+		}
+	})
+
+	t.Run("ErrMonotoneViolation", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority([]int{10}, identityKey)
+
+		if _, err := radixQueue.Get(); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if err := radixQueue.Offer(9); !errors.Is(err, queue.ErrMonotoneViolation) {
+			t.Fatalf("expected error to be %v, got %v", queue.ErrMonotoneViolation, err)
+		}
+	})
+
+	t.Run("ErrQueueIsFull", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority(
+			[]int{1},
+			identityKey,
+			queue.WithCapacity(1),
+		)
+
+		if err := radixQueue.Offer(2); !errors.Is(err, queue.ErrQueueIsFull) {
+			t.Fatalf("expected error to be %v, got %v", queue.ErrQueueIsFull, err)
+		}
+	})
+}
+
+func testRadixPriorityPeek(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority([]int{7, 3, 5}, identityKey)
+
+		elem, err := radixQueue.Peek()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if elem != 3 {
+			t.Fatalf("expected elem to be 3, got %d", elem)
+		}
+
+		// Peek must not move the monotone floor.
+		if err := radixQueue.Offer(1); err != nil {
+			t.Fatalf("offer after peek: %v", err)
+		}
+	})
+
+	t.Run("MatchesGetOnEqualKeys", func(t *testing.T) {
+		t.Parallel()
+
+		type item struct{ id, key int }
+
+		radixQueue := queue.NewRadixPriority(
+			[]item{{id: 1, key: 4}, {id: 2, key: 4}, {id: 3, key: 8}},
+			func(i item) uint64 { return uint64(i.key) },
+		)
+
+		for radixQueue.Size() > 0 {
+			peeked, err := radixQueue.Peek()
+			if err != nil {
+				t.Fatalf("peek: %v", err)
+			}
+
+			got, err := radixQueue.Get()
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+
+			if peeked != got {
+				t.Fatalf("peek returned %v but get returned %v", peeked, got)
+			}
+		}
+	})
+
+	t.Run("ErrNoElementsAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority[int](nil, identityKey)
+
+		if _, err := radixQueue.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected error to be %v, got %v", queue.ErrNoElementsAvailable, err)
+		}
+	})
+}
+
+func testRadixPriorityContains(t *testing.T) {
+	t.Parallel()
+
+	radixQueue := queue.NewRadixPriority([]int{1, 2, 300}, identityKey)
+
+	if !radixQueue.Contains(300) {
+		t.Fatal("expected queue to contain 300")
+	}
+
+	if radixQueue.Contains(4) {
+		t.Fatal("expected queue to not contain 4")
+	}
+}
+
+func testRadixPriorityClear(t *testing.T) {
+	t.Parallel()
+
+	radixQueue := queue.NewRadixPriority([]int{3, 1, 2}, identityKey)
+
+	expected := []int{1, 2, 3}
+
+	if got := radixQueue.Clear(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+
+	if !radixQueue.IsEmpty() {
+		t.Fatal("expected queue to be empty")
+	}
+}
+
+func testRadixPriorityIterator(t *testing.T) {
+	t.Parallel()
+
+	radixQueue := queue.NewRadixPriority([]int{3, 1, 2}, identityKey)
+
+	iterElems := make([]int, 0, 3)
+
+	for e := range radixQueue.Iterator() {
+		iterElems = append(iterElems, e)
+	}
+
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(expected, iterElems) {
+		t.Fatalf("expected %v got %v", expected, iterElems)
+	}
+
+	if !radixQueue.IsEmpty() {
+		t.Fatal("expected queue to be empty")
+	}
+}
+
+func testRadixPriorityReset(t *testing.T) {
+	t.Parallel()
+
+	radixQueue := queue.NewRadixPriority([]int{8, 4}, identityKey)
+
+	for !radixQueue.IsEmpty() {
+		if _, err := radixQueue.Get(); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+	}
+
+	radixQueue.Reset()
+
+	// The monotone floor is reset together with the elements.
+	if err := radixQueue.Offer(1); err != nil {
+		t.Fatalf("offer after reset: %v", err)
+	}
+
+	expected := []int{1, 4, 8}
+
+	if got := radixQueue.Clear(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+}
+
+func testRadixPriorityMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("HasElements", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority([]int{3, 1, 2}, identityKey)
+
+		marshaled, err := json.Marshal(radixQueue)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := []byte(`[1,2,3]`)
+		if !bytes.Equal(expected, marshaled) {
+			t.Fatalf("expected %s, got %s", expected, marshaled)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		radixQueue := queue.NewRadixPriority[int](nil, identityKey)
+
+		marshaled, err := json.Marshal(radixQueue)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := []byte(`[]`)
+		if !bytes.Equal(expected, marshaled) {
+			t.Fatalf("expected %s, got %s", expected, marshaled)
+		}
+	})
+}
+
+// testRadixPriorityMatchesSortedOrder drives a monotone workload with
+// interleaved offers and gets and checks that every extraction returns
+// the smallest remaining key.
+func testRadixPriorityMatchesSortedOrder(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+
+	radixQueue := queue.NewRadixPriority[int](nil, identityKey)
+	reference := queue.NewPriority[int](nil, lessInt)
+
+	last := 0
+
+	for i := 0; i < 5000; i++ {
+		if rng.Intn(3) > 0 || radixQueue.IsEmpty() {
+			elem := last + rng.Intn(1<<rng.Intn(20))
+
+			if err := radixQueue.Offer(elem); err != nil {
+				t.Fatalf("offer %d: %v", elem, err)
+			}
+
+			_ = reference.Offer(elem)
+
+			continue
+		}
+
+		got, err := radixQueue.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		want, _ := reference.Get()
+		if got != want {
+			t.Fatalf("step %d: got %d want %d", i, got, want)
+		}
+
+		last = got
+	}
+}
+
+// gridEdge is an edge of the benchmark grid graph.
+type gridEdge struct {
+	to     int
+	weight int
+}
+
+// gridVisit is a tentative distance for a node of the benchmark grid graph.
+type gridVisit struct {
+	node int
+	dist uint64
+}
+
+// newGridGraph builds a side x side 4-connected grid with random weights.
+func newGridGraph(side int) [][]gridEdge {
+	rng := rand.New(rand.NewSource(42))
+
+	graph := make([][]gridEdge, side*side)
+
+	for row := 0; row < side; row++ {
+		for col := 0; col < side; col++ {
+			n := row*side + col
+
+			if col+1 < side {
+				w := 1 + rng.Intn(100)
+				graph[n] = append(graph[n], gridEdge{to: n + 1, weight: w})
+				graph[n+1] = append(graph[n+1], gridEdge{to: n, weight: w})
+			}
+
+			if row+1 < side {
+				w := 1 + rng.Intn(100)
+				graph[n] = append(graph[n], gridEdge{to: n + side, weight: w})
+				graph[n+side] = append(graph[n+side], gridEdge{to: n, weight: w})
+			}
+		}
+	}
+
+	return graph
+}
+
+// dijkstra runs a lazy-deletion Dijkstra from node 0 over graph using q and
+// returns the distance to the last node.
+func dijkstra(graph [][]gridEdge, q queue.Queue[gridVisit]) uint64 {
+	dist := make([]uint64, len(graph))
+	for i := range dist {
+		dist[i] = ^uint64(0)
+	}
+
+	dist[0] = 0
+	_ = q.Offer(gridVisit{node: 0, dist: 0})
+
+	for {
+		v, err := q.Get()
+		if err != nil {
+			return dist[len(graph)-1]
+		}
+
+		if v.dist > dist[v.node] {
+			continue
+		}
+
+		for _, e := range graph[v.node] {
+			if d := v.dist + uint64(e.weight); d < dist[e.to] {
+				dist[e.to] = d
+				_ = q.Offer(gridVisit{node: e.to, dist: d})
+			}
+		}
+	}
+}
+
+func BenchmarkRadixPriorityDijkstra(b *testing.B) {
+	graph := newGridGraph(200)
+
+	b.Run("RadixPriority", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			radixQueue := queue.NewRadixPriority[gridVisit](
+				nil,
+				func(v gridVisit) uint64 { return v.dist },
+			)
+
+			_ = dijkstra(graph, radixQueue)
+		}
+	})
+
+	b.Run("Priority", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			priorityQueue := queue.NewPriority[gridVisit](
+				nil,
+				func(elem, otherElem gridVisit) bool { return elem.dist < otherElem.dist },
+			)
+
+			_ = dijkstra(graph, priorityQueue)
+		}
+	})
+}
+
+func BenchmarkRadixPriorityQueue(b *testing.B) {
+	b.Run("Peek", func(b *testing.B) {
+		radixQueue := queue.NewRadixPriority([]int{1}, identityKey)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i <= b.N; i++ {
+			_, _ = radixQueue.Peek()
+		}
+	})
+
+	b.Run("Get_Offer", func(b *testing.B) {
+		radixQueue := queue.NewRadixPriority([]int{1}, identityKey)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i <= b.N; i++ {
+			_, _ = radixQueue.Get()
+
+			_ = radixQueue.Offer(1)
+		}
+	})
+
+	b.Run("Offer", func(b *testing.B) {
+		radixQueue := queue.NewRadixPriority[int](nil, identityKey)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i <= b.N; i++ {
+			_ = radixQueue.Offer(i)
+		}
+	})
+}
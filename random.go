@@ -0,0 +1,231 @@
+package queue
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sync"
+)
+
+// Ensure Random implements the Queue interface.
+var _ Queue[any] = (*Random[any])(nil)
+
+// Random is a Queue implementation whose Get removes an element chosen
+// uniformly at random, e.g. to spread load over workers or to sample
+// elements fairly. Offer and Get run in O(1): the removed element is
+// replaced by the last one.
+//
+// Peek returns the element the next Get removes. Clear and Iterator
+// return the elements in the order successive Get calls would have.
+//
+// Random numbers come from the global source of math/rand unless
+// WithRand is given.
+type Random[T comparable] struct {
+	initialElems []T
+	elems        []T
+	capacity     *int
+	rand         *rand.Rand
+	// next is the index of the element the next Get removes, chosen by
+	// Peek, or -1 if none was chosen yet.
+	next int
+
+	lock sync.Mutex
+}
+
+// NewRandom returns a new Random queue containing the given elements.
+// Panics if WithCapacity is negative.
+func NewRandom[T comparable](elems []T, opts ...Option) *Random[T] {
+	options := options{
+		capacity: nil,
+		rand:     nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	if options.capacity != nil && len(elems) > *options.capacity {
+		elems = elems[:*options.capacity]
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	rq := &Random[T]{
+		initialElems: initialElems,
+		capacity:     options.capacity,
+		rand:         options.rand,
+	}
+
+	rq.reset()
+
+	return rq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element into the queue.
+// If the queue is full it returns the ErrQueueIsFull error.
+func (rq *Random[T]) Offer(elem T) error {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if rq.capacity != nil && len(rq.elems) >= *rq.capacity {
+		return ErrQueueIsFull
+	}
+
+	rq.elems = append(rq.elems, elem)
+
+	return nil
+}
+
+// Reset sets the queue to its initial state with the original elements.
+func (rq *Random[T]) Reset() {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	rq.reset()
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns an element chosen uniformly at random.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (rq *Random[T]) Get() (v T, _ error) {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if len(rq.elems) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return rq.get(), nil
+}
+
+// Clear removes and returns all elements from the queue, in random order.
+func (rq *Random[T]) Clear() []T {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	elems := make([]T, 0, len(rq.elems))
+
+	for len(rq.elems) > 0 {
+		elems = append(elems, rq.get())
+	}
+
+	return elems
+}
+
+// Iterator returns an iterator over the elements in this queue, in random
+// order.
+// It removes the elements from the queue.
+func (rq *Random[T]) Iterator() <-chan T {
+	elems := rq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the element the next Get removes.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (rq *Random[T]) Peek() (v T, _ error) {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if len(rq.elems) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return rq.elems[rq.pick()], nil
+}
+
+// Size returns the number of elements in the queue.
+func (rq *Random[T]) Size() int {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	return len(rq.elems)
+}
+
+// IsEmpty returns true if the queue is empty.
+func (rq *Random[T]) IsEmpty() bool {
+	return rq.Size() == 0
+}
+
+// Contains returns true if the queue contains the given element.
+func (rq *Random[T]) Contains(elem T) bool {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	for _, e := range rq.elems {
+		if e == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the Random queue to JSON, in no particular
+// order.
+func (rq *Random[T]) MarshalJSON() ([]byte, error) {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	return json.Marshal(rq.elems)
+}
+
+// ===================================Helpers==================================
+
+// pick chooses the element the next get removes, unless Peek already did,
+// and returns its index. The queue must be non-empty.
+func (rq *Random[T]) pick() int {
+	if rq.next < 0 {
+		if rq.rand != nil {
+			rq.next = rq.rand.Intn(len(rq.elems))
+		} else {
+			rq.next = rand.Intn(len(rq.elems)) //nolint:gosec // not security sensitive
+		}
+	}
+
+	return rq.next
+}
+
+// get removes and returns a random element by moving the last element
+// into its slot. The queue must be non-empty.
+func (rq *Random[T]) get() T {
+	i, last := rq.pick(), len(rq.elems)-1
+
+	elem := rq.elems[i]
+	rq.elems[i] = rq.elems[last]
+
+	// Zero the vacated slot so the backing array no longer references
+	// the removed element.
+	var zero T
+
+	rq.elems[last] = zero
+	rq.elems = rq.elems[:last]
+	rq.next = -1
+
+	return elem
+}
+
+// reset restores the initial elements. Caller must hold the lock.
+func (rq *Random[T]) reset() {
+	rq.elems = make([]T, len(rq.initialElems))
+	copy(rq.elems, rq.initialElems)
+
+	rq.next = -1
+}
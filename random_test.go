@@ -0,0 +1,250 @@
+package queue_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestRandom(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NegativeCapacity", testRandomNegativeCapacity)
+	t.Run("Capacity", testRandomCapacity)
+	t.Run("Empty", testRandomEmpty)
+	t.Run("Deterministic", testRandomDeterministic)
+	t.Run("PeekThenGet", testRandomPeekThenGet)
+	t.Run("Uniform", testRandomUniform)
+	t.Run("SizeAndContains", testRandomSizeAndContains)
+	t.Run("ClearAndIterator", testRandomClearAndIterator)
+	t.Run("Reset", testRandomReset)
+	t.Run("MarshalJSON", testRandomMarshalJSON)
+}
+
+func testRandomNegativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != negativeCapacityPanic {
+			t.Fatalf("expected panic %q, got %v", negativeCapacityPanic, p)
+		}
+	}()
+
+	_ = queue.NewRandom[int](nil, queue.WithCapacity(-1))
+}
+
+func testRandomCapacity(t *testing.T) {
+	t.Parallel()
+
+	random := queue.NewRandom([]int{1, 2, 3}, queue.WithCapacity(2))
+
+	if err := random.Offer(4); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+
+	_, _ = random.Get()
+
+	if err := random.Offer(4); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if random.Size() != 2 || random.Contains(3) {
+		t.Fatalf("expected the trailing initial element to be dropped, size %d", random.Size())
+	}
+}
+
+func testRandomEmpty(t *testing.T) {
+	t.Parallel()
+
+	random := queue.NewRandom[int](nil)
+
+	if _, err := random.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := random.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !random.IsEmpty() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testRandomDeterministic(t *testing.T) {
+	t.Parallel()
+
+	elems := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	drain := func() []int {
+		random := queue.NewRandom(elems, queue.WithRand(rand.New(rand.NewSource(42))))
+
+		var got []int
+
+		for !random.IsEmpty() {
+			elem, err := random.Get()
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+
+			got = append(got, elem)
+		}
+
+		return got
+	}
+
+	first, second := drain(), drain()
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("same seed, different orders: %v and %v", first, second)
+	}
+
+	sort.Ints(first)
+
+	if !reflect.DeepEqual(first, elems) {
+		t.Fatalf("expected a permutation of %v, got %v", elems, first)
+	}
+}
+
+func testRandomPeekThenGet(t *testing.T) {
+	t.Parallel()
+
+	random := queue.NewRandom([]int{1, 2, 3, 4, 5})
+
+	for !random.IsEmpty() {
+		peeked, err := random.Peek()
+		if err != nil {
+			t.Fatalf("peek: %v", err)
+		}
+
+		if again, _ := random.Peek(); again != peeked {
+			t.Fatalf("peek: got %d then %d", peeked, again)
+		}
+
+		// Offers do not change the element the next Get removes.
+		_ = random.Offer(0)
+
+		if got, _ := random.Get(); got != peeked {
+			t.Fatalf("get: got %d, peeked %d", got, peeked)
+		}
+
+		_ = random.Clear()
+	}
+}
+
+func testRandomUniform(t *testing.T) {
+	t.Parallel()
+
+	const (
+		elems  = 4
+		trials = 10000
+	)
+
+	r := rand.New(rand.NewSource(1))
+	counts := make([]int, elems)
+
+	for i := 0; i < trials; i++ {
+		random := queue.NewRandom([]int{0, 1, 2, 3}, queue.WithRand(r))
+
+		elem, _ := random.Get()
+		counts[elem]++
+	}
+
+	for elem, count := range counts {
+		if count < trials/elems*9/10 || count > trials/elems*11/10 {
+			t.Fatalf("element %d was removed first %d times out of %d", elem, count, trials)
+		}
+	}
+}
+
+func testRandomSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	random := queue.NewRandom([]int{1, 2})
+
+	if !random.Contains(1) || random.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+
+	if random.Size() != 2 || random.IsEmpty() {
+		t.Fatalf("expected size 2, got %d", random.Size())
+	}
+}
+
+func testRandomClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	random := queue.NewRandom([]int{1, 2, 3}, queue.WithRand(rand.New(rand.NewSource(7))))
+
+	peeked, _ := random.Peek()
+
+	cleared := random.Clear()
+
+	if cleared[0] != peeked {
+		t.Fatalf("expected clear to start with the peeked %d, got %v", peeked, cleared)
+	}
+
+	sort.Ints(cleared)
+
+	if !reflect.DeepEqual(cleared, []int{1, 2, 3}) || !random.IsEmpty() {
+		t.Fatalf("expected [1 2 3] and an empty queue, got %v", cleared)
+	}
+
+	random.Reset()
+
+	var got []int
+
+	for e := range random.Iterator() {
+		got = append(got, e)
+	}
+
+	sort.Ints(got)
+
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) || !random.IsEmpty() {
+		t.Fatalf("expected [1 2 3] and an empty queue, got %v", got)
+	}
+}
+
+func testRandomReset(t *testing.T) {
+	t.Parallel()
+
+	random := queue.NewRandom([]int{1, 2})
+
+	_ = random.Offer(3)
+	_, _ = random.Get()
+
+	random.Reset()
+
+	got := random.Clear()
+	sort.Ints(got)
+
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testRandomMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewRandom([]int{1, 2, 3}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2,3]" {
+		t.Fatalf("expected [1,2,3], got %s", data)
+	}
+
+	data, err = queue.NewRandom[int](nil).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[]" {
+		t.Fatalf("expected [], got %s", data)
+	}
+}
@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+// Ensure Ref implements the Queue interface.
+var _ Queue[any] = (*Ref[any])(nil)
+
+// Ref is a Queue implementation holding the current version of a
+// Persistent queue. Every change builds a new version and installs it
+// with an atomic compare-and-swap, retrying if another goroutine changed
+// the queue in the meantime, so no method ever takes a lock.
+//
+// Snapshot returns the current version in O(1): an immutable,
+// point-in-time view of the queue that later changes do not affect.
+// Peek, Size, IsEmpty, Contains and MarshalJSON each work on such a
+// snapshot, so they are consistent even while other goroutines change
+// the queue.
+type Ref[T comparable] struct {
+	current  atomic.Pointer[Persistent[T]]
+	initial  *Persistent[T]
+	capacity *int
+}
+
+// NewRef returns a new Ref queue containing the given elements.
+// Panics if WithCapacity is negative.
+func NewRef[T comparable](elems []T, opts ...Option) *Ref[T] {
+	options := options{
+		capacity: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	if options.capacity != nil && len(elems) > *options.capacity {
+		elems = elems[:*options.capacity]
+	}
+
+	rq := &Ref[T]{
+		initial:  NewPersistent(elems),
+		capacity: options.capacity,
+	}
+
+	rq.current.Store(rq.initial)
+
+	return rq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element to the tail of the queue.
+// If the queue is full it returns the ErrQueueIsFull error.
+func (rq *Ref[T]) Offer(elem T) error {
+	for {
+		pq := rq.current.Load()
+
+		if rq.capacity != nil && pq.Size() >= *rq.capacity {
+			return ErrQueueIsFull
+		}
+
+		if rq.current.CompareAndSwap(pq, pq.Offer(elem)) {
+			return nil
+		}
+	}
+}
+
+// Reset sets the queue to its initial state with the original elements.
+// It runs in O(1), as the original version is immutable.
+func (rq *Ref[T]) Reset() {
+	rq.current.Store(rq.initial)
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (rq *Ref[T]) Get() (v T, _ error) {
+	for {
+		pq := rq.current.Load()
+
+		elem, rest, err := pq.Get()
+		if err != nil {
+			return v, err
+		}
+
+		if rq.current.CompareAndSwap(pq, rest) {
+			return elem, nil
+		}
+	}
+}
+
+// Clear removes and returns all elements from the queue.
+func (rq *Ref[T]) Clear() []T {
+	return rq.current.Swap(&Persistent[T]{}).Elems()
+}
+
+// Iterator returns an iterator over the elements in this queue.
+// It removes the elements from the queue.
+func (rq *Ref[T]) Iterator() <-chan T {
+	elems := rq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Snapshot returns the current version of the queue, an immutable view
+// that later changes to the Ref do not affect. It runs in O(1).
+func (rq *Ref[T]) Snapshot() *Persistent[T] {
+	return rq.current.Load()
+}
+
+// Peek retrieves but does not remove the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (rq *Ref[T]) Peek() (T, error) {
+	return rq.Snapshot().Peek()
+}
+
+// Size returns the number of elements in the queue.
+func (rq *Ref[T]) Size() int {
+	return rq.Snapshot().Size()
+}
+
+// IsEmpty returns true if the queue is empty.
+func (rq *Ref[T]) IsEmpty() bool {
+	return rq.Snapshot().IsEmpty()
+}
+
+// Contains returns true if the queue contains the given element.
+func (rq *Ref[T]) Contains(elem T) bool {
+	return rq.Snapshot().Contains(elem)
+}
+
+// MarshalJSON serializes the Ref queue to JSON.
+func (rq *Ref[T]) MarshalJSON() ([]byte, error) {
+	return rq.Snapshot().MarshalJSON()
+}
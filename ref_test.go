@@ -0,0 +1,233 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestRef(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NegativeCapacity", testRefNegativeCapacity)
+	t.Run("FIFO", testRefFIFO)
+	t.Run("Capacity", testRefCapacity)
+	t.Run("Empty", testRefEmpty)
+	t.Run("Snapshot", testRefSnapshot)
+	t.Run("SizeAndContains", testRefSizeAndContains)
+	t.Run("ClearAndIterator", testRefClearAndIterator)
+	t.Run("Reset", testRefReset)
+	t.Run("MarshalJSON", testRefMarshalJSON)
+	t.Run("ConcurrentProducersConsumers", testRefConcurrentProducersConsumers)
+}
+
+func testRefNegativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != negativeCapacityPanic {
+			t.Fatalf("expected panic %q, got %v", negativeCapacityPanic, p)
+		}
+	}()
+
+	_ = queue.NewRef[int](nil, queue.WithCapacity(-1))
+}
+
+func testRefFIFO(t *testing.T) {
+	t.Parallel()
+
+	ref := queue.NewRef([]int{1, 2})
+
+	if err := ref.Offer(3); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if got, _ := ref.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	for want := 1; want <= 3; want++ {
+		got, err := ref.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testRefCapacity(t *testing.T) {
+	t.Parallel()
+
+	ref := queue.NewRef([]int{1, 2, 3}, queue.WithCapacity(2))
+
+	if err := ref.Offer(4); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+
+	if got := ref.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testRefEmpty(t *testing.T) {
+	t.Parallel()
+
+	ref := queue.NewRef[int](nil)
+
+	if _, err := ref.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := ref.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !ref.IsEmpty() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testRefSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ref := queue.NewRef([]int{1, 2})
+
+	snapshot := ref.Snapshot()
+
+	_, _ = ref.Get()
+	_ = ref.Offer(3)
+
+	if got := snapshot.Elems(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected the snapshot to keep [1 2], got %v", got)
+	}
+
+	if got := ref.Snapshot().Elems(); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Fatalf("expected [2 3], got %v", got)
+	}
+}
+
+func testRefSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	ref := queue.NewRef([]int{1, 2})
+
+	if ref.Size() != 2 || ref.IsEmpty() {
+		t.Fatalf("expected size 2, got %d", ref.Size())
+	}
+
+	if !ref.Contains(2) || ref.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testRefClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	ref := queue.NewRef([]int{1, 2, 3})
+
+	if got := ref.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	_ = ref.Offer(4)
+
+	var got []int
+
+	for e := range ref.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{4}) {
+		t.Fatalf("expected [4], got %v", got)
+	}
+}
+
+func testRefReset(t *testing.T) {
+	t.Parallel()
+
+	elems := []int{1, 2}
+
+	ref := queue.NewRef(elems)
+
+	elems[0] = 100
+
+	_, _ = ref.Get()
+	_ = ref.Offer(3)
+
+	ref.Reset()
+
+	if got := ref.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testRefMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewRef([]int{1, 2}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2]" {
+		t.Fatalf("expected [1,2], got %s", data)
+	}
+}
+
+func testRefConcurrentProducersConsumers(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goroutines   = 8
+		perGoroutine = 1_000
+	)
+
+	ref := queue.NewRef[int](nil)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		received []int
+	)
+
+	wg.Add(goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			var local []int
+
+			for i := 0; i < perGoroutine; i++ {
+				_ = ref.Offer(g*perGoroutine + i)
+
+				if elem, err := ref.Get(); err == nil {
+					local = append(local, elem)
+				}
+			}
+
+			mu.Lock()
+			received = append(received, local...)
+			mu.Unlock()
+		}(g)
+	}
+
+	wg.Wait()
+
+	received = append(received, ref.Clear()...)
+
+	sort.Ints(received)
+
+	for i, e := range received {
+		if e != i {
+			t.Fatalf("element %d missing or duplicated, got %d", i, e)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package queue
+
+import (
+	"container/heap"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// sequenced pairs an element with its cached sequence number.
+type sequenced[T comparable] struct {
+	elem T
+	seq  uint64
+}
+
+// Ensure Resequencer implements the Queue interface.
+var _ Queue[any] = (*Resequencer[any])(nil)
+
+// Resequencer is a Queue implementation that releases elements strictly
+// in the order of their sequence numbers, as computed by a caller-supplied
+// function, e.g. to deliver packets received out of order.
+//
+// Elements can be offered in any order, but Get and GetWait only release
+// the element with the next expected sequence number, starting at the one
+// given to NewResequencer; elements after a missing sequence number are
+// held back until it arrives. With WithGapTimeout, a missing sequence
+// number is skipped once later elements have waited for it that long.
+// With WithWindow, elements too far ahead are rejected with
+// ErrWindowOverflow instead of being buffered.
+//
+// Offer returns ErrDuplicateSequence for a sequence number that is
+// already buffered, or that was already released or skipped.
+type Resequencer[T comparable] struct {
+	seqFunc  func(T) uint64
+	items    priorityHeap[sequenced[T]]
+	buffered map[uint64]struct{}
+	// next is the sequence number the queue releases next.
+	next         uint64
+	initialNext  uint64
+	initialElems []T
+	gapTimeout   time.Duration
+	window       *int
+	now          func() time.Time
+	// blockedSince is when the lowest buffered element started waiting
+	// for a missing sequence number, zero while nothing waits.
+	blockedSince time.Time
+
+	lock     sync.Mutex
+	notEmpty *sync.Cond
+}
+
+// NewResequencer creates a Resequencer containing the given elements,
+// which releases the element with sequence number next first. seqFunc is
+// called once per offered element. Elements Offer would reject are
+// dropped.
+// Panics if seqFunc is nil, WithGapTimeout is negative or WithWindow is
+// not positive.
+func NewResequencer[T comparable](
+	elems []T,
+	seqFunc func(T) uint64,
+	next uint64,
+	opts ...Option,
+) *Resequencer[T] {
+	if seqFunc == nil {
+		panic("nil seq func")
+	}
+
+	options := options{clock: time.Now, gapTimeout: 0, window: nil}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.gapTimeout < 0 {
+		panic("negative gap timeout")
+	}
+
+	if options.window != nil && *options.window <= 0 {
+		panic("window must be positive")
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	rq := &Resequencer[T]{
+		seqFunc:      seqFunc,
+		initialNext:  next,
+		initialElems: initialElems,
+		gapTimeout:   options.gapTimeout,
+		window:       options.window,
+		now:          options.clock,
+	}
+
+	rq.items.lessFunc = func(elem, otherElem sequenced[T]) bool {
+		return elem.seq < otherElem.seq
+	}
+
+	rq.notEmpty = sync.NewCond(&rq.lock)
+
+	rq.reset()
+
+	return rq
+}
+
+// ==================================Insertion=================================
+
+// Offer buffers the element until every element before it in sequence
+// order was released or skipped.
+// It returns ErrDuplicateSequence if the sequence number of the element
+// is already buffered, released or skipped, and ErrWindowOverflow if it
+// is beyond the window.
+func (rq *Resequencer[T]) Offer(elem T) error {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if err := rq.offer(elem); err != nil {
+		return err
+	}
+
+	rq.notEmpty.Broadcast()
+
+	return nil
+}
+
+// Reset sets the queue to its initial state, with the original elements
+// and next expected sequence number.
+func (rq *Resequencer[T]) Reset() {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	rq.reset()
+
+	rq.notEmpty.Broadcast()
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the element with the next expected sequence
+// number, or the lowest buffered one if the gap before it timed out.
+// Otherwise it returns an ErrNoElementsAvailable error.
+func (rq *Resequencer[T]) Get() (v T, _ error) {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if ok, _ := rq.release(rq.now()); !ok {
+		return v, ErrNoElementsAvailable
+	}
+
+	return rq.pop(), nil
+}
+
+// GetWait removes and returns the element with the next expected sequence
+// number, waiting until it is offered or, with WithGapTimeout, until the
+// gap before the lowest buffered element times out.
+func (rq *Resequencer[T]) GetWait() T {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	for {
+		ok, wait := rq.release(rq.now())
+		if ok {
+			return rq.pop()
+		}
+
+		if wait <= 0 {
+			// Only an Offer or Reset can make an element releasable.
+			rq.notEmpty.Wait()
+
+			continue
+		}
+
+		// Schedule a timer that Broadcasts when the gap times out, then
+		// Wait. Any earlier Offer or Reset also Broadcasts, so we
+		// re-check on state changes too.
+		timer := time.AfterFunc(wait, func() {
+			rq.lock.Lock()
+			rq.notEmpty.Broadcast()
+			rq.lock.Unlock()
+		})
+
+		rq.notEmpty.Wait()
+		timer.Stop()
+	}
+}
+
+// Clear removes and returns all buffered elements in sequence order,
+// including those held back by a gap. The next expected sequence number
+// is left unchanged.
+func (rq *Resequencer[T]) Clear() []T {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	return rq.drain()
+}
+
+// Iterator returns a channel that receives all buffered elements in
+// sequence order. Elements are removed from the queue.
+func (rq *Resequencer[T]) Iterator() <-chan T {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	elems := rq.drain()
+
+	ch := make(chan T, len(elems))
+
+	for i := range elems {
+		ch <- elems[i]
+	}
+
+	close(ch)
+
+	return ch
+}
+
+// =================================Examination================================
+
+// Peek returns the element the next Get would release.
+// If no element can be released it returns an ErrNoElementsAvailable
+// error.
+func (rq *Resequencer[T]) Peek() (v T, _ error) {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	if ok, _ := rq.release(rq.now()); !ok {
+		return v, ErrNoElementsAvailable
+	}
+
+	return rq.items.elems[0].elem, nil
+}
+
+// Next returns the next expected sequence number.
+func (rq *Resequencer[T]) Next() uint64 {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	return rq.next
+}
+
+// Size returns the number of buffered elements, including those held
+// back by a gap.
+func (rq *Resequencer[T]) Size() int {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	return rq.items.Len()
+}
+
+// IsEmpty returns true if no element is buffered.
+func (rq *Resequencer[T]) IsEmpty() bool {
+	return rq.Size() == 0
+}
+
+// Contains reports whether the given element is buffered.
+func (rq *Resequencer[T]) Contains(elem T) bool {
+	rq.lock.Lock()
+	defer rq.lock.Unlock()
+
+	for _, e := range rq.items.elems {
+		if e.elem == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the buffered elements of the Resequencer to JSON
+// in sequence order.
+func (rq *Resequencer[T]) MarshalJSON() ([]byte, error) {
+	rq.lock.Lock()
+
+	snapshot := make([]sequenced[T], len(rq.items.elems))
+	copy(snapshot, rq.items.elems)
+
+	rq.lock.Unlock()
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].seq < snapshot[j].seq
+	})
+
+	out := make([]T, len(snapshot))
+	for i := range snapshot {
+		out[i] = snapshot[i].elem
+	}
+
+	return json.Marshal(out)
+}
+
+// ===================================Helpers==================================
+
+// offer buffers the element. Caller must hold the lock.
+func (rq *Resequencer[T]) offer(elem T) error {
+	seq := rq.seqFunc(elem)
+
+	if _, ok := rq.buffered[seq]; ok || seq < rq.next {
+		return ErrDuplicateSequence
+	}
+
+	if rq.window != nil && seq-rq.next >= uint64(*rq.window) {
+		return ErrWindowOverflow
+	}
+
+	heap.Push(&rq.items, sequenced[T]{elem: elem, seq: seq})
+	rq.buffered[seq] = struct{}{}
+
+	rq.track()
+
+	return nil
+}
+
+// release reports whether the lowest buffered element can be released at
+// now. If it cannot only because the gap before it has not timed out yet,
+// wait is the time left until it does; otherwise wait is zero or less.
+// Caller must hold the lock.
+func (rq *Resequencer[T]) release(now time.Time) (ok bool, wait time.Duration) {
+	if rq.items.Len() == 0 {
+		return false, 0
+	}
+
+	if rq.items.elems[0].seq == rq.next || rq.gapTimeout == 0 {
+		return rq.items.elems[0].seq == rq.next, 0
+	}
+
+	wait = rq.gapTimeout - now.Sub(rq.blockedSince)
+
+	return wait <= 0, wait
+}
+
+// pop removes and returns the lowest buffered element, skipping the gap
+// before it if there is one. The queue must be non-empty and the caller
+// must hold the lock.
+func (rq *Resequencer[T]) pop() T {
+	// nolint: forcetypeassert, revive // the heap only ever holds sequenced[T].
+	e := heap.Pop(&rq.items).(sequenced[T])
+
+	delete(rq.buffered, e.seq)
+	rq.next = e.seq + 1
+
+	// Any gap before the new lowest element is a new one, with a full
+	// timeout of its own.
+	rq.blockedSince = time.Time{}
+	rq.track()
+
+	return e.elem
+}
+
+// track starts the gap timeout when the lowest buffered element starts
+// waiting for a missing sequence number, and stops it when it no longer
+// does. Caller must hold the lock.
+func (rq *Resequencer[T]) track() {
+	switch {
+	case rq.items.Len() == 0 || rq.items.elems[0].seq == rq.next:
+		rq.blockedSince = time.Time{}
+	case rq.blockedSince.IsZero():
+		rq.blockedSince = rq.now()
+	}
+}
+
+// drain removes and returns all buffered elements in sequence order.
+// Caller must hold the lock.
+func (rq *Resequencer[T]) drain() []T {
+	out := make([]T, rq.items.Len())
+
+	for i := range out {
+		// nolint: forcetypeassert, revive // the heap only ever holds sequenced[T].
+		out[i] = heap.Pop(&rq.items).(sequenced[T]).elem
+	}
+
+	rq.buffered = make(map[uint64]struct{})
+	rq.blockedSince = time.Time{}
+
+	return out
+}
+
+// reset restores the initial elements and next expected sequence number.
+// Caller must hold the lock.
+func (rq *Resequencer[T]) reset() {
+	rq.items.elems = nil
+	rq.buffered = make(map[uint64]struct{}, len(rq.initialElems))
+	rq.next = rq.initialNext
+	rq.blockedSince = time.Time{}
+
+	for _, e := range rq.initialElems {
+		_ = rq.offer(e)
+	}
+}
@@ -0,0 +1,354 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+// seqOf uses an int element as its own sequence number.
+func seqOf(elem int) uint64 {
+	return uint64(elem)
+}
+
+func TestResequencer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InvalidArguments", testResequencerInvalidArguments)
+	t.Run("InOrder", testResequencerInOrder)
+	t.Run("DuplicateSequence", testResequencerDuplicateSequence)
+	t.Run("Window", testResequencerWindow)
+	t.Run("GapTimeout", testResequencerGapTimeout)
+	t.Run("ConsecutiveGaps", testResequencerConsecutiveGaps)
+	t.Run("GetWait", testResequencerGetWait)
+	t.Run("GetWaitGapTimeout", testResequencerGetWaitGapTimeout)
+	t.Run("ClearAndIterator", testResequencerClearAndIterator)
+	t.Run("Reset", testResequencerReset)
+	t.Run("SizeAndContains", testResequencerSizeAndContains)
+	t.Run("MarshalJSON", testResequencerMarshalJSON)
+}
+
+func testResequencerInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		want string
+		new  func()
+	}{
+		"NilSeqFunc": {
+			want: "nil seq func",
+			new:  func() { queue.NewResequencer[int](nil, nil, 0) },
+		},
+		"NegativeGapTimeout": {
+			want: "negative gap timeout",
+			new:  func() { queue.NewResequencer(nil, seqOf, 0, queue.WithGapTimeout(-time.Second)) },
+		},
+		"NonPositiveWindow": {
+			want: "window must be positive",
+			new:  func() { queue.NewResequencer(nil, seqOf, 0, queue.WithWindow(0)) },
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if p := recover(); p != c.want {
+					t.Fatalf("expected panic %q, got %v", c.want, p)
+				}
+			}()
+
+			c.new()
+		})
+	}
+}
+
+func testResequencerInOrder(t *testing.T) {
+	t.Parallel()
+
+	rq := queue.NewResequencer([]int{12}, seqOf, 10)
+
+	if err := rq.Offer(11); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if _, err := rq.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get before 10: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := rq.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek before 10: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	_ = rq.Offer(10)
+
+	if got, _ := rq.Peek(); got != 10 {
+		t.Fatalf("peek: got %d want 10", got)
+	}
+
+	for want := 10; want <= 12; want++ {
+		got, err := rq.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+
+	if rq.Next() != 13 || !rq.IsEmpty() {
+		t.Fatalf("expected an empty queue expecting 13, got next %d", rq.Next())
+	}
+
+	if _, err := rq.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get on empty: expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+
+func testResequencerDuplicateSequence(t *testing.T) {
+	t.Parallel()
+
+	rq := queue.NewResequencer([]int{0, 2}, seqOf, 0)
+
+	if err := rq.Offer(2); !errors.Is(err, queue.ErrDuplicateSequence) {
+		t.Fatalf("buffered: expected ErrDuplicateSequence, got %v", err)
+	}
+
+	_, _ = rq.Get()
+
+	if err := rq.Offer(0); !errors.Is(err, queue.ErrDuplicateSequence) {
+		t.Fatalf("released: expected ErrDuplicateSequence, got %v", err)
+	}
+
+	if rq.Size() != 1 {
+		t.Fatalf("expected size 1, got %d", rq.Size())
+	}
+}
+
+func testResequencerWindow(t *testing.T) {
+	t.Parallel()
+
+	rq := queue.NewResequencer(nil, seqOf, 0, queue.WithWindow(2))
+
+	if err := rq.Offer(2); !errors.Is(err, queue.ErrWindowOverflow) {
+		t.Fatalf("expected ErrWindowOverflow, got %v", err)
+	}
+
+	for _, elem := range []int{1, 0} {
+		if err := rq.Offer(elem); err != nil {
+			t.Fatalf("offer %d: %v", elem, err)
+		}
+	}
+
+	_, _ = rq.Get()
+
+	if err := rq.Offer(2); err != nil {
+		t.Fatalf("offer 2 after the window moved: %v", err)
+	}
+}
+
+func testResequencerGapTimeout(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+
+	rq := queue.NewResequencer(
+		[]int{2},
+		seqOf,
+		0,
+		queue.WithGapTimeout(10*time.Second),
+		queue.WithClock(clock.Now),
+	)
+
+	clock.Advance(5 * time.Second)
+
+	// A lower element arriving does not restart the timeout.
+	_ = rq.Offer(1)
+
+	if _, err := rq.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get before the timeout: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	clock.Advance(5 * time.Second)
+
+	if got, _ := rq.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	for want := 1; want <= 2; want++ {
+		if got, err := rq.Get(); err != nil || got != want {
+			t.Fatalf("get: got %d, %v want %d", got, err, want)
+		}
+	}
+
+	// The next gap gets a full timeout of its own.
+	_ = rq.Offer(4)
+
+	clock.Advance(9 * time.Second)
+
+	if _, err := rq.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get before the timeout: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	clock.Advance(time.Second)
+
+	if got, err := rq.Get(); err != nil || got != 4 {
+		t.Fatalf("get: got %d, %v want 4", got, err)
+	}
+
+	if rq.Next() != 5 {
+		t.Fatalf("expected next 5, got %d", rq.Next())
+	}
+}
+
+func testResequencerConsecutiveGaps(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+
+	rq := queue.NewResequencer(
+		[]int{5},
+		seqOf,
+		0,
+		queue.WithGapTimeout(time.Second),
+		queue.WithClock(clock.Now),
+	)
+
+	clock.Advance(time.Second)
+
+	_ = rq.Offer(10)
+
+	if got, err := rq.Get(); err != nil || got != 5 {
+		t.Fatalf("get: got %d, %v want 5", got, err)
+	}
+
+	// Skipping 0..4 leaves 10 waiting for 6..9, which restarts the timeout.
+	if _, err := rq.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get before the timeout: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	clock.Advance(time.Second)
+
+	if got, err := rq.Get(); err != nil || got != 10 {
+		t.Fatalf("get: got %d, %v want 10", got, err)
+	}
+}
+
+func testResequencerGetWait(t *testing.T) {
+	t.Parallel()
+
+	rq := queue.NewResequencer([]int{1}, seqOf, 0)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+
+		_ = rq.Offer(0)
+	}()
+
+	for want := 0; want <= 1; want++ {
+		if got := rq.GetWait(); got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testResequencerGetWaitGapTimeout(t *testing.T) {
+	t.Parallel()
+
+	const gapTimeout = 20 * time.Millisecond
+
+	rq := queue.NewResequencer([]int{1}, seqOf, 0, queue.WithGapTimeout(gapTimeout))
+
+	start := time.Now()
+
+	if got := rq.GetWait(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	if elapsed := time.Since(start); elapsed < gapTimeout {
+		t.Fatalf("released after %v, before the gap timeout", elapsed)
+	}
+}
+
+func testResequencerClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	rq := queue.NewResequencer([]int{3, 1}, seqOf, 0)
+
+	if got := rq.Clear(); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Fatalf("expected [1 3], got %v", got)
+	}
+
+	if rq.Next() != 0 || !rq.IsEmpty() {
+		t.Fatalf("expected an empty queue expecting 0, got next %d", rq.Next())
+	}
+
+	// Cleared sequence numbers can be offered again.
+	_ = rq.Offer(3)
+	_ = rq.Offer(1)
+
+	var got []int
+
+	for e := range rq.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Fatalf("expected [1 3], got %v", got)
+	}
+}
+
+func testResequencerReset(t *testing.T) {
+	t.Parallel()
+
+	// The duplicate initial element is dropped.
+	rq := queue.NewResequencer([]int{0, 1, 1}, seqOf, 0)
+
+	_, _ = rq.Get()
+	_, _ = rq.Get()
+	_ = rq.Offer(5)
+
+	rq.Reset()
+
+	if rq.Next() != 0 {
+		t.Fatalf("expected next 0, got %d", rq.Next())
+	}
+
+	if got := rq.Clear(); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Fatalf("expected [0 1], got %v", got)
+	}
+}
+
+func testResequencerSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	rq := queue.NewResequencer([]int{1, 2}, seqOf, 0)
+
+	if !rq.Contains(2) || rq.Contains(0) {
+		t.Fatal("unexpected Contains result")
+	}
+
+	if rq.Size() != 2 || rq.IsEmpty() {
+		t.Fatalf("expected size 2, got %d", rq.Size())
+	}
+}
+
+func testResequencerMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewResequencer([]int{3, 1, 2}, seqOf, 0).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2,3]" {
+		t.Fatalf("expected [1,2,3], got %s", data)
+	}
+}
@@ -0,0 +1,262 @@
+package queue
+
+import (
+	"container/heap"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// maxReservoirSkip bounds the number of elements a Reservoir skips at
+// once, so that the skip still fits in a uint64 when it is huge.
+const maxReservoirSkip = 1 << 62
+
+// reservoirEntry is an element of a weighted Reservoir with its key: the
+// sample holds the elements with the largest keys.
+type reservoirEntry[T comparable] struct {
+	elem T
+	key  float64
+}
+
+// Reservoir keeps a statistically uniform sample of at most capacity
+// elements out of an unbounded stream of offered elements, in O(capacity)
+// memory, e.g. to sample telemetry. Where Circular keeps the last
+// elements, every element offered to a Reservoir is equally likely to be
+// in the sample, whatever its position in the stream.
+//
+// It implements Algorithm L: once the sample is full, it draws how many
+// elements to skip before the next one replaces a random element of the
+// sample, so that most Offer calls only increment a counter.
+//
+// With WithWeight, the sample is weighted instead (Algorithm A-Res): every
+// element gets the key u^(1/weight) for a random u, and the sample holds
+// the elements with the largest keys, in a heap.
+//
+// Random numbers come from the global source of math/rand unless
+// WithRand is given. A Reservoir is not a Queue: elements cannot be taken
+// out of it, the sample is read with Snapshot.
+type Reservoir[T comparable] struct {
+	capacity int
+	rand     *rand.Rand
+	weight   func(T) float64
+
+	// sample holds the unweighted sample.
+	sample []T
+	// keyed holds the weighted sample, smallest key first.
+	keyed priorityHeap[reservoirEntry[T]]
+
+	// seen is the number of elements offered so far.
+	seen uint64
+	// next is the value of seen at which the next element enters the
+	// full unweighted sample, and w the largest random key of the sample
+	// in Algorithm L.
+	next uint64
+	w    float64
+
+	lock sync.Mutex
+}
+
+// NewReservoir returns a new, empty Reservoir keeping a sample of at most
+// capacity elements.
+// Panics if capacity is not positive or if the WithWeight function does
+// not take T.
+func NewReservoir[T comparable](capacity int, opts ...Option) *Reservoir[T] {
+	if capacity <= 0 {
+		panic("capacity must be positive")
+	}
+
+	options := options{
+		rand:   nil,
+		weight: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	var weight func(T) float64
+
+	if options.weight != nil {
+		fn, ok := options.weight.(func(T) float64)
+		if !ok {
+			panic("weight func does not match the element type")
+		}
+
+		weight = fn
+	}
+
+	r := &Reservoir[T]{
+		capacity: capacity,
+		rand:     options.rand,
+		weight:   weight,
+	}
+
+	r.keyed.lessFunc = func(elem, otherElem reservoirEntry[T]) bool {
+		return elem.key < otherElem.key
+	}
+
+	r.reset()
+
+	return r
+}
+
+// ==================================Insertion=================================
+
+// Offer presents the element to the reservoir, which keeps it in the
+// sample with the probability that keeps the sample uniform, or weighted
+// with WithWeight. It runs in O(1), or O(log capacity) when weighted.
+func (r *Reservoir[T]) Offer(elem T) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.seen++
+
+	if r.weight != nil {
+		r.offerWeighted(elem)
+
+		return
+	}
+
+	if len(r.sample) < r.capacity {
+		r.sample = append(r.sample, elem)
+
+		if len(r.sample) == r.capacity {
+			r.skip()
+		}
+
+		return
+	}
+
+	if r.seen == r.next {
+		r.sample[r.intn(r.capacity)] = elem
+		r.skip()
+	}
+}
+
+// Reset empties the sample and sets the number of seen elements to zero.
+func (r *Reservoir[T]) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.reset()
+}
+
+// =================================Examination================================
+
+// Snapshot returns a copy of the current sample, in no particular order.
+func (r *Reservoir[T]) Snapshot() []T {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.weight == nil {
+		sample := make([]T, len(r.sample))
+		copy(sample, r.sample)
+
+		return sample
+	}
+
+	sample := make([]T, len(r.keyed.elems))
+
+	for i, entry := range r.keyed.elems {
+		sample[i] = entry.elem
+	}
+
+	return sample
+}
+
+// Seen returns the number of elements offered since the reservoir was
+// created or last reset.
+func (r *Reservoir[T]) Seen() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.seen
+}
+
+// Size returns the number of elements in the sample, which is the
+// capacity once enough elements were offered.
+func (r *Reservoir[T]) Size() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return len(r.sample) + len(r.keyed.elems)
+}
+
+// MarshalJSON serializes the sample of the Reservoir to JSON.
+func (r *Reservoir[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Snapshot())
+}
+
+// ===================================Helpers==================================
+
+// offerWeighted gives the element the key log(u)/weight, which orders the
+// elements like u^(1/weight) without underflowing, and keeps it if its key
+// is among the largest. Caller must hold the lock.
+func (r *Reservoir[T]) offerWeighted(elem T) {
+	weight := r.weight(elem)
+
+	// Also rejects NaN.
+	if !(weight > 0) {
+		return
+	}
+
+	entry := reservoirEntry[T]{elem: elem, key: math.Log(r.float()) / weight}
+
+	if r.keyed.Len() < r.capacity {
+		heap.Push(&r.keyed, entry)
+
+		return
+	}
+
+	if entry.key > r.keyed.elems[0].key {
+		r.keyed.elems[0] = entry
+		heap.Fix(&r.keyed, 0)
+	}
+}
+
+// skip draws the largest random key of the sample after a replacement
+// and, from it, the next element to enter the sample. Caller must hold
+// the lock.
+func (r *Reservoir[T]) skip() {
+	r.w *= math.Exp(math.Log(r.float()) / float64(r.capacity))
+
+	// After enough replacements w underflows to zero, which would make
+	// Log1p(-w) zero too, and the skip NaN when the random number drawn
+	// for it is 1. Keep w positive, so that the skip is at worst huge,
+	// and clamped.
+	if r.w == 0 {
+		r.w = math.SmallestNonzeroFloat64
+	}
+
+	skipped := math.Floor(math.Log(r.float()) / math.Log1p(-r.w))
+
+	r.next = r.seen + uint64(math.Min(skipped, maxReservoirSkip)) + 1
+}
+
+// float returns a random number in (0, 1]. Caller must hold the lock.
+func (r *Reservoir[T]) float() float64 {
+	if r.rand != nil {
+		return 1 - r.rand.Float64()
+	}
+
+	return 1 - rand.Float64() //nolint:gosec // not security sensitive
+}
+
+// intn returns a random number in [0, n). Caller must hold the lock.
+func (r *Reservoir[T]) intn(n int) int {
+	if r.rand != nil {
+		return r.rand.Intn(n)
+	}
+
+	return rand.Intn(n) //nolint:gosec // not security sensitive
+}
+
+// reset empties the sample. Caller must hold the lock.
+func (r *Reservoir[T]) reset() {
+	r.sample = nil
+	r.keyed.elems = nil
+	r.seen = 0
+	r.next = 0
+	r.w = 1
+}
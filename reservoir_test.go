@@ -0,0 +1,290 @@
+package queue_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestReservoir(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NonPositiveCapacity", testReservoirNonPositiveCapacity)
+	t.Run("WeightTypeMismatch", testReservoirWeightTypeMismatch)
+	t.Run("FillsUp", testReservoirFillsUp)
+	t.Run("Deterministic", testReservoirDeterministic)
+	t.Run("Uniform", testReservoirUniform)
+	t.Run("GlobalRand", testReservoirGlobalRand)
+	t.Run("Weighted", testReservoirWeighted)
+	t.Run("NonPositiveWeight", testReservoirNonPositiveWeight)
+	t.Run("Reset", testReservoirReset)
+	t.Run("MarshalJSON", testReservoirMarshalJSON)
+	t.Run("KeyUnderflow", testReservoirKeyUnderflow)
+}
+
+func testReservoirNonPositiveCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "capacity must be positive" {
+			t.Fatalf("expected panic %q, got %v", "capacity must be positive", p)
+		}
+	}()
+
+	_ = queue.NewReservoir[int](0)
+}
+
+func testReservoirWeightTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	const want = "weight func does not match the element type"
+
+	defer func() {
+		if p := recover(); p != want {
+			t.Fatalf("expected panic %q, got %v", want, p)
+		}
+	}()
+
+	_ = queue.NewReservoir[int](1, queue.WithWeight(func(string) float64 { return 1 }))
+}
+
+func testReservoirFillsUp(t *testing.T) {
+	t.Parallel()
+
+	reservoir := queue.NewReservoir[int](3)
+
+	for i := 1; i <= 2; i++ {
+		reservoir.Offer(i)
+	}
+
+	if got := reservoir.Snapshot(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+
+	for i := 3; i <= 100; i++ {
+		reservoir.Offer(i)
+	}
+
+	if reservoir.Size() != 3 || reservoir.Seen() != 100 {
+		t.Fatalf("expected size 3 and 100 seen, got %d and %d", reservoir.Size(), reservoir.Seen())
+	}
+}
+
+func testReservoirDeterministic(t *testing.T) {
+	t.Parallel()
+
+	sample := func() []int {
+		reservoir := queue.NewReservoir[int](5, queue.WithRand(rand.New(rand.NewSource(42))))
+
+		for i := 0; i < 1000; i++ {
+			reservoir.Offer(i)
+		}
+
+		return reservoir.Snapshot()
+	}
+
+	if first, second := sample(), sample(); !reflect.DeepEqual(first, second) {
+		t.Fatalf("same seed, different samples: %v and %v", first, second)
+	}
+}
+
+// testReservoirUniform checks that every position of the stream is about
+// equally likely to end up in the sample.
+func testReservoirUniform(t *testing.T) {
+	t.Parallel()
+
+	const (
+		capacity = 10
+		stream   = 100
+		trials   = 5000
+		want     = trials * capacity / stream
+	)
+
+	r := rand.New(rand.NewSource(1))
+	counts := make([]int, stream)
+
+	for i := 0; i < trials; i++ {
+		reservoir := queue.NewReservoir[int](capacity, queue.WithRand(r))
+
+		for elem := 0; elem < stream; elem++ {
+			reservoir.Offer(elem)
+		}
+
+		for _, elem := range reservoir.Snapshot() {
+			counts[elem]++
+		}
+	}
+
+	for elem, count := range counts {
+		if count < want*8/10 || count > want*12/10 {
+			t.Fatalf("element %d was sampled %d times, want about %d", elem, count, want)
+		}
+	}
+}
+
+func testReservoirGlobalRand(t *testing.T) {
+	t.Parallel()
+
+	reservoir := queue.NewReservoir[int](2, queue.WithWeight(func(int) float64 { return 1 }))
+	unweighted := queue.NewReservoir[int](2)
+
+	for i := 0; i < 50; i++ {
+		reservoir.Offer(i)
+		unweighted.Offer(i)
+	}
+
+	if reservoir.Size() != 2 || unweighted.Size() != 2 {
+		t.Fatalf("expected full samples, got %d and %d", reservoir.Size(), unweighted.Size())
+	}
+}
+
+// testReservoirWeighted checks that a single-slot weighted reservoir keeps
+// every element with a probability proportional to its weight.
+func testReservoirWeighted(t *testing.T) {
+	t.Parallel()
+
+	const trials = 10000
+
+	weights := []float64{1, 2, 3, 4}
+
+	r := rand.New(rand.NewSource(1))
+	counts := make([]int, len(weights))
+
+	for i := 0; i < trials; i++ {
+		reservoir := queue.NewReservoir[int](
+			1,
+			queue.WithRand(r),
+			queue.WithWeight(func(elem int) float64 { return weights[elem] }),
+		)
+
+		for elem := range weights {
+			reservoir.Offer(elem)
+		}
+
+		counts[reservoir.Snapshot()[0]]++
+	}
+
+	for elem, count := range counts {
+		want := int(trials * weights[elem] / 10)
+
+		if count < want*9/10 || count > want*11/10 {
+			t.Fatalf("element %d was sampled %d times, want about %d", elem, count, want)
+		}
+	}
+}
+
+func testReservoirNonPositiveWeight(t *testing.T) {
+	t.Parallel()
+
+	reservoir := queue.NewReservoir[int](
+		3,
+		queue.WithWeight(func(elem int) float64 {
+			switch elem {
+			case 0:
+				return 0
+			case 1:
+				return math.NaN()
+			default:
+				return 1
+			}
+		}),
+	)
+
+	for elem := 0; elem < 4; elem++ {
+		reservoir.Offer(elem)
+	}
+
+	got := reservoir.Snapshot()
+	sort.Ints(got)
+
+	if !reflect.DeepEqual(got, []int{2, 3}) || reservoir.Seen() != 4 {
+		t.Fatalf("expected [2 3] and 4 seen, got %v and %d", got, reservoir.Seen())
+	}
+}
+
+func testReservoirReset(t *testing.T) {
+	t.Parallel()
+
+	reservoir := queue.NewReservoir[int](2)
+
+	for i := 0; i < 10; i++ {
+		reservoir.Offer(i)
+	}
+
+	reservoir.Reset()
+
+	if reservoir.Size() != 0 || reservoir.Seen() != 0 {
+		t.Fatalf("expected an empty reservoir, got %v", reservoir.Snapshot())
+	}
+
+	reservoir.Offer(42)
+
+	if got := reservoir.Snapshot(); !reflect.DeepEqual(got, []int{42}) {
+		t.Fatalf("expected [42], got %v", got)
+	}
+}
+
+func testReservoirMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	reservoir := queue.NewReservoir[int](3)
+
+	reservoir.Offer(1)
+	reservoir.Offer(2)
+
+	data, err := reservoir.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2]" {
+		t.Fatalf("expected [1,2], got %s", data)
+	}
+}
+
+// keyUnderflowSource is a rand.Source driving an unweighted Reservoir of
+// capacity 1, which draws a slot to replace, a number to shrink its
+// largest key w with, and one for the skip, in turn. The slot draw is
+// left out when the sample fills up.
+type keyUnderflowSource struct {
+	draws int
+}
+
+func (s *keyUnderflowSource) Int63() int64 {
+	step := (s.draws + 1) % 3
+
+	s.draws++
+
+	// Shrink w as much as possible: 1<<63 - 1024 is the largest Int63
+	// that Float64 does not round up to 1, and makes the Reservoir draw
+	// 2^-53.
+	if step == 1 {
+		return 1<<63 - 1024
+	}
+
+	// Draw the slot, which is always 0, or a skip of 0.
+	return 0
+}
+
+func (*keyUnderflowSource) Seed(int64) {}
+
+func testReservoirKeyUnderflow(t *testing.T) {
+	t.Parallel()
+
+	// Every replacement shrinks the largest key of the sample by 2^-53,
+	// so that it underflows after about 20 of them, and then draws a skip
+	// of 0, so that every element replaces the sample.
+	reservoir := queue.NewReservoir[int](1, queue.WithRand(rand.New(&keyUnderflowSource{})))
+
+	for i := 0; i < 100; i++ {
+		reservoir.Offer(i)
+	}
+
+	if got := reservoir.Snapshot(); !reflect.DeepEqual(got, []int{99}) {
+		t.Fatalf("expected [99], got %v", got)
+	}
+}
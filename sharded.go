@@ -0,0 +1,243 @@
+package queue
+
+import (
+	"encoding/json"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Ensure Sharded implements the Queue interface.
+var _ Queue[any] = (*Sharded[any])(nil)
+
+// Sharded is an unbounded Queue implementation that spreads its elements
+// over several Linked shards, each with its own lock, so that concurrent
+// producers and consumers rarely contend on the same lock.
+//
+// Every P (see runtime.GOMAXPROCS) has a local shard: Offer places the
+// element on the local shard of the P running the caller, and Get takes
+// the head of that shard, stealing from the following shards in turn only
+// when it is empty, so it reports ErrNoElementsAvailable only after
+// looking at every shard. Producers and consumers on different Ps thus
+// share no lock and no counter. The local shards are kept in a sync.Pool,
+// which may forget them at any time; a P then gets the next shard in
+// round-robin order as its local one.
+//
+// Ordering is relaxed: two elements placed on the same shard are
+// retrieved in the order they were offered, but there is no order across
+// shards, so elements offered one after the other, even by a single
+// goroutine, may be retrieved in any order.
+//
+// Each element lives on exactly one shard until it is removed, so Size,
+// Contains and Clear never count, find or return an element twice. As
+// these methods visit the shards one after the other, they are not atomic
+// with respect to concurrent Offer and Get calls: Size is exact only when
+// no other goroutine uses the queue, and Clear may leave behind elements
+// offered to shards it already visited. Get may likewise report
+// ErrNoElementsAvailable while an element is being offered to a shard it
+// already visited.
+type Sharded[T comparable] struct {
+	shards []*Linked[T]
+
+	// local holds the index of the local shard of every P, as a *int.
+	local sync.Pool
+	// nextLocal is the round-robin counter of the shards handed out as
+	// local ones.
+	nextLocal atomic.Uint64
+}
+
+// NewSharded returns a new Sharded queue containing the given elements,
+// placed on the shards in round-robin order.
+// The number of shards is set with WithShards and defaults to
+// runtime.GOMAXPROCS(0).
+// Panics if WithShards is not positive.
+func NewSharded[T comparable](elems []T, opts ...Option) *Sharded[T] {
+	options := options{
+		shards: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	n := runtime.GOMAXPROCS(0)
+
+	if options.shards != nil {
+		if *options.shards <= 0 {
+			panic("shard count must be positive")
+		}
+
+		n = *options.shards
+	}
+
+	shardElems := make([][]T, n)
+	for i, e := range elems {
+		shardElems[i%n] = append(shardElems[i%n], e)
+	}
+
+	sq := &Sharded[T]{
+		shards: make([]*Linked[T], n),
+	}
+
+	sq.local.New = func() any {
+		i := int((sq.nextLocal.Add(1) - 1) % uint64(n))
+
+		return &i
+	}
+
+	for i := range sq.shards {
+		sq.shards[i] = NewLinked(shardElems[i])
+	}
+
+	return sq
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element on the local shard. It never fails.
+func (sq *Sharded[T]) Offer(elem T) error {
+	return sq.shards[sq.localShard()].Offer(elem)
+}
+
+// Reset sets the queue to its initial state with the original elements on
+// their original shards.
+func (sq *Sharded[T]) Reset() {
+	for _, shard := range sq.shards {
+		shard.Reset()
+	}
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the head of the local shard, or of the first
+// non-empty shard after it.
+// If all shards are empty it returns an ErrNoElementsAvailable error.
+func (sq *Sharded[T]) Get() (v T, _ error) {
+	start := sq.localShard()
+
+	for i := range sq.shards {
+		elem, err := sq.shard(start, i).Get()
+		if err == nil {
+			return elem, nil
+		}
+	}
+
+	return v, ErrNoElementsAvailable
+}
+
+// Clear removes and returns all elements from the queue, shard by shard.
+func (sq *Sharded[T]) Clear() []T {
+	removed := make([]T, 0, sq.Size())
+
+	for _, shard := range sq.shards {
+		removed = append(removed, shard.Clear()...)
+	}
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this queue.
+// It removes the elements from the queue.
+func (sq *Sharded[T]) Iterator() <-chan T {
+	elems := sq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the head of the local shard, or of
+// the first non-empty shard after it.
+// If all shards are empty it returns an ErrNoElementsAvailable error.
+func (sq *Sharded[T]) Peek() (v T, _ error) {
+	start := sq.localShard()
+
+	for i := range sq.shards {
+		elem, err := sq.shard(start, i).Peek()
+		if err == nil {
+			return elem, nil
+		}
+	}
+
+	return v, ErrNoElementsAvailable
+}
+
+// Size returns the number of elements in the queue.
+func (sq *Sharded[T]) Size() int {
+	size := 0
+
+	for _, shard := range sq.shards {
+		size += shard.Size()
+	}
+
+	return size
+}
+
+// IsEmpty returns true if the queue is empty.
+func (sq *Sharded[T]) IsEmpty() bool {
+	for _, shard := range sq.shards {
+		if !shard.IsEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Contains returns true if the queue contains the given element.
+func (sq *Sharded[T]) Contains(elem T) bool {
+	for _, shard := range sq.shards {
+		if shard.Contains(elem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Shards returns the number of shards.
+func (sq *Sharded[T]) Shards() int {
+	return len(sq.shards)
+}
+
+// MarshalJSON serializes the Sharded queue to JSON, shard by shard.
+func (sq *Sharded[T]) MarshalJSON() ([]byte, error) {
+	output := []T{}
+
+	for _, shard := range sq.shards {
+		shard.lock.RLock()
+
+		for n := shard.head; n != nil; n = n.next {
+			output = append(output, n.value)
+		}
+
+		shard.lock.RUnlock()
+	}
+
+	return json.Marshal(output)
+}
+
+// ===================================Helpers==================================
+
+// localShard returns the index of the local shard of the P running the
+// caller.
+func (sq *Sharded[T]) localShard() int {
+	i, _ := sq.local.Get().(*int)
+
+	sq.local.Put(i)
+
+	return *i
+}
+
+// shard returns the i-th shard after start, wrapping around.
+func (sq *Sharded[T]) shard(start, i int) *Linked[T] {
+	return sq.shards[(start+i)%len(sq.shards)]
+}
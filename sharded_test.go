@@ -0,0 +1,274 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestSharded(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NonPositiveShards", testShardedNonPositiveShards)
+	t.Run("DefaultShards", testShardedDefaultShards)
+	t.Run("SingleShardIsFIFO", testShardedSingleShardIsFIFO)
+	t.Run("FIFOPerShard", testShardedFIFOPerShard)
+	t.Run("Steal", testShardedSteal)
+	t.Run("Empty", testShardedEmpty)
+	t.Run("SizeAndContains", testShardedSizeAndContains)
+	t.Run("ClearAndIterator", testShardedClearAndIterator)
+	t.Run("Reset", testShardedReset)
+	t.Run("MarshalJSON", testShardedMarshalJSON)
+	t.Run("ConcurrentProducersConsumers", testShardedConcurrentProducersConsumers)
+}
+
+func testShardedNonPositiveShards(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "shard count must be positive" {
+			t.Fatalf("expected panic 'shard count must be positive', got %v", p)
+		}
+	}()
+
+	_ = queue.NewSharded[int](nil, queue.WithShards(0))
+}
+
+func testShardedDefaultShards(t *testing.T) {
+	t.Parallel()
+
+	if got, want := queue.NewSharded[int](nil).Shards(), runtime.GOMAXPROCS(0); got != want {
+		t.Fatalf("expected %d shards, got %d", want, got)
+	}
+}
+
+func testShardedSingleShardIsFIFO(t *testing.T) {
+	t.Parallel()
+
+	sharded := queue.NewSharded([]int{1, 2}, queue.WithShards(1))
+
+	_ = sharded.Offer(3)
+	_ = sharded.Offer(4)
+
+	if got, _ := sharded.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	for want := 1; want <= 4; want++ {
+		got, err := sharded.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+func testShardedFIFOPerShard(t *testing.T) {
+	t.Parallel()
+
+	// The initial elements are placed in round-robin order, so the
+	// elements of one shard share the same remainder modulo 3 and must
+	// come out in increasing order.
+	elems := make([]int, 30)
+	for i := range elems {
+		elems[i] = i
+	}
+
+	sharded := queue.NewSharded(elems, queue.WithShards(3))
+
+	last := []int{-1, -1, -1}
+
+	for i := 0; i < 30; i++ {
+		got, err := sharded.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got <= last[got%3] {
+			t.Fatalf("shard %d: got %d after %d", got%3, got, last[got%3])
+		}
+
+		last[got%3] = got
+	}
+}
+func testShardedSteal(t *testing.T) {
+	t.Parallel()
+
+	// 1 and 2 land on the first shard and 0 on the second. Whichever of
+	// them is local, Get steals from the other one when it is empty, so
+	// it finds every element.
+	sharded := queue.NewSharded([]int{1, 0, 2}, queue.WithShards(2))
+
+	var got []int
+
+	for i := 0; i < 3; i++ {
+		if _, err := sharded.Peek(); err != nil {
+			t.Fatalf("peek: %v", err)
+		}
+
+		elem, err := sharded.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		got = append(got, elem)
+	}
+
+	sorted := append([]int(nil), got...)
+	sort.Ints(sorted)
+
+	if !reflect.DeepEqual(sorted, []int{0, 1, 2}) {
+		t.Fatalf("expected every element once, got %v", got)
+	}
+
+	if _, err := sharded.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+func testShardedEmpty(t *testing.T) {
+	t.Parallel()
+
+	sharded := queue.NewSharded[int](nil, queue.WithShards(4))
+
+	if _, err := sharded.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := sharded.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !sharded.IsEmpty() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testShardedSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	sharded := queue.NewSharded([]int{1, 2, 3}, queue.WithShards(2))
+
+	if sharded.Size() != 3 || sharded.IsEmpty() {
+		t.Fatalf("expected size 3, got %d", sharded.Size())
+	}
+
+	if !sharded.Contains(2) || sharded.Contains(4) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testShardedClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	sharded := queue.NewSharded([]int{1, 2, 3}, queue.WithShards(2))
+
+	if got := sharded.Clear(); !reflect.DeepEqual(got, []int{1, 3, 2}) {
+		t.Fatalf("expected [1 3 2], got %v", got)
+	}
+
+	_ = sharded.Offer(4)
+
+	var got []int
+
+	for e := range sharded.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{4}) {
+		t.Fatalf("expected [4], got %v", got)
+	}
+}
+
+func testShardedReset(t *testing.T) {
+	t.Parallel()
+
+	sharded := queue.NewSharded([]int{1, 2, 3}, queue.WithShards(2))
+
+	_, _ = sharded.Get()
+	_ = sharded.Offer(4)
+
+	sharded.Reset()
+
+	if got := sharded.Clear(); !reflect.DeepEqual(got, []int{1, 3, 2}) {
+		t.Fatalf("expected [1 3 2], got %v", got)
+	}
+}
+func testShardedMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewSharded([]int{1, 2, 3}, queue.WithShards(2)).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,3,2]" {
+		t.Fatalf("expected [1,3,2], got %s", data)
+	}
+}
+
+func testShardedConcurrentProducersConsumers(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goroutines   = 8
+		perGoroutine = 2_000
+	)
+
+	sharded := queue.NewSharded[int](nil, queue.WithShards(4))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		received []int
+	)
+
+	wg.Add(2 * goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < perGoroutine; i++ {
+				_ = sharded.Offer(g*perGoroutine + i)
+			}
+		}(g)
+
+		go func() {
+			defer wg.Done()
+
+			var local []int
+
+			for len(local) < perGoroutine {
+				if elem, err := sharded.Get(); err == nil {
+					local = append(local, elem)
+				}
+			}
+
+			mu.Lock()
+			received = append(received, local...)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	// Every element is received exactly once.
+	sort.Ints(received)
+
+	for i, e := range received {
+		if e != i {
+			t.Fatalf("element %d missing or duplicated, got %d", i, e)
+		}
+	}
+
+	if !sharded.IsEmpty() {
+		t.Fatalf("expected an empty queue, size %d", sharded.Size())
+	}
+}
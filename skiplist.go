@@ -0,0 +1,486 @@
+package queue
+
+import (
+	"encoding/json"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// skipListMaxLevel is the number of levels of a SkipListPriority queue,
+// enough for billions of elements.
+const skipListMaxLevel = 32
+
+// slNode is a node of a SkipListPriority queue. Nodes are ordered by
+// value, and by seq among equal values, so that every node has a unique
+// key and equal elements keep their insertion order.
+type slNode[T any] struct {
+	value T
+	seq   uint64
+	next  []atomic.Pointer[slNode[T]]
+
+	// lock guards the links from this node. A node is marked before it
+	// is unlinked and fully linked once it is reachable on all its
+	// levels; only fully linked, unmarked nodes are in the queue.
+	lock        sync.Mutex
+	marked      atomic.Bool
+	fullyLinked atomic.Bool
+}
+
+// live reports whether the node is in the queue.
+func (n *slNode[T]) live() bool {
+	return n.fullyLinked.Load() && !n.marked.Load()
+}
+
+// slPath holds, for every level, the last node before a key and the node
+// after it.
+type slPath[T any] struct {
+	preds [skipListMaxLevel]*slNode[T]
+	succs [skipListMaxLevel]*slNode[T]
+}
+
+// Ensure SkipListPriority implements the Queue interface.
+var _ Queue[any] = (*SkipListPriority[any])(nil)
+
+// SkipListPriority is a priority Queue implementation backed by a
+// concurrent skip list ordered by a lessFunc, like Priority. The head of
+// the queue is always the highest priority element; equal elements are
+// retrieved in the order they were offered.
+//
+// The skip list is the lazy skip list of Herlihy et al.: lookups take no
+// locks at all, and Offer, Get and Remove only lock the few nodes around
+// the element they insert or remove, so they run concurrently as long as
+// they touch different parts of the list.
+//
+// Besides the Queue methods, it offers non-destructive ordered iteration
+// with All and Range, and removal of arbitrary elements with Remove, in
+// O(log n).
+//
+// Iteration and the methods that look at more than one element are not
+// atomic with respect to concurrent changes: they observe every element
+// that stays in the queue during the call, and may or may not observe
+// the others.
+type SkipListPriority[T comparable] struct {
+	head     *slNode[T]
+	lessFunc func(elem, otherElem T) bool
+	seq      atomic.Uint64
+	size     atomic.Int64
+
+	initialElems []T
+	capacity     *int
+}
+
+// NewSkipListPriority returns a new SkipListPriority queue containing the
+// given elements, ordered by lessFunc as in NewPriority.
+//
+// ! If capacity is provided and is less than the number of elements
+// provided, the highest priority elements are kept.
+//
+// Panics if lessFunc is nil or if WithCapacity is negative.
+func NewSkipListPriority[T comparable](
+	elems []T,
+	lessFunc func(elem, otherElem T) bool,
+	opts ...Option,
+) *SkipListPriority[T] {
+	if lessFunc == nil {
+		panic("nil less func")
+	}
+
+	options := options{
+		capacity: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	if options.capacity != nil && *options.capacity < len(initialElems) {
+		sort.SliceStable(initialElems, func(i, j int) bool {
+			return lessFunc(initialElems[i], initialElems[j])
+		})
+
+		initialElems = initialElems[:*options.capacity]
+	}
+
+	sl := &SkipListPriority[T]{
+		head:         &slNode[T]{next: make([]atomic.Pointer[slNode[T]], skipListMaxLevel)},
+		lessFunc:     lessFunc,
+		initialElems: initialElems,
+		capacity:     options.capacity,
+	}
+
+	for _, e := range initialElems {
+		_ = sl.Offer(e)
+	}
+
+	return sl
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element into the queue, after the elements equal to
+// it.
+// If the queue is full it returns the ErrQueueIsFull error.
+func (sl *SkipListPriority[T]) Offer(elem T) error {
+	if !sl.reserve() {
+		return ErrQueueIsFull
+	}
+
+	n := &slNode[T]{
+		value: elem,
+		seq:   sl.seq.Add(1),
+		next:  make([]atomic.Pointer[slNode[T]], randomSkipListLevel()),
+	}
+
+	for !sl.link(n) {
+		// A neighbour changed between the search and the locking; search
+		// again.
+	}
+
+	return nil
+}
+
+// Reset sets the queue to its initial state with the original elements,
+// by draining it and offering the original elements again.
+func (sl *SkipListPriority[T]) Reset() {
+	_ = sl.Clear()
+
+	for _, e := range sl.initialElems {
+		_ = sl.Offer(e)
+	}
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (sl *SkipListPriority[T]) Get() (v T, _ error) {
+	// The first node may be claimed by a concurrent Get; move on to the
+	// next one then.
+	for n := sl.head.next[0].Load(); n != nil; n = n.next[0].Load() {
+		if sl.remove(n) {
+			return n.value, nil
+		}
+	}
+
+	return v, ErrNoElementsAvailable
+}
+
+// Remove removes one occurrence of the element from the queue, the
+// earliest offered one, and reports whether it found one. It runs in
+// O(log n), plus the number of elements equal to it by lessFunc.
+func (sl *SkipListPriority[T]) Remove(elem T) bool {
+	for n := sl.seek(elem); n != nil && !sl.lessFunc(elem, n.value); n = n.next[0].Load() {
+		if n.value == elem && sl.remove(n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Clear removes and returns all elements from the queue, in priority
+// order.
+func (sl *SkipListPriority[T]) Clear() []T {
+	removed := make([]T, 0, sl.Size())
+
+	for {
+		elem, err := sl.Get()
+		if err != nil {
+			return removed
+		}
+
+		removed = append(removed, elem)
+	}
+}
+
+// Iterator returns an iterator over the elements in the queue, in
+// priority order.
+// It removes the elements from the queue.
+func (sl *SkipListPriority[T]) Iterator() <-chan T {
+	elems := sl.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// All returns an iterator over the elements in the queue, in priority
+// order, without removing them. Iteration stops when yield returns false.
+// With Go 1.23 or later, the result can be ranged over directly.
+func (sl *SkipListPriority[T]) All() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for n := sl.head.next[0].Load(); n != nil; n = n.next[0].Load() {
+			if n.live() && !yield(n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the elements e in the queue with
+// from <= e < to by lessFunc, in priority order, without removing them.
+// Finding the first element takes O(log n).
+func (sl *SkipListPriority[T]) Range(from, to T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for n := sl.seek(from); n != nil && sl.lessFunc(n.value, to); n = n.next[0].Load() {
+			if n.live() && !yield(n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Peek retrieves but does not remove the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (sl *SkipListPriority[T]) Peek() (v T, _ error) {
+	elems := sl.elems(1)
+
+	if len(elems) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return elems[0], nil
+}
+
+// Size returns the number of elements in the queue. While other
+// goroutines change the queue it is an approximation.
+func (sl *SkipListPriority[T]) Size() int {
+	if n := sl.size.Load(); n > 0 {
+		return int(n)
+	}
+
+	return 0
+}
+
+// IsEmpty returns true if the queue is empty.
+func (sl *SkipListPriority[T]) IsEmpty() bool {
+	return len(sl.elems(1)) == 0
+}
+
+// Contains returns true if the queue contains the given element. It runs
+// in O(log n), plus the number of elements equal to it by lessFunc.
+func (sl *SkipListPriority[T]) Contains(elem T) bool {
+	for n := sl.seek(elem); n != nil && !sl.lessFunc(elem, n.value); n = n.next[0].Load() {
+		if n.value == elem && n.live() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the SkipListPriority queue to JSON in priority
+// order.
+func (sl *SkipListPriority[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sl.elems(-1))
+}
+
+// ===================================Helpers==================================
+
+// less orders nodes by value, then by offer order.
+func (sl *SkipListPriority[T]) less(n, other *slNode[T]) bool {
+	if sl.lessFunc(n.value, other.value) {
+		return true
+	}
+
+	return !sl.lessFunc(other.value, n.value) && n.seq < other.seq
+}
+
+// find returns the neighbours of n on every level.
+func (sl *SkipListPriority[T]) find(n *slNode[T]) *slPath[T] {
+	path := &slPath[T]{}
+	pred := sl.head
+
+	for level := skipListMaxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+
+		for curr != nil && sl.less(curr, n) {
+			pred, curr = curr, curr.next[level].Load()
+		}
+
+		path.preds[level], path.succs[level] = pred, curr
+	}
+
+	return path
+}
+
+// seek returns the first node, live or not, whose value is not less than
+// elem, or nil.
+func (sl *SkipListPriority[T]) seek(elem T) *slNode[T] {
+	pred := sl.head
+
+	for level := skipListMaxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+
+		for curr != nil && sl.lessFunc(curr.value, elem) {
+			pred, curr = curr, curr.next[level].Load()
+		}
+	}
+
+	return pred.next[0].Load()
+}
+
+// link inserts n between its neighbours and reports whether it did; it
+// fails if the neighbours changed since they were looked up.
+func (sl *SkipListPriority[T]) link(n *slNode[T]) bool {
+	path := sl.find(n)
+	top := len(n.next) - 1
+
+	valid := lockPath(path, top, func(level int, pred, succ *slNode[T]) bool {
+		return (succ == nil || !succ.marked.Load()) && pred.next[level].Load() == succ
+	})
+
+	if valid {
+		for level := 0; level <= top; level++ {
+			n.next[level].Store(path.succs[level])
+		}
+
+		for level := 0; level <= top; level++ {
+			path.preds[level].next[level].Store(n)
+		}
+
+		n.fullyLinked.Store(true)
+	}
+
+	unlockPath(path, top)
+
+	return valid
+}
+
+// remove takes n out of the queue and reports whether it did; it fails if
+// n is not in the queue, because it is not fully linked yet or another
+// goroutine removed it first.
+func (sl *SkipListPriority[T]) remove(n *slNode[T]) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	ok := n.live()
+
+	if ok {
+		// Marking n logically removes it and, under its lock, keeps
+		// Offer from linking new nodes after it.
+		n.marked.Store(true)
+
+		for !sl.unlink(n) {
+			// A predecessor changed; search again.
+		}
+
+		sl.release()
+	}
+
+	return ok
+}
+
+// unlink physically removes the marked node n from every level and
+// reports whether it did; it fails if the predecessors of n changed
+// since they were looked up. Caller must hold the lock of n.
+func (sl *SkipListPriority[T]) unlink(n *slNode[T]) bool {
+	path := sl.find(n)
+	top := len(n.next) - 1
+
+	valid := lockPath(path, top, func(level int, pred, _ *slNode[T]) bool {
+		return pred.next[level].Load() == n
+	})
+
+	if valid {
+		for level := top; level >= 0; level-- {
+			path.preds[level].next[level].Store(n.next[level].Load())
+		}
+	}
+
+	unlockPath(path, top)
+
+	return valid
+}
+
+// reserve counts a new element against the capacity, and reports whether
+// there was room for it.
+func (sl *SkipListPriority[T]) reserve() bool {
+	for {
+		size := sl.size.Load()
+
+		if sl.capacity != nil && size >= int64(*sl.capacity) {
+			return false
+		}
+
+		if sl.size.CompareAndSwap(size, size+1) {
+			return true
+		}
+	}
+}
+
+// release uncounts a removed element.
+func (sl *SkipListPriority[T]) release() {
+	sl.size.Add(-1)
+}
+
+// elems returns up to limit elements of the queue in priority order, or
+// all of them if limit is negative.
+func (sl *SkipListPriority[T]) elems(limit int) []T {
+	output := []T{}
+
+	sl.All()(func(elem T) bool {
+		output = append(output, elem)
+
+		return len(output) != limit
+	})
+
+	return output
+}
+
+// lockPath locks the predecessors on levels 0 to top of path, from the
+// bottom up, and reports whether they are all unmarked and pass valid.
+// Predecessors are locked from the last to the first in list order, like
+// everywhere else, which rules out deadlocks.
+func lockPath[T any](
+	path *slPath[T],
+	top int,
+	valid func(level int, pred, succ *slNode[T]) bool,
+) bool {
+	ok := true
+
+	for level := 0; level <= top; level++ {
+		pred := path.preds[level]
+
+		if level == 0 || pred != path.preds[level-1] {
+			pred.lock.Lock()
+		}
+
+		ok = ok && !pred.marked.Load() && valid(level, pred, path.succs[level])
+	}
+
+	return ok
+}
+
+// unlockPath unlocks the predecessors locked by lockPath.
+func unlockPath[T any](path *slPath[T], top int) {
+	for level := 0; level <= top; level++ {
+		if level == 0 || path.preds[level] != path.preds[level-1] {
+			path.preds[level].lock.Unlock()
+		}
+	}
+}
+
+// randomSkipListLevel returns the number of levels of a new node: one,
+// plus one with probability 1/2 for every further level.
+func randomSkipListLevel() int {
+	return 1 + bits.TrailingZeros64(rand.Uint64()|1<<(skipListMaxLevel-1)) //nolint:gosec // level choice only
+}
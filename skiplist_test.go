@@ -0,0 +1,375 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestSkipListPriority(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NilLessFunc", testSkipListPriorityNilLessFunc)
+	t.Run("NegativeCapacity", testSkipListPriorityNegativeCapacity)
+	t.Run("PriorityOrder", testSkipListPriorityPriorityOrder)
+	t.Run("EqualElementsKeepOfferOrder", testSkipListPriorityEqualElementsKeepOfferOrder)
+	t.Run("Capacity", testSkipListPriorityCapacity)
+	t.Run("Empty", testSkipListPriorityEmpty)
+	t.Run("Remove", testSkipListPriorityRemove)
+	t.Run("All", testSkipListPriorityAll)
+	t.Run("Range", testSkipListPriorityRange)
+	t.Run("SizeAndContains", testSkipListPrioritySizeAndContains)
+	t.Run("ClearAndIterator", testSkipListPriorityClearAndIterator)
+	t.Run("Reset", testSkipListPriorityReset)
+	t.Run("MarshalJSON", testSkipListPriorityMarshalJSON)
+	t.Run("Concurrent", testSkipListPriorityConcurrent)
+}
+
+// collect gathers the elements yielded by a SkipListPriority iterator.
+func collect(seq func(yield func(int) bool)) []int {
+	elems := []int{}
+
+	seq(func(elem int) bool {
+		elems = append(elems, elem)
+
+		return true
+	})
+
+	return elems
+}
+
+func testSkipListPriorityNilLessFunc(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "nil less func" {
+			t.Fatalf("expected panic 'nil less func', got %v", p)
+		}
+	}()
+
+	_ = queue.NewSkipListPriority[int](nil, nil)
+}
+
+func testSkipListPriorityNegativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != negativeCapacityPanic {
+			t.Fatalf("expected panic %q, got %v", negativeCapacityPanic, p)
+		}
+	}()
+
+	_ = queue.NewSkipListPriority[int](nil, lessInt, queue.WithCapacity(-1))
+}
+
+func testSkipListPriorityPriorityOrder(t *testing.T) {
+	t.Parallel()
+
+	skipList := queue.NewSkipListPriority([]int{5, 1, 4}, lessInt)
+
+	_ = skipList.Offer(3)
+	_ = skipList.Offer(2)
+
+	if got, _ := skipList.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	for want := 1; want <= 5; want++ {
+		got, err := skipList.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testSkipListPriorityEqualElementsKeepOfferOrder(t *testing.T) {
+	t.Parallel()
+
+	type task struct {
+		priority int
+		name     string
+	}
+
+	skipList := queue.NewSkipListPriority[task](nil, func(elem, otherElem task) bool {
+		return elem.priority < otherElem.priority
+	})
+
+	for _, tk := range []task{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}} {
+		_ = skipList.Offer(tk)
+	}
+
+	var got []string
+
+	for e := range skipList.Iterator() {
+		got = append(got, e.name)
+	}
+
+	if !reflect.DeepEqual(got, []string{"b", "d", "a", "c"}) {
+		t.Fatalf("expected [b d a c], got %v", got)
+	}
+}
+
+func testSkipListPriorityCapacity(t *testing.T) {
+	t.Parallel()
+
+	// The highest priority elements are kept.
+	skipList := queue.NewSkipListPriority([]int{4, 1, 3, 2}, lessInt, queue.WithCapacity(2))
+
+	if err := skipList.Offer(0); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+
+	if got := collect(skipList.All()); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+
+	_, _ = skipList.Get()
+
+	if err := skipList.Offer(0); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+}
+
+func testSkipListPriorityEmpty(t *testing.T) {
+	t.Parallel()
+
+	skipList := queue.NewSkipListPriority[int](nil, lessInt)
+
+	if _, err := skipList.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := skipList.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !skipList.IsEmpty() || skipList.Size() != 0 {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testSkipListPriorityRemove(t *testing.T) {
+	t.Parallel()
+
+	skipList := queue.NewSkipListPriority([]int{1, 2, 3, 2, 4}, lessInt)
+
+	if !skipList.Remove(2) {
+		t.Fatal("expected 2 to be removed")
+	}
+
+	if got := collect(skipList.All()); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Fatalf("expected [1 2 3 4], got %v", got)
+	}
+
+	if !skipList.Remove(2) || skipList.Remove(2) {
+		t.Fatal("expected exactly one more 2 to be removed")
+	}
+
+	if skipList.Remove(0) || skipList.Remove(5) {
+		t.Fatal("expected missing elements not to be removed")
+	}
+
+	if skipList.Size() != 3 {
+		t.Fatalf("expected size 3, got %d", skipList.Size())
+	}
+}
+
+func testSkipListPriorityAll(t *testing.T) {
+	t.Parallel()
+
+	skipList := queue.NewSkipListPriority([]int{3, 1, 2}, lessInt)
+
+	if got := collect(skipList.All()); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	// Iteration stops as soon as yield returns false.
+	var first []int
+
+	skipList.All()(func(elem int) bool {
+		first = append(first, elem)
+
+		return false
+	})
+
+	if !reflect.DeepEqual(first, []int{1}) {
+		t.Fatalf("expected [1], got %v", first)
+	}
+
+	if skipList.Size() != 3 {
+		t.Fatalf("expected All not to remove elements, size %d", skipList.Size())
+	}
+}
+
+func testSkipListPriorityRange(t *testing.T) {
+	t.Parallel()
+
+	elems := make([]int, 0, 100)
+	for i := 99; i >= 0; i-- {
+		elems = append(elems, i)
+	}
+
+	skipList := queue.NewSkipListPriority(elems, lessInt)
+
+	if got := collect(skipList.Range(40, 45)); !reflect.DeepEqual(got, []int{40, 41, 42, 43, 44}) {
+		t.Fatalf("expected [40 41 42 43 44], got %v", got)
+	}
+
+	if got := collect(skipList.Range(200, 300)); len(got) != 0 {
+		t.Fatalf("expected an empty range, got %v", got)
+	}
+
+	var first []int
+
+	skipList.Range(10, 20)(func(elem int) bool {
+		first = append(first, elem)
+
+		return len(first) < 2
+	})
+
+	if !reflect.DeepEqual(first, []int{10, 11}) {
+		t.Fatalf("expected [10 11], got %v", first)
+	}
+}
+
+func testSkipListPrioritySizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	skipList := queue.NewSkipListPriority([]int{1, 2, 3}, lessInt)
+
+	if skipList.Size() != 3 || skipList.IsEmpty() {
+		t.Fatalf("expected size 3, got %d", skipList.Size())
+	}
+
+	if !skipList.Contains(2) || skipList.Contains(4) || skipList.Contains(0) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testSkipListPriorityClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	skipList := queue.NewSkipListPriority([]int{3, 1, 2}, lessInt)
+
+	if got := skipList.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	_ = skipList.Offer(5)
+	_ = skipList.Offer(4)
+
+	var got []int
+
+	for e := range skipList.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{4, 5}) {
+		t.Fatalf("expected [4 5], got %v", got)
+	}
+}
+
+func testSkipListPriorityReset(t *testing.T) {
+	t.Parallel()
+
+	elems := []int{3, 1, 2}
+
+	skipList := queue.NewSkipListPriority(elems, lessInt)
+
+	elems[0] = 100
+
+	_, _ = skipList.Get()
+	_ = skipList.Offer(4)
+
+	skipList.Reset()
+
+	if got := skipList.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+}
+
+func testSkipListPriorityMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewSkipListPriority([]int{3, 1, 2}, lessInt).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2,3]" {
+		t.Fatalf("expected [1,2,3], got %s", data)
+	}
+}
+
+func testSkipListPriorityConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goroutines   = 8
+		perGoroutine = 1_000
+	)
+
+	skipList := queue.NewSkipListPriority[int](nil, lessInt)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		received []int
+	)
+
+	wg.Add(goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			var local []int
+
+			for i := 0; i < perGoroutine; i++ {
+				elem := g*perGoroutine + i
+
+				_ = skipList.Offer(elem)
+
+				// Remove every fourth element again; take the others
+				// with Get.
+				if i%4 == 0 && skipList.Remove(elem) {
+					local = append(local, elem)
+
+					continue
+				}
+
+				if e, err := skipList.Get(); err == nil {
+					local = append(local, e)
+				}
+			}
+
+			mu.Lock()
+			received = append(received, local...)
+			mu.Unlock()
+		}(g)
+	}
+
+	wg.Wait()
+
+	received = append(received, skipList.Clear()...)
+
+	sort.Ints(received)
+
+	for i, e := range received {
+		if e != i {
+			t.Fatalf("element %d missing or duplicated, got %d", i, e)
+		}
+	}
+
+	if skipList.Size() != 0 {
+		t.Fatalf("expected size 0, got %d", skipList.Size())
+	}
+}
@@ -0,0 +1,98 @@
+package queue
+
+import (
+	"encoding/json"
+)
+
+// Snapshot is an immutable, point-in-time view of the elements of a
+// Blocking, Linked or Circular queue, returned by their Snapshot method.
+//
+// Taking a snapshot is O(1): it shares the queue's storage instead of
+// copying it. The queue copies that storage the next time it would
+// overwrite part of it, so the snapshot never changes, and reading it
+// never takes the queue lock. A Snapshot is safe for concurrent use by
+// multiple goroutines.
+type Snapshot[T comparable] struct {
+	// segments are shared slices of a slice-backed queue, in order.
+	segments [][]T
+	// head is the first of nodes shared nodes of a Linked queue. Only the
+	// first nodes-1 next pointers may be followed: the next pointer of
+	// the last one is written by the queue's later Offer calls.
+	head  *node[T]
+	nodes int
+	size  int
+}
+
+// Len returns the number of elements in the snapshot.
+func (s *Snapshot[T]) Len() int {
+	return s.size
+}
+
+// All returns an iterator over the elements in the snapshot, from head to
+// tail. Iteration stops when yield returns false.
+// With Go 1.23 or later, the result can be ranged over directly.
+func (s *Snapshot[T]) All() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for _, segment := range s.segments {
+			for _, elem := range segment {
+				if !yield(elem) {
+					return
+				}
+			}
+		}
+
+		n := s.head
+
+		for i := 0; i < s.nodes; i++ {
+			if i > 0 {
+				n = n.next
+			}
+
+			if !yield(n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Elems returns the elements in the snapshot, from head to tail.
+func (s *Snapshot[T]) Elems() []T {
+	elems := make([]T, 0, s.size)
+
+	s.All()(func(elem T) bool {
+		elems = append(elems, elem)
+
+		return true
+	})
+
+	return elems
+}
+
+// Contains returns true if the snapshot contains the given element.
+func (s *Snapshot[T]) Contains(elem T) bool {
+	found := false
+
+	s.All()(func(e T) bool {
+		found = e == elem
+
+		return !found
+	})
+
+	return found
+}
+
+// MarshalJSON serializes the snapshot to JSON.
+func (s *Snapshot[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Elems())
+}
+
+// sliceSnapshot returns a snapshot sharing the given segments.
+func sliceSnapshot[T comparable](segments ...[]T) *Snapshot[T] {
+	size := 0
+
+	for _, segment := range segments {
+		size += len(segment)
+	}
+
+	return &Snapshot[T]{segments: segments, size: size}
+}
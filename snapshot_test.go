@@ -0,0 +1,292 @@
+package queue_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+// snapshotQueue is a queue that hands out copy-on-write snapshots.
+type snapshotQueue interface {
+	queue.Queue[int]
+	Snapshot() *queue.Snapshot[int]
+}
+
+// snapshotQueues returns a constructor for every queue with a Snapshot
+// method. The queues are unbounded or large enough for the tests.
+func snapshotQueues() map[string]func(elems []int) snapshotQueue {
+	return map[string]func(elems []int) snapshotQueue{
+		"Blocking": func(elems []int) snapshotQueue { return queue.NewBlocking(elems) },
+		"Linked":   func(elems []int) snapshotQueue { return queue.NewLinked(elems) },
+		"Circular": func(elems []int) snapshotQueue { return queue.NewCircular(elems, 10) },
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PointInTime", testSnapshotPointInTime)
+	t.Run("ResetAndClear", testSnapshotResetAndClear)
+	t.Run("Empty", testSnapshotEmpty)
+	t.Run("StopIteration", testSnapshotStopIteration)
+	t.Run("MarshalJSON", testSnapshotMarshalJSON)
+	t.Run("BlockingGetWait", testSnapshotBlockingGetWait)
+	t.Run("CircularWrapped", testSnapshotCircularWrapped)
+	t.Run("CircularOverwrite", testSnapshotCircularOverwrite)
+	t.Run("ConcurrentReaders", testSnapshotConcurrentReaders)
+}
+
+func testSnapshotPointInTime(t *testing.T) {
+	t.Parallel()
+
+	for name, newQueue := range snapshotQueues() {
+		newQueue := newQueue
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			q := newQueue([]int{1, 2, 3})
+
+			snap := q.Snapshot()
+
+			if err := q.Offer(4); err != nil {
+				t.Fatalf("offer: %v", err)
+			}
+
+			if got, _ := q.Get(); got != 1 {
+				t.Fatalf("get: got %d want 1", got)
+			}
+
+			again := q.Snapshot()
+
+			if got, _ := q.Get(); got != 2 {
+				t.Fatalf("get: got %d want 2", got)
+			}
+
+			q.Reset()
+			_ = q.Offer(5)
+
+			if got := q.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3, 5}) {
+				t.Fatalf("clear: got %v want [1 2 3 5]", got)
+			}
+
+			if got := snap.Elems(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+				t.Fatalf("snapshot: got %v want [1 2 3]", got)
+			}
+
+			if got := again.Elems(); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+				t.Fatalf("second snapshot: got %v want [2 3 4]", got)
+			}
+
+			if snap.Len() != 3 || !snap.Contains(3) || snap.Contains(4) {
+				t.Fatalf("snapshot: unexpected Len %d or Contains", snap.Len())
+			}
+
+			// The queue keeps working normally once it is no longer shared.
+			_ = q.Offer(6)
+			_ = q.Offer(7)
+
+			if got, _ := q.Get(); got != 6 {
+				t.Fatalf("get: got %d want 6", got)
+			}
+
+			if got := q.Snapshot().Elems(); !reflect.DeepEqual(got, []int{7}) {
+				t.Fatalf("snapshot: got %v want [7]", got)
+			}
+		})
+	}
+}
+
+func testSnapshotResetAndClear(t *testing.T) {
+	t.Parallel()
+
+	for name, newQueue := range snapshotQueues() {
+		newQueue := newQueue
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			q := newQueue([]int{1, 2})
+			_ = q.Offer(3)
+
+			beforeReset := q.Snapshot()
+
+			q.Reset()
+
+			beforeClear := q.Snapshot()
+
+			if got := q.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+				t.Fatalf("clear: got %v want [1 2]", got)
+			}
+
+			_ = q.Offer(4)
+
+			if got := beforeReset.Elems(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+				t.Fatalf("snapshot before reset: got %v want [1 2 3]", got)
+			}
+
+			if got := beforeClear.Elems(); !reflect.DeepEqual(got, []int{1, 2}) {
+				t.Fatalf("snapshot before clear: got %v want [1 2]", got)
+			}
+		})
+	}
+}
+
+func testSnapshotEmpty(t *testing.T) {
+	t.Parallel()
+
+	for name, newQueue := range snapshotQueues() {
+		newQueue := newQueue
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			snap := newQueue(nil).Snapshot()
+
+			if snap.Len() != 0 || snap.Contains(0) {
+				t.Fatalf("expected an empty snapshot, got %v", snap.Elems())
+			}
+		})
+	}
+}
+
+func testSnapshotStopIteration(t *testing.T) {
+	t.Parallel()
+
+	for name, newQueue := range snapshotQueues() {
+		newQueue := newQueue
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			snap := newQueue([]int{1, 2, 3}).Snapshot()
+
+			if !snap.Contains(1) {
+				t.Fatal("expected the snapshot to contain 1")
+			}
+
+			var got []int
+
+			snap.All()(func(elem int) bool {
+				got = append(got, elem)
+
+				return elem < 2
+			})
+
+			if !reflect.DeepEqual(got, []int{1, 2}) {
+				t.Fatalf("got %v want [1 2]", got)
+			}
+		})
+	}
+}
+
+func testSnapshotMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	snap := queue.NewLinked([]int{1, 2}).Snapshot()
+
+	marshaled, err := snap.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(marshaled) != "[1,2]" {
+		t.Fatalf("got %s want [1,2]", marshaled)
+	}
+}
+
+func testSnapshotBlockingGetWait(t *testing.T) {
+	t.Parallel()
+
+	blocking := queue.NewBlocking([]int{1, 2})
+
+	snap := blocking.Snapshot()
+
+	if got := blocking.GetWait(); got != 1 {
+		t.Fatalf("get: got %d want 1", got)
+	}
+
+	if got := snap.Elems(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("snapshot: got %v want [1 2]", got)
+	}
+}
+
+func testSnapshotCircularWrapped(t *testing.T) {
+	t.Parallel()
+
+	circular := queue.NewCircular([]int{1, 2, 3}, 3)
+
+	_, _ = circular.Get()
+	_ = circular.Offer(4)
+
+	snap := circular.Snapshot()
+
+	if got := snap.Elems(); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Fatalf("snapshot: got %v want [2 3 4]", got)
+	}
+}
+
+func testSnapshotCircularOverwrite(t *testing.T) {
+	t.Parallel()
+
+	circular := queue.NewCircular([]int{1, 2, 3}, 3)
+
+	snap := circular.Snapshot()
+
+	// The queue is full: the offer overwrites the oldest element.
+	_ = circular.Offer(4)
+
+	if circular.Contains(1) {
+		t.Fatal("expected 1 to be overwritten")
+	}
+
+	if got := snap.Elems(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("snapshot: got %v want [1 2 3]", got)
+	}
+}
+
+func testSnapshotConcurrentReaders(t *testing.T) {
+	t.Parallel()
+
+	const elems = 100
+
+	for name, newQueue := range snapshotQueues() {
+		newQueue := newQueue
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			initial := make([]int, 10)
+			for i := range initial {
+				initial[i] = i
+			}
+
+			q := newQueue(initial)
+
+			var wg sync.WaitGroup
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for i := 0; i < elems; i++ {
+					_, _ = q.Get()
+					_ = q.Offer(i)
+				}
+			}()
+
+			for i := 0; i < elems; i++ {
+				snap := q.Snapshot()
+
+				if got := len(snap.Elems()); got != snap.Len() {
+					t.Errorf("snapshot: iterated %d elements, Len %d", got, snap.Len())
+				}
+			}
+
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"math/bits"
+	"sync/atomic"
+)
+
+// Ensure SPSC implements the Queue interface.
+var _ Queue[any] = (*SPSC[any])(nil)
+
+// SPSC is a bounded FIFO Queue implementation for exactly one producer
+// goroutine and one consumer goroutine. The ring buffer is coordinated
+// through two atomic indices only: the producer owns the tail and the
+// consumer owns the head, each on its own cache line, so neither side
+// ever waits for the other and no lock is taken.
+//
+// Offer and OfferWait may only be called by the producer. Get, GetWait,
+// Peek, PeekWait, Contains, Clear, Iterator and MarshalJSON may only be
+// called by the consumer. Size and IsEmpty may be called by any
+// goroutine. Reset must not run concurrently with any other method.
+// When built with -race, overlapping calls from two producers or from two
+// consumers panic instead of silently corrupting the queue.
+//
+// OfferWait, GetWait and PeekWait spin briefly and then park the calling
+// goroutine until the other side makes progress.
+type SPSC[T comparable] struct {
+	// Consumer side: head is the next slot to read and tailCache the last
+	// tail the consumer observed, saving a shared load per Get.
+	head      atomic.Uint64
+	tailCache uint64
+	_         [cacheLineSize - 16]byte
+
+	// Producer side: tail is the next slot to write and headCache the
+	// last head the producer observed.
+	tail      atomic.Uint64
+	headCache uint64
+	_         [cacheLineSize - 16]byte
+
+	// buf has a power of two length, at least capacity, so that indices
+	// wrap with mask.
+	buf          []T
+	mask         uint64
+	capacity     uint64
+	initialElems []T
+
+	notEmpty parker // the consumer parks here
+	notFull  parker // the producer parks here
+
+	// producing and consuming detect concurrent calls on the same side
+	// when the race detector is on.
+	producing atomic.Bool
+	consuming atomic.Bool
+}
+
+// NewSPSC returns a new SPSC queue holding at most capacity elements,
+// containing the given elements. Elements beyond the capacity are
+// dropped.
+// Panics if capacity is not positive.
+func NewSPSC[T comparable](elems []T, capacity int) *SPSC[T] {
+	if capacity <= 0 {
+		panic("capacity must be positive")
+	}
+
+	if len(elems) > capacity {
+		elems = elems[:capacity]
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	size := uint64(1) << bits.Len64(uint64(capacity)-1)
+
+	q := &SPSC[T]{
+		buf:          make([]T, size),
+		mask:         size - 1,
+		capacity:     uint64(capacity),
+		initialElems: initialElems,
+	}
+
+	q.notEmpty.init()
+	q.notFull.init()
+
+	q.reset()
+
+	return q
+}
+
+// ==================================Insertion=================================
+
+// Offer inserts the element to the tail of the queue.
+// If the queue is full it returns the ErrQueueIsFull error.
+// Only the producer may call Offer.
+func (q *SPSC[T]) Offer(elem T) error {
+	enterSide(&q.producing, "producer")
+	defer exitSide(&q.producing)
+
+	if !q.offer(elem) {
+		return ErrQueueIsFull
+	}
+
+	return nil
+}
+
+// OfferWait inserts the element to the tail of the queue.
+// It waits for necessary space to become available.
+// Only the producer may call OfferWait.
+func (q *SPSC[T]) OfferWait(elem T) {
+	enterSide(&q.producing, "producer")
+	defer exitSide(&q.producing)
+
+	for !q.offer(elem) {
+		_ = q.notFull.wait(context.Background(), q.hasSpace)
+	}
+}
+
+// Reset sets the queue to its initial state with the original elements.
+// It must not run concurrently with any other method.
+func (q *SPSC[T]) Reset() {
+	enterSide(&q.producing, "producer")
+	defer exitSide(&q.producing)
+
+	enterSide(&q.consuming, "consumer")
+	defer exitSide(&q.consuming)
+
+	q.reset()
+}
+
+// ===================================Removal==================================
+
+// Get removes and returns the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+// Only the consumer may call Get.
+func (q *SPSC[T]) Get() (v T, _ error) {
+	enterSide(&q.consuming, "consumer")
+	defer exitSide(&q.consuming)
+
+	if !q.available() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return q.pop(), nil
+}
+
+// GetWait removes and returns the head of the queue.
+// If no element is available it waits until the queue
+// has an element available.
+// Only the consumer may call GetWait.
+func (q *SPSC[T]) GetWait() T {
+	enterSide(&q.consuming, "consumer")
+	defer exitSide(&q.consuming)
+
+	q.waitAvailable()
+
+	return q.pop()
+}
+
+// Clear removes and returns all elements from the queue.
+// Only the consumer may call Clear.
+func (q *SPSC[T]) Clear() []T {
+	enterSide(&q.consuming, "consumer")
+	defer exitSide(&q.consuming)
+
+	removed := make([]T, 0, q.Size())
+
+	for q.available() {
+		removed = append(removed, q.pop())
+	}
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this queue.
+// It removes the elements from the queue.
+// Only the consumer may call Iterator.
+func (q *SPSC[T]) Iterator() <-chan T {
+	elems := q.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+// Only the consumer may call Peek.
+func (q *SPSC[T]) Peek() (v T, _ error) {
+	enterSide(&q.consuming, "consumer")
+	defer exitSide(&q.consuming)
+
+	if !q.available() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return q.buf[q.head.Load()&q.mask], nil
+}
+
+// PeekWait retrieves but does not remove the head of the queue.
+// If no element is available it waits until the queue
+// has an element available.
+// Only the consumer may call PeekWait.
+func (q *SPSC[T]) PeekWait() T {
+	enterSide(&q.consuming, "consumer")
+	defer exitSide(&q.consuming)
+
+	q.waitAvailable()
+
+	return q.buf[q.head.Load()&q.mask]
+}
+
+// Size returns the number of elements in the queue. While the producer
+// and the consumer run it is a snapshot that may already be stale.
+func (q *SPSC[T]) Size() int {
+	for {
+		tail := q.tail.Load()
+		head := q.head.Load()
+
+		// The consumer cannot move head past a tail that did not change
+		// in the meantime, so the difference is a consistent size.
+		if q.tail.Load() == tail {
+			return int(tail - head)
+		}
+	}
+}
+
+// IsEmpty returns true if the queue is empty.
+func (q *SPSC[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Contains returns true if the queue contains the given element.
+// Only the consumer may call Contains.
+func (q *SPSC[T]) Contains(elem T) bool {
+	enterSide(&q.consuming, "consumer")
+	defer exitSide(&q.consuming)
+
+	for i, tail := q.head.Load(), q.tail.Load(); i != tail; i++ {
+		if q.buf[i&q.mask] == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the SPSC queue to JSON.
+// Only the consumer may call MarshalJSON.
+func (q *SPSC[T]) MarshalJSON() ([]byte, error) {
+	enterSide(&q.consuming, "consumer")
+
+	head, tail := q.head.Load(), q.tail.Load()
+
+	output := make([]T, 0, tail-head)
+	for i := head; i != tail; i++ {
+		output = append(output, q.buf[i&q.mask])
+	}
+
+	exitSide(&q.consuming)
+
+	return json.Marshal(output)
+}
+
+// ===================================Helpers==================================
+
+// offer writes elem at the tail unless the queue is full, and reports
+// whether it did. Producer side.
+func (q *SPSC[T]) offer(elem T) bool {
+	tail := q.tail.Load()
+
+	if tail-q.headCache >= q.capacity {
+		q.headCache = q.head.Load()
+
+		if tail-q.headCache >= q.capacity {
+			return false
+		}
+	}
+
+	q.buf[tail&q.mask] = elem
+
+	// Publishing the new tail makes the slot visible to the consumer.
+	q.tail.Store(tail + 1)
+	q.notEmpty.wake()
+
+	return true
+}
+
+// available reports whether an element can be read. Consumer side.
+func (q *SPSC[T]) available() bool {
+	if q.head.Load() != q.tailCache {
+		return true
+	}
+
+	q.tailCache = q.tail.Load()
+
+	return q.head.Load() != q.tailCache
+}
+
+// waitAvailable blocks until an element can be read. Consumer side.
+func (q *SPSC[T]) waitAvailable() {
+	_ = q.notEmpty.wait(context.Background(), q.available)
+}
+
+// hasSpace reports whether an element can be written. Producer side.
+func (q *SPSC[T]) hasSpace() bool {
+	return q.tail.Load()-q.head.Load() < q.capacity
+}
+
+// pop reads and releases the head slot. An element must be available.
+// Consumer side.
+func (q *SPSC[T]) pop() T {
+	head := q.head.Load()
+	elem := q.buf[head&q.mask]
+
+	// Zero the slot so the buffer no longer references the element; the
+	// producer only writes it again after the head moves past it.
+	var zero T
+
+	q.buf[head&q.mask] = zero
+
+	q.head.Store(head + 1)
+	q.notFull.wake()
+
+	return elem
+}
+
+// reset replaces the elements with the initial ones. Both sides must be
+// idle.
+func (q *SPSC[T]) reset() {
+	var zero T
+
+	for i := range q.buf {
+		q.buf[i] = zero
+	}
+
+	n := uint64(copy(q.buf, q.initialElems))
+
+	q.head.Store(0)
+	q.tail.Store(n)
+	q.headCache = 0
+	q.tailCache = n
+}
+
+// enterSide marks the start of a call on one side of a single-producer
+// or single-consumer queue. With the race detector on, it panics if a
+// call on the same side is already in flight.
+func enterSide(active *atomic.Bool, side string) {
+	if raceEnabled && !active.CompareAndSwap(false, true) {
+		panic("queue: concurrent " + side + "s on a single-" + side + " queue")
+	}
+}
+
+// exitSide marks the end of a call started with enterSide.
+func exitSide(active *atomic.Bool) {
+	if raceEnabled {
+		active.Store(false)
+	}
+}
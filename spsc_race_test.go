@@ -0,0 +1,65 @@
+//go:build race
+
+package queue_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/adrianbrad/queue"
+)
+
+// panicValue calls fn and returns the value it panicked with, or nil.
+func panicValue(fn func()) (p any) {
+	defer func() { p = recover() }()
+
+	fn()
+
+	return nil
+}
+
+func TestSPSCConcurrentProducers(t *testing.T) {
+	t.Parallel()
+
+	const want = "queue: concurrent producers on a single-producer queue"
+
+	spsc := queue.NewSPSC([]int{1}, 1)
+
+	// The first producer stays inside OfferWait until a slot frees up,
+	// unless it is the one to detect the second producer, by entering
+	// OfferWait while an Offer below runs.
+	first := make(chan any, 1)
+
+	go func() {
+		first <- panicValue(func() { spsc.OfferWait(2) })
+	}()
+
+	// The queue is full, so Offer fails without a side effect until the
+	// first producer is inside OfferWait, and then panics.
+	for {
+		select {
+		case p := <-first:
+			if p != want {
+				t.Fatalf("expected panic %q, got %v", want, p)
+			}
+
+			return
+		default:
+		}
+
+		if p := panicValue(func() { _ = spsc.Offer(3) }); p != nil {
+			if p != want {
+				t.Fatalf("expected panic %q, got %v", want, p)
+			}
+
+			// Let the first producer complete.
+			_ = spsc.GetWait()
+
+			<-first
+
+			return
+		}
+
+		runtime.Gosched()
+	}
+}
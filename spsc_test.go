@@ -0,0 +1,328 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestSPSC(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NonPositiveCapacity", testSPSCNonPositiveCapacity)
+	t.Run("FIFO", testSPSCFIFO)
+	t.Run("ExactCapacity", testSPSCExactCapacity)
+	t.Run("Empty", testSPSCEmpty)
+	t.Run("Wraparound", testSPSCWraparound)
+	t.Run("GetWait", testSPSCGetWait)
+	t.Run("PeekWait", testSPSCPeekWait)
+	t.Run("OfferWait", testSPSCOfferWait)
+	t.Run("ProducerConsumer", testSPSCProducerConsumer)
+	t.Run("Contains", testSPSCContains)
+	t.Run("ClearAndIterator", testSPSCClearAndIterator)
+	t.Run("Reset", testSPSCReset)
+	t.Run("MarshalJSON", testSPSCMarshalJSON)
+}
+
+func testSPSCNonPositiveCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != "capacity must be positive" {
+			t.Fatalf("expected panic 'capacity must be positive', got %v", p)
+		}
+	}()
+
+	_ = queue.NewSPSC[int](nil, 0)
+}
+
+func testSPSCFIFO(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC([]int{1, 2}, 4)
+
+	if err := spsc.Offer(3); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if got, _ := spsc.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	for want := 1; want <= 3; want++ {
+		got, err := spsc.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testSPSCExactCapacity(t *testing.T) {
+	t.Parallel()
+
+	// The ring is rounded up to 4 slots, but only 3 may be used.
+	spsc := queue.NewSPSC([]int{1, 2, 3, 4}, 3)
+
+	if spsc.Size() != 3 {
+		t.Fatalf("expected size 3, got %d", spsc.Size())
+	}
+
+	if err := spsc.Offer(5); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+}
+
+func testSPSCEmpty(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC[int](nil, 2)
+
+	if _, err := spsc.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("get: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := spsc.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("peek: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !spsc.IsEmpty() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func testSPSCWraparound(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC[int](nil, 3)
+
+	for i := 0; i < 100; i++ {
+		if err := spsc.Offer(i); err != nil {
+			t.Fatalf("offer %d: %v", i, err)
+		}
+
+		if err := spsc.Offer(i + 1000); err != nil {
+			t.Fatalf("offer %d: %v", i+1000, err)
+		}
+
+		for _, want := range []int{i, i + 1000} {
+			got, err := spsc.Get()
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+
+			if got != want {
+				t.Fatalf("got %d want %d", got, want)
+			}
+		}
+	}
+}
+
+func testSPSCGetWait(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC[int](nil, 2)
+
+	result := make(chan int)
+
+	go func() { result <- spsc.GetWait() }()
+
+	// Give the consumer time to spin and park.
+	time.Sleep(10 * time.Millisecond)
+
+	if err := spsc.Offer(1); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	select {
+	case got := <-result:
+		if got != 1 {
+			t.Fatalf("got %d want 1", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("GetWait did not return after Offer")
+	}
+}
+
+func testSPSCPeekWait(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC[int](nil, 2)
+
+	result := make(chan int)
+
+	go func() { result <- spsc.PeekWait() }()
+
+	time.Sleep(10 * time.Millisecond)
+
+	spsc.OfferWait(1)
+
+	select {
+	case got := <-result:
+		if got != 1 {
+			t.Fatalf("got %d want 1", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PeekWait did not return after OfferWait")
+	}
+
+	if spsc.Size() != 1 {
+		t.Fatalf("expected PeekWait to keep the element, size %d", spsc.Size())
+	}
+}
+
+func testSPSCOfferWait(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC([]int{1}, 1)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		spsc.OfferWait(2)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if got := spsc.GetWait(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("OfferWait did not return after Get freed a slot")
+	}
+
+	if got := spsc.GetWait(); got != 2 {
+		t.Fatalf("got %d want 2", got)
+	}
+}
+
+func testSPSCProducerConsumer(t *testing.T) {
+	t.Parallel()
+
+	const n = 100_000
+
+	spsc := queue.NewSPSC[int](nil, 64)
+
+	go func() {
+		for i := 0; i < n; i++ {
+			spsc.OfferWait(i)
+		}
+	}()
+
+	for want := 0; want < n; want++ {
+		if got := spsc.GetWait(); got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testSPSCContains(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC([]int{1, 2}, 2)
+
+	if !spsc.Contains(2) || spsc.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+}
+
+func testSPSCClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC([]int{1, 2, 3}, 4)
+
+	if got := spsc.Clear(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	_ = spsc.Offer(4)
+
+	var got []int
+
+	for e := range spsc.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{4}) {
+		t.Fatalf("expected [4], got %v", got)
+	}
+}
+
+func testSPSCReset(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC([]int{1, 2}, 2)
+
+	_, _ = spsc.Get()
+	_ = spsc.Offer(3)
+
+	spsc.Reset()
+
+	if got := spsc.Clear(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+}
+
+func testSPSCMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	spsc := queue.NewSPSC([]int{1, 2}, 2)
+
+	data, err := spsc.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2]" {
+		t.Fatalf("expected [1,2], got %s", data)
+	}
+}
+
+// BenchmarkPipeline streams elements from one producer goroutine to one
+// consumer through the bounded FIFO queues.
+func BenchmarkPipeline(b *testing.B) {
+	b.Run("SPSC", func(b *testing.B) {
+		spsc := queue.NewSPSC[int](nil, 1024)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		go func() {
+			for i := 0; i < b.N; i++ {
+				spsc.OfferWait(i)
+			}
+		}()
+
+		for i := 0; i < b.N; i++ {
+			_ = spsc.GetWait()
+		}
+	})
+
+	b.Run("Blocking", func(b *testing.B) {
+		blocking := queue.NewBlocking[int](nil, queue.WithCapacity(1024))
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		go func() {
+			for i := 0; i < b.N; i++ {
+				blocking.OfferWait(i)
+			}
+		}()
+
+		for i := 0; i < b.N; i++ {
+			_ = blocking.GetWait()
+		}
+	})
+}
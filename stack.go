@@ -0,0 +1,295 @@
+package queue
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+var _ Queue[any] = (*Stack[any])(nil)
+
+// Stack is a LIFO Queue implementation: Get and Peek return the most
+// recently offered element.
+//
+// Like Blocking, it supports operations that wait for the stack to have
+// available elements, and wait for a free slot in case it is full.
+// Clear, Iterator and MarshalJSON list the elements top to bottom, the
+// order in which Get would return them.
+type Stack[T comparable] struct {
+	initialElems []T
+	// elems holds the bottom of the stack at index 0 and the top at the
+	// last index, so push and pop are appends and truncations.
+	elems    []T
+	capacity *int
+
+	// synchronization
+	lock         sync.RWMutex
+	notEmptyCond *sync.Cond
+	notFullCond  *sync.Cond
+}
+
+// NewStack returns a new Stack containing the given elements, pushed in
+// order, so that the last element is the top of the stack.
+// Panics if WithCapacity is negative.
+func NewStack[T comparable](
+	elems []T,
+	opts ...Option,
+) *Stack[T] {
+	options := options{
+		capacity: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	// Elements past the capacity would have been rejected by Offer, so
+	// the trailing ones are dropped.
+	if options.capacity != nil && len(elems) > *options.capacity {
+		elems = elems[:*options.capacity]
+	}
+
+	ownedElems := make([]T, len(elems))
+	copy(ownedElems, elems)
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	st := &Stack[T]{
+		elems:        ownedElems,
+		initialElems: initialElems,
+		capacity:     options.capacity,
+	}
+
+	st.notEmptyCond = sync.NewCond(&st.lock)
+	st.notFullCond = sync.NewCond(&st.lock)
+
+	return st
+}
+
+// ==================================Insertion=================================
+
+// OfferWait pushes the element onto the top of the stack.
+// It waits for necessary space to become available.
+func (st *Stack[T]) OfferWait(elem T) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	for st.isFull() {
+		st.notFullCond.Wait()
+	}
+
+	st.elems = append(st.elems, elem)
+
+	st.notEmptyCond.Broadcast()
+}
+
+// Offer pushes the element onto the top of the stack.
+// If the stack is full it returns the ErrQueueIsFull error.
+func (st *Stack[T]) Offer(elem T) error {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	if st.isFull() {
+		return ErrQueueIsFull
+	}
+
+	st.elems = append(st.elems, elem)
+
+	st.notEmptyCond.Broadcast()
+
+	return nil
+}
+
+// Reset sets the stack to its initial state with the original elements.
+func (st *Stack[T]) Reset() {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	st.elems = make([]T, len(st.initialElems))
+	copy(st.elems, st.initialElems)
+
+	st.notEmptyCond.Broadcast()
+	st.notFullCond.Broadcast()
+}
+
+// ===================================Removal==================================
+
+// GetWait removes and returns the top of the stack.
+// If no element is available it waits until the stack
+// has an element available.
+func (st *Stack[T]) GetWait() T {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	for st.isEmpty() {
+		st.notEmptyCond.Wait()
+	}
+
+	return st.pop()
+}
+
+// Get removes and returns the top of the stack.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (st *Stack[T]) Get() (v T, _ error) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	if st.isEmpty() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return st.pop(), nil
+}
+
+// Clear removes and returns all elements from the stack, top to bottom.
+func (st *Stack[T]) Clear() []T {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	defer st.notFullCond.Broadcast()
+
+	removed := st.topDown()
+
+	// Drop references into the backing array so popped elements can be
+	// GC'd while the stack outlives them.
+	var zero T
+	for i := range st.elems {
+		st.elems[i] = zero
+	}
+
+	st.elems = st.elems[:0]
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this stack, top to
+// bottom. It removes the elements from the stack.
+func (st *Stack[T]) Iterator() <-chan T {
+	elems := st.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek retrieves but does not remove the top of the stack.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (st *Stack[T]) Peek() (v T, _ error) {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	if st.isEmpty() {
+		return v, ErrNoElementsAvailable
+	}
+
+	return st.elems[len(st.elems)-1], nil
+}
+
+// PeekWait retrieves but does not remove the top of the stack.
+// If no element is available it waits until the stack
+// has an element available.
+func (st *Stack[T]) PeekWait() T {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	for st.isEmpty() {
+		st.notEmptyCond.Wait()
+	}
+
+	return st.elems[len(st.elems)-1]
+}
+
+// Size returns the number of elements in the stack.
+func (st *Stack[T]) Size() int {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	return len(st.elems)
+}
+
+// Contains returns true if the stack contains the given element.
+func (st *Stack[T]) Contains(elem T) bool {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	for _, e := range st.elems {
+		if e == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEmpty returns true if the stack is empty.
+func (st *Stack[T]) IsEmpty() bool {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	return st.isEmpty()
+}
+
+// ===================================Helpers==================================
+
+// isEmpty returns true if the stack is empty.
+func (st *Stack[T]) isEmpty() bool {
+	return len(st.elems) == 0
+}
+
+// isFull returns true if the stack is full.
+func (st *Stack[T]) isFull() bool {
+	if st.capacity == nil {
+		return false
+	}
+
+	return len(st.elems) >= *st.capacity
+}
+
+// pop removes and returns the top element. The stack must be non-empty
+// and the caller must hold the lock.
+func (st *Stack[T]) pop() T {
+	n := len(st.elems) - 1
+	elem := st.elems[n]
+
+	// Zero the popped slot so the backing array no longer references the
+	// popped element.
+	var zero T
+
+	st.elems[n] = zero
+	st.elems = st.elems[:n]
+
+	st.notFullCond.Broadcast()
+
+	return elem
+}
+
+// topDown returns a copy of the elements, top to bottom.
+func (st *Stack[T]) topDown() []T {
+	out := make([]T, len(st.elems))
+
+	for i := range st.elems {
+		out[len(out)-1-i] = st.elems[i]
+	}
+
+	return out
+}
+
+// MarshalJSON serializes the Stack to JSON, top to bottom.
+func (st *Stack[T]) MarshalJSON() ([]byte, error) {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	return json.Marshal(st.topDown())
+}
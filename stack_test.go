@@ -0,0 +1,307 @@
+package queue_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestStack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NegativeCapacity", testStackNegativeCapacity)
+	t.Run("Get", testStackGet)
+	t.Run("GetWait", testStackGetWait)
+	t.Run("Offer", testStackOffer)
+	t.Run("OfferWait", testStackOfferWait)
+	t.Run("Peek", testStackPeek)
+	t.Run("PeekWait", testStackPeekWait)
+	t.Run("SizeAndContains", testStackSizeAndContains)
+	t.Run("Clear", testStackClear)
+	t.Run("Iterator", testStackIterator)
+	t.Run("Reset", testStackReset)
+	t.Run("MarshalJSON", testStackMarshalJSON)
+	t.Run("CapacityLesserThanLenElems", testStackCapacityLesserThanLenElems)
+	t.Run("NewDoesNotAliasCallerSlice", testStackNewDoesNotAliasCallerSlice)
+}
+
+func testStackNegativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != negativeCapacityPanic {
+			t.Fatalf("expected panic %q, got %v", negativeCapacityPanic, p)
+		}
+	}()
+
+	_ = queue.NewStack[int](nil, queue.WithCapacity(-1))
+}
+
+func testStackGet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("LIFO", func(t *testing.T) {
+		t.Parallel()
+
+		stack := queue.NewStack([]int{1, 2})
+
+		if err := stack.Offer(3); err != nil {
+			t.Fatalf("offer: %v", err)
+		}
+
+		for _, want := range []int{3, 2, 1} {
+			got, err := stack.Get()
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+
+			if got != want {
+				t.Fatalf("got %d want %d", got, want)
+			}
+		}
+	})
+
+	t.Run("ErrNoElementsAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		stack := queue.NewStack[int](nil)
+
+		if _, err := stack.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+		}
+	})
+}
+
+func testStackGetWait(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack[int](nil)
+
+	result := make(chan int)
+
+	go func() {
+		result <- stack.GetWait()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := stack.Offer(1); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	select {
+	case got := <-result:
+		if got != 1 {
+			t.Fatalf("got %d want 1", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("GetWait did not return after Offer")
+	}
+}
+
+func testStackOffer(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1}, queue.WithCapacity(1))
+
+	if err := stack.Offer(2); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+}
+
+func testStackOfferWait(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1}, queue.WithCapacity(1))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		stack.OfferWait(2)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if got := stack.GetWait(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("OfferWait did not return after Get freed a slot")
+	}
+
+	if got, _ := stack.Peek(); got != 2 {
+		t.Fatalf("got %d want 2", got)
+	}
+}
+
+func testStackPeek(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1, 2})
+
+	got, err := stack.Peek()
+	if err != nil {
+		t.Fatalf("peek: %v", err)
+	}
+
+	if got != 2 {
+		t.Fatalf("got %d want 2", got)
+	}
+
+	if stack.Size() != 2 {
+		t.Fatalf("expected peek to keep the element, size %d", stack.Size())
+	}
+
+	_ = stack.Clear()
+
+	if _, err := stack.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+
+func testStackPeekWait(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack[int](nil)
+
+	result := make(chan int)
+
+	go func() {
+		result <- stack.PeekWait()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	stack.OfferWait(1)
+
+	select {
+	case got := <-result:
+		if got != 1 {
+			t.Fatalf("got %d want 1", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PeekWait did not return after OfferWait")
+	}
+
+	if stack.IsEmpty() {
+		t.Fatal("expected PeekWait to keep the element")
+	}
+}
+
+func testStackSizeAndContains(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1, 2})
+
+	if !stack.Contains(1) || stack.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+
+	if stack.Size() != 2 || stack.IsEmpty() {
+		t.Fatalf("expected size 2, got %d", stack.Size())
+	}
+}
+
+func testStackClear(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1, 2, 3})
+
+	if got := stack.Clear(); !reflect.DeepEqual(got, []int{3, 2, 1}) {
+		t.Fatalf("expected [3 2 1], got %v", got)
+	}
+
+	if !stack.IsEmpty() {
+		t.Fatal("expected empty stack after clear")
+	}
+}
+
+func testStackIterator(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1, 2, 3})
+
+	var got []int
+
+	for e := range stack.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{3, 2, 1}) {
+		t.Fatalf("expected [3 2 1], got %v", got)
+	}
+}
+
+func testStackReset(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1, 2})
+
+	if err := stack.Offer(3); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	_, _ = stack.Get()
+	_, _ = stack.Get()
+
+	stack.Reset()
+
+	if got := stack.Clear(); !reflect.DeepEqual(got, []int{2, 1}) {
+		t.Fatalf("expected [2 1], got %v", got)
+	}
+}
+
+func testStackMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1, 2, 3})
+
+	data, err := stack.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[3,2,1]" {
+		t.Fatalf("expected [3,2,1], got %s", data)
+	}
+
+	data, err = queue.NewStack[int](nil).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[]" {
+		t.Fatalf("expected [], got %s", data)
+	}
+}
+
+func testStackCapacityLesserThanLenElems(t *testing.T) {
+	t.Parallel()
+
+	stack := queue.NewStack([]int{1, 2, 3}, queue.WithCapacity(2))
+
+	if got := stack.Clear(); !reflect.DeepEqual(got, []int{2, 1}) {
+		t.Fatalf("expected [2 1], got %v", got)
+	}
+}
+
+func testStackNewDoesNotAliasCallerSlice(t *testing.T) {
+	t.Parallel()
+
+	elems := []int{1, 2}
+	stack := queue.NewStack(elems)
+
+	elems[1] = 42
+
+	if got, _ := stack.Peek(); got != 2 {
+		t.Fatalf("got %d want 2", got)
+	}
+}
@@ -0,0 +1,253 @@
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// syncWaiter is a goroutine parked on a Synchronous queue. For a waiting
+// consumer the producer stores elem and then closes ready; for a waiting
+// producer the consumer takes elem and then closes ready.
+type syncWaiter[T any] struct {
+	elem  T
+	ready chan struct{}
+}
+
+// Ensure Synchronous implements the Queue interface.
+var _ Queue[any] = (*Synchronous[any])(nil)
+
+// Synchronous is a Queue implementation without any capacity, where each
+// insertion must wait for a removal and vice versa, like Java's
+// SynchronousQueue. An element is handed directly from a producer to a
+// consumer; it is never stored in the queue.
+//
+// OfferWait blocks until a consumer takes the element and GetWait blocks
+// until a producer hands one over. Offer and Get never block: they only
+// succeed when a counterpart is already waiting. OfferContext and
+// GetContext wait until a counterpart arrives or the context is done.
+//
+// Since the queue never holds elements, Peek always returns
+// ErrNoElementsAvailable, Size is always 0, Contains is always false and
+// Clear always returns an empty slice. Waiting producers and consumers
+// are served in FIFO order.
+type Synchronous[T comparable] struct {
+	// at most one of producers and consumers is non-empty at any time.
+	producers []*syncWaiter[T]
+	consumers []*syncWaiter[T]
+
+	lock sync.Mutex
+}
+
+// NewSynchronous returns a new Synchronous queue.
+func NewSynchronous[T comparable]() *Synchronous[T] {
+	return &Synchronous[T]{}
+}
+
+// ==================================Insertion=================================
+
+// Offer hands the element to a waiting consumer.
+// If no consumer is waiting it returns the ErrQueueIsFull error, as a
+// queue without capacity is always full.
+func (sq *Synchronous[T]) Offer(elem T) error {
+	sq.lock.Lock()
+	defer sq.lock.Unlock()
+
+	if len(sq.consumers) == 0 {
+		return ErrQueueIsFull
+	}
+
+	sq.handTo(elem)
+
+	return nil
+}
+
+// OfferWait hands the element to a consumer, waiting for one to arrive
+// if none is waiting.
+func (sq *Synchronous[T]) OfferWait(elem T) {
+	_ = sq.OfferContext(context.Background(), elem)
+}
+
+// OfferContext hands the element to a consumer, waiting for one to arrive
+// if none is waiting. If ctx is done first, the element is withdrawn and
+// the context's error is returned.
+func (sq *Synchronous[T]) OfferContext(ctx context.Context, elem T) error {
+	sq.lock.Lock()
+
+	if len(sq.consumers) > 0 {
+		sq.handTo(elem)
+		sq.lock.Unlock()
+
+		return nil
+	}
+
+	producer := &syncWaiter[T]{elem: elem, ready: make(chan struct{})}
+	sq.producers = append(sq.producers, producer)
+
+	sq.lock.Unlock()
+
+	select {
+	case <-producer.ready:
+	case <-ctx.Done():
+		sq.lock.Lock()
+		withdrawn := removeWaiter(&sq.producers, producer)
+		sq.lock.Unlock()
+
+		// Otherwise a consumer took the element before the withdrawal.
+		if withdrawn {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Reset does nothing, as a Synchronous queue holds no elements.
+// Waiting producers and consumers keep waiting.
+func (*Synchronous[T]) Reset() {}
+
+// ===================================Removal==================================
+
+// Get takes the element of a waiting producer.
+// If no producer is waiting it returns an ErrNoElementsAvailable error.
+func (sq *Synchronous[T]) Get() (v T, _ error) {
+	sq.lock.Lock()
+	defer sq.lock.Unlock()
+
+	if len(sq.producers) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return sq.takeFrom(), nil
+}
+
+// GetWait takes the element of a producer, waiting for one to arrive if
+// none is waiting.
+func (sq *Synchronous[T]) GetWait() T {
+	v, _ := sq.GetContext(context.Background())
+
+	return v
+}
+
+// GetContext takes the element of a producer, waiting for one to arrive
+// if none is waiting. If ctx is done first, it returns the context's
+// error.
+func (sq *Synchronous[T]) GetContext(ctx context.Context) (v T, _ error) {
+	sq.lock.Lock()
+
+	if len(sq.producers) > 0 {
+		v = sq.takeFrom()
+		sq.lock.Unlock()
+
+		return v, nil
+	}
+
+	consumer := &syncWaiter[T]{ready: make(chan struct{})}
+	sq.consumers = append(sq.consumers, consumer)
+
+	sq.lock.Unlock()
+
+	select {
+	case <-consumer.ready:
+	case <-ctx.Done():
+		sq.lock.Lock()
+		withdrawn := removeWaiter(&sq.consumers, consumer)
+		sq.lock.Unlock()
+
+		// Otherwise a producer handed over an element before the
+		// withdrawal, and it must not be lost.
+		if withdrawn {
+			return v, ctx.Err()
+		}
+	}
+
+	return consumer.elem, nil
+}
+
+// Clear returns an empty slice, as a Synchronous queue holds no elements.
+func (*Synchronous[T]) Clear() []T {
+	return []T{}
+}
+
+// Iterator returns a closed channel, as a Synchronous queue holds no
+// elements.
+func (*Synchronous[T]) Iterator() <-chan T {
+	iteratorCh := make(chan T)
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// Peek always returns an ErrNoElementsAvailable error, as a Synchronous
+// queue holds no elements.
+func (*Synchronous[T]) Peek() (v T, _ error) {
+	return v, ErrNoElementsAvailable
+}
+
+// Size always returns 0, as a Synchronous queue holds no elements.
+func (*Synchronous[T]) Size() int {
+	return 0
+}
+
+// IsEmpty always returns true, as a Synchronous queue holds no elements.
+func (*Synchronous[T]) IsEmpty() bool {
+	return true
+}
+
+// Contains always returns false, as a Synchronous queue holds no elements.
+func (*Synchronous[T]) Contains(T) bool {
+	return false
+}
+
+// MarshalJSON serializes the Synchronous queue to an empty JSON array.
+func (*Synchronous[T]) MarshalJSON() ([]byte, error) {
+	return []byte("[]"), nil
+}
+
+// ===================================Helpers==================================
+
+// handTo gives elem to the longest waiting consumer. There must be one
+// and the caller must hold the lock.
+func (sq *Synchronous[T]) handTo(elem T) {
+	consumer := sq.consumers[0]
+
+	sq.consumers[0] = nil
+	sq.consumers = sq.consumers[1:]
+
+	consumer.elem = elem
+	close(consumer.ready)
+}
+
+// takeFrom takes the element of the longest waiting producer. There must
+// be one and the caller must hold the lock.
+func (sq *Synchronous[T]) takeFrom() T {
+	producer := sq.producers[0]
+
+	sq.producers[0] = nil
+	sq.producers = sq.producers[1:]
+
+	close(producer.ready)
+
+	return producer.elem
+}
+
+// removeWaiter removes w from waiters and reports whether it was there.
+func removeWaiter[T any](waiters *[]*syncWaiter[T], w *syncWaiter[T]) bool {
+	i := 0
+	for i < len(*waiters) && (*waiters)[i] != w {
+		i++
+	}
+
+	found := i < len(*waiters)
+
+	if found {
+		copy((*waiters)[i:], (*waiters)[i+1:])
+
+		(*waiters)[len(*waiters)-1] = nil
+		*waiters = (*waiters)[:len(*waiters)-1]
+	}
+
+	return found
+}
@@ -0,0 +1,300 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestSynchronous(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NoCounterpart", testSynchronousNoCounterpart)
+	t.Run("OfferWaitGetWait", testSynchronousOfferWaitGetWait)
+	t.Run("OfferToWaitingConsumer", testSynchronousOfferToWaitingConsumer)
+	t.Run("GetFromWaitingProducer", testSynchronousGetFromWaitingProducer)
+	t.Run("FIFOWaiters", testSynchronousFIFOWaiters)
+	t.Run("OfferContextCancelled", testSynchronousOfferContextCancelled)
+	t.Run("GetContextCancelled", testSynchronousGetContextCancelled)
+	t.Run("CancelledWaiterBehindOthers", testSynchronousCancelledWaiterBehindOthers)
+	t.Run("CancelRacesHandoff", testSynchronousCancelRacesHandoff)
+	t.Run("HoldsNoElements", testSynchronousHoldsNoElements)
+	t.Run("Reset", testSynchronousReset)
+}
+
+func testSynchronousNoCounterpart(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	if err := syncQueue.Offer(1); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+
+	if _, err := syncQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+
+func testSynchronousOfferWaitGetWait(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		syncQueue.OfferWait(1)
+	}()
+
+	if got := syncQueue.GetWait(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("OfferWait did not return after the handoff")
+	}
+}
+
+func testSynchronousOfferToWaitingConsumer(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	result := make(chan int)
+
+	go func() { result <- syncQueue.GetWait() }()
+
+	// Offer only succeeds once the consumer is parked.
+	deadline := time.Now().Add(time.Second)
+
+	for syncQueue.Offer(1) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("consumer never became available")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := <-result; got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+}
+
+func testSynchronousGetFromWaitingProducer(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		syncQueue.OfferWait(1)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		got, err := syncQueue.Get()
+		if err == nil {
+			if got != 1 {
+				t.Fatalf("got %d want 1", got)
+			}
+
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("producer never became available")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	<-done
+}
+
+func testSynchronousFIFOWaiters(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	const producers = 5
+
+	for i := 0; i < producers; i++ {
+		go syncQueue.OfferWait(i)
+
+		// Let each producer park before starting the next one.
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for want := 0; want < producers; want++ {
+		if got := syncQueue.GetWait(); got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testSynchronousOfferContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := syncQueue.OfferContext(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	// The withdrawn element must not be delivered.
+	if _, err := syncQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+
+func testSynchronousGetContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := syncQueue.GetContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	// The withdrawn consumer must not swallow an element.
+	if err := syncQueue.Offer(1); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+}
+
+func testSynchronousCancelledWaiterBehindOthers(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	go syncQueue.OfferWait(1)
+
+	// Let the first producer park before the one that gives up.
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := syncQueue.OfferContext(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	if got := syncQueue.GetWait(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	if _, err := syncQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+
+// testSynchronousCancelRacesHandoff cancels both sides while they meet and
+// checks that an element is delivered exactly when the producer reports
+// success.
+func testSynchronousCancelRacesHandoff(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	for i := 0; i < 1000; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		offered := make(chan error, 1)
+		taken := make(chan error, 1)
+
+		go func() { offered <- syncQueue.OfferContext(ctx, i) }()
+
+		go func() {
+			got, err := syncQueue.GetContext(ctx)
+			if err == nil && got != i {
+				err = errors.New("received a stale element")
+			}
+
+			taken <- err
+		}()
+
+		cancel()
+
+		offerErr, getErr := <-offered, <-taken
+
+		if (offerErr == nil) != (getErr == nil) {
+			t.Fatalf("iteration %d: offer err %v, get err %v", i, offerErr, getErr)
+		}
+	}
+}
+
+func testSynchronousHoldsNoElements(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	syncQueue.Reset()
+
+	if _, err := syncQueue.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if syncQueue.Size() != 0 || !syncQueue.IsEmpty() || syncQueue.Contains(0) {
+		t.Fatal("expected an empty queue")
+	}
+
+	if got := syncQueue.Clear(); len(got) != 0 {
+		t.Fatalf("expected no elements, got %v", got)
+	}
+
+	for range syncQueue.Iterator() {
+		t.Fatal("expected no elements from the iterator")
+	}
+
+	data, err := syncQueue.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[]" {
+		t.Fatalf("expected [], got %s", data)
+	}
+}
+
+func testSynchronousReset(t *testing.T) {
+	t.Parallel()
+
+	syncQueue := queue.NewSynchronous[int]()
+
+	go syncQueue.OfferWait(1)
+
+	// Let the producer park before resetting.
+	time.Sleep(5 * time.Millisecond)
+
+	syncQueue.Reset()
+
+	// The waiting producer survives the reset.
+	if got := syncQueue.GetWait(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	// And the queue keeps handing elements off afterwards.
+	go syncQueue.OfferWait(2)
+
+	if got := syncQueue.GetWait(); got != 2 {
+		t.Fatalf("got %d want 2", got)
+	}
+}
@@ -0,0 +1,675 @@
+package queue
+
+import (
+	"encoding/json"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+)
+
+// readyLevel marks a wheel entry that is due and sits in the ready list.
+const readyLevel = -1
+
+// wheelEntry is an element scheduled on a TimingWheel. Entries are linked
+// into exactly one slot (or the ready list) and, through samePrev/sameNext,
+// into the chain of entries holding an equal element, which is what makes
+// Remove O(1).
+type wheelEntry[T any] struct {
+	elem     T
+	deadline time.Time
+	expiry   uint64 // deadline rounded up to a whole tick.
+	level    int
+
+	prev, next         *wheelEntry[T]
+	list               *wheelList[T]
+	samePrev, sameNext *wheelEntry[T]
+}
+
+// wheelList is an intrusive doubly linked list of wheel entries.
+type wheelList[T any] struct {
+	head, tail *wheelEntry[T]
+	len        int
+}
+
+func (l *wheelList[T]) pushBack(e *wheelEntry[T]) {
+	e.list = l
+	e.prev = l.tail
+	e.next = nil
+
+	if l.tail == nil {
+		l.head = e
+	} else {
+		l.tail.next = e
+	}
+
+	l.tail = e
+	l.len++
+}
+
+func (l *wheelList[T]) remove(e *wheelEntry[T]) {
+	if e.prev == nil {
+		l.head = e.next
+	} else {
+		e.prev.next = e.next
+	}
+
+	if e.next == nil {
+		l.tail = e.prev
+	} else {
+		e.next.prev = e.prev
+	}
+
+	e.prev, e.next, e.list = nil, nil, nil
+	l.len--
+}
+
+// takeAll detaches and returns the head of the list, leaving it empty.
+// The returned entries are still chained through next.
+func (l *wheelList[T]) takeAll() *wheelEntry[T] {
+	head := l.head
+	*l = wheelList[T]{}
+
+	return head
+}
+
+// wheelLevel is one ring of a hierarchical timing wheel.
+type wheelLevel[T any] struct {
+	slots []wheelList[T]
+	count int
+}
+
+// Ensure TimingWheel implements the Queue interface.
+var _ Queue[any] = (*TimingWheel[any])(nil)
+
+// TimingWheel is a Queue implementation with the same contract as Delay:
+// each element becomes dequeuable at a deadline computed by a
+// caller-supplied function at Offer time, Get returns
+// ErrNoElementsAvailable until the head is due and GetWait sleeps until
+// it is.
+//
+// Instead of a binary heap it stores elements on a hierarchical timing
+// wheel, so Offer and Remove are O(1) regardless of how many elements are
+// pending. This fits large populations of short timeouts that are mostly
+// cancelled before they fire, such as connection idle timers.
+//
+// Deadlines are rounded up to a whole tick: an element is never released
+// before its deadline, and at most one tick after it. Elements that
+// become due in the same tick are released in Offer order.
+type TimingWheel[T comparable] struct {
+	deadlineFunc func(T) time.Time
+	now          func() time.Time
+	start        time.Time
+	tick         time.Duration
+	slotBits     int
+	mask         uint64
+
+	levels  []wheelLevel[T]
+	ready   wheelList[T]
+	cur     uint64 // next tick that has not been processed yet.
+	pending int    // entries scheduled on the levels, not yet due.
+	index   map[T]*wheelEntry[T]
+
+	initial  []T
+	capacity *int
+
+	lock     sync.Mutex
+	notEmpty *sync.Cond
+}
+
+// NewTimingWheel creates a TimingWheel queue containing the given
+// elements. deadlineFunc is called at Offer and Reset time to compute each
+// element's deadline. tick is the resolution of the wheel and slots the
+// number of slots per level; slots is rounded up to a power of two, and
+// levels are added on demand for deadlines further away than
+// tick*slots.
+// Panics if deadlineFunc is nil, tick is not positive, slots is lower
+// than 2 or WithCapacity is negative.
+func NewTimingWheel[T comparable](
+	elems []T,
+	deadlineFunc func(T) time.Time,
+	tick time.Duration,
+	slots int,
+	opts ...Option,
+) *TimingWheel[T] {
+	if deadlineFunc == nil {
+		panic("nil deadline func")
+	}
+
+	if tick <= 0 {
+		panic("tick must be positive")
+	}
+
+	if slots < 2 { //nolint:mnd // a wheel needs at least two slots.
+		panic("slots must be at least 2")
+	}
+
+	options := options{capacity: nil, clock: time.Now}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	effective := elems
+	if options.capacity != nil && *options.capacity < len(effective) {
+		effective = effective[:*options.capacity]
+	}
+
+	initial := make([]T, len(effective))
+	copy(initial, effective)
+
+	slotBits := bits.Len(uint(slots - 1))
+
+	tw := &TimingWheel[T]{
+		deadlineFunc: deadlineFunc,
+		now:          options.clock,
+		start:        options.clock(),
+		tick:         tick,
+		slotBits:     slotBits,
+		mask:         1<<slotBits - 1,
+		index:        make(map[T]*wheelEntry[T], len(effective)),
+		initial:      initial,
+		capacity:     options.capacity,
+	}
+
+	tw.notEmpty = sync.NewCond(&tw.lock)
+
+	for _, e := range effective {
+		tw.schedule(e)
+	}
+
+	return tw
+}
+
+// ==================================Insertion=================================
+
+// Offer schedules elem at deadlineFunc(elem) in O(1).
+// Returns ErrQueueIsFull when constructed WithCapacity and already at limit.
+func (tw *TimingWheel[T]) Offer(elem T) error {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	if tw.capacity != nil && tw.size() >= *tw.capacity {
+		return ErrQueueIsFull
+	}
+
+	tw.schedule(elem)
+
+	tw.notEmpty.Broadcast()
+
+	return nil
+}
+
+// Reset restores the queue to the elements provided at construction,
+// recomputing their deadlines with the original deadlineFunc.
+func (tw *TimingWheel[T]) Reset() {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	tw.clear()
+
+	for _, e := range tw.initial {
+		tw.schedule(e)
+	}
+
+	tw.notEmpty.Broadcast()
+}
+
+// ===================================Removal==================================
+
+// Get returns the head if its deadline has passed, otherwise
+// ErrNoElementsAvailable. Never blocks.
+func (tw *TimingWheel[T]) Get() (v T, _ error) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	tw.advance(tw.nowTick())
+
+	if tw.ready.len == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	elem := tw.popReady()
+
+	tw.notEmpty.Broadcast()
+
+	return elem, nil
+}
+
+// GetWait blocks until the head's deadline passes and returns that
+// element. If the queue is empty, waits for an Offer.
+func (tw *TimingWheel[T]) GetWait() T {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	for {
+		tw.advance(tw.nowTick())
+
+		if tw.ready.len > 0 {
+			return tw.popReady()
+		}
+
+		if tw.pending > 0 {
+			// Sleep until the tick of the earliest pending entry. Any
+			// Offer / Remove / Reset / Clear also Broadcasts, so we
+			// re-check on state changes too.
+			due := tw.start.Add(time.Duration(tw.earliest().expiry) * tw.tick)
+			timer := time.AfterFunc(due.Sub(tw.now()), func() {
+				tw.lock.Lock()
+				tw.notEmpty.Broadcast()
+				tw.lock.Unlock()
+			})
+
+			tw.notEmpty.Wait()
+			timer.Stop()
+
+			continue
+		}
+
+		tw.notEmpty.Wait()
+	}
+}
+
+// Remove cancels one pending occurrence of elem in O(1), due or not.
+// It reports whether an occurrence was found.
+func (tw *TimingWheel[T]) Remove(elem T) bool {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	e, ok := tw.index[elem]
+	if !ok {
+		return false
+	}
+
+	tw.unlink(e)
+
+	tw.notEmpty.Broadcast()
+
+	return true
+}
+
+// Clear removes and returns all elements in deadline order.
+func (tw *TimingWheel[T]) Clear() []T {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	out := tw.sorted()
+
+	tw.clear()
+
+	tw.notEmpty.Broadcast()
+
+	return out
+}
+
+// Iterator returns a channel that receives all elements in deadline
+// order. Elements are removed from the queue.
+func (tw *TimingWheel[T]) Iterator() <-chan T {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	elems := tw.sorted()
+
+	tw.clear()
+
+	ch := make(chan T, len(elems))
+
+	for i := range elems {
+		ch <- elems[i]
+	}
+
+	close(ch)
+
+	tw.notEmpty.Broadcast()
+
+	return ch
+}
+
+// =================================Examination================================
+
+// Peek returns the head regardless of whether its deadline has passed.
+// Returns ErrNoElementsAvailable if the queue is empty.
+func (tw *TimingWheel[T]) Peek() (v T, _ error) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	tw.advance(tw.nowTick())
+
+	if tw.size() == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return tw.earliest().elem, nil
+}
+
+// Size returns the number of elements in the queue, due or not.
+func (tw *TimingWheel[T]) Size() int {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	return tw.size()
+}
+
+// IsEmpty returns true if the queue contains no elements.
+func (tw *TimingWheel[T]) IsEmpty() bool {
+	return tw.Size() == 0
+}
+
+// Contains reports whether the given element is in the queue, in O(1).
+func (tw *TimingWheel[T]) Contains(elem T) bool {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+
+	_, ok := tw.index[elem]
+
+	return ok
+}
+
+// MarshalJSON serializes the TimingWheel queue to JSON in deadline order.
+func (tw *TimingWheel[T]) MarshalJSON() ([]byte, error) {
+	tw.lock.Lock()
+	out := tw.sorted()
+	tw.lock.Unlock()
+
+	return json.Marshal(out)
+}
+
+// ===================================Helpers==================================
+
+func (tw *TimingWheel[T]) size() int {
+	return tw.pending + tw.ready.len
+}
+
+// nowTick returns the last tick that has fully elapsed.
+func (tw *TimingWheel[T]) nowTick() uint64 {
+	elapsed := tw.now().Sub(tw.start)
+	if elapsed < 0 {
+		return 0
+	}
+
+	return uint64(elapsed / tw.tick)
+}
+
+// tickOf rounds deadline up to a whole tick so that elements are never
+// released early.
+func (tw *TimingWheel[T]) tickOf(deadline time.Time) uint64 {
+	d := deadline.Sub(tw.start)
+	if d <= 0 {
+		return 0
+	}
+
+	return uint64((d + tw.tick - 1) / tw.tick)
+}
+
+// schedule creates an entry for elem and places it on the wheel.
+func (tw *TimingWheel[T]) schedule(elem T) {
+	deadline := tw.deadlineFunc(elem)
+
+	e := &wheelEntry[T]{
+		elem:     elem,
+		deadline: deadline,
+		expiry:   tw.tickOf(deadline),
+	}
+
+	if head, ok := tw.index[elem]; ok {
+		e.sameNext = head
+		head.samePrev = e
+	}
+
+	tw.index[elem] = e
+
+	tw.place(e)
+}
+
+// place puts e on the level given by the highest group of bits in which
+// its expiry differs from the current tick, or straight on the ready list
+// if it is already due.
+func (tw *TimingWheel[T]) place(e *wheelEntry[T]) {
+	if e.expiry < tw.cur {
+		e.level = readyLevel
+		tw.ready.pushBack(e)
+
+		return
+	}
+
+	level := 0
+	if diff := e.expiry ^ tw.cur; diff != 0 {
+		level = (bits.Len64(diff) - 1) / tw.slotBits
+	}
+
+	for len(tw.levels) <= level {
+		tw.levels = append(tw.levels, wheelLevel[T]{
+			slots: make([]wheelList[T], tw.mask+1),
+		})
+	}
+
+	e.level = level
+	tw.levels[level].slots[tw.slotAt(e.expiry, level)].pushBack(e)
+	tw.levels[level].count++
+	tw.pending++
+}
+
+// slotAt returns the slot index of tick t on the given level.
+func (tw *TimingWheel[T]) slotAt(t uint64, level int) uint64 {
+	return (t >> (tw.slotBits * level)) & tw.mask
+}
+
+// unlink removes e from its list and from the element index.
+func (tw *TimingWheel[T]) unlink(e *wheelEntry[T]) {
+	if e.level == readyLevel {
+		tw.ready.remove(e)
+	} else {
+		e.list.remove(e)
+		tw.levels[e.level].count--
+		tw.pending--
+	}
+
+	switch {
+	case e.samePrev != nil:
+		e.samePrev.sameNext = e.sameNext
+	case e.sameNext != nil:
+		tw.index[e.elem] = e.sameNext
+	default:
+		delete(tw.index, e.elem)
+	}
+
+	if e.sameNext != nil {
+		e.sameNext.samePrev = e.samePrev
+	}
+}
+
+// popReady removes and returns the first due element.
+func (tw *TimingWheel[T]) popReady() T {
+	e := tw.ready.head
+
+	tw.unlink(e)
+
+	return e.elem
+}
+
+// advance processes every tick up to and including target, moving due
+// entries to the ready list and cascading higher levels down as their
+// slots come into range. Empty stretches of the wheel are skipped, so an
+// idle wheel costs O(slots) per level instead of O(elapsed ticks).
+func (tw *TimingWheel[T]) advance(target uint64) {
+	for tw.cur <= target {
+		if tw.pending == 0 {
+			tw.cur = target + 1
+
+			return
+		}
+
+		if tw.levels[0].count > 0 {
+			slot := tw.nextSlot(0, tw.slotAt(tw.cur, 0))
+
+			next := tw.cur&^tw.mask | slot
+			if next > target {
+				tw.cur = target + 1
+
+				return
+			}
+
+			tw.cur = next
+			tw.expire(slot)
+			tw.cur++
+
+			if tw.cur&tw.mask == 0 {
+				tw.cascade()
+			}
+
+			continue
+		}
+
+		// Lower levels are empty: jump straight to the next boundary at
+		// which the lowest populated level cascades.
+		level := 1
+		for tw.levels[level].count == 0 {
+			level++
+		}
+
+		shift := tw.slotBits * level
+		slot := tw.nextSlot(level, tw.slotAt(tw.cur, level)+1)
+
+		// Landing exactly on the boundary still has to cascade it, even
+		// if that is past target, or the slot would be skipped for good.
+		next := tw.cur>>(shift+tw.slotBits)<<(shift+tw.slotBits) | slot<<shift
+		if next > target+1 {
+			tw.cur = target + 1
+
+			return
+		}
+
+		tw.cur = next
+		tw.cascade()
+	}
+}
+
+// nextSlot returns the first non-empty slot of level at or after from.
+// Placement guarantees such a slot exists within the current rotation.
+func (tw *TimingWheel[T]) nextSlot(level int, from uint64) uint64 {
+	slots := tw.levels[level].slots
+
+	for slots[from].len == 0 {
+		from++
+	}
+
+	return from
+}
+
+// expire moves every entry of a level-0 slot to the ready list.
+func (tw *TimingWheel[T]) expire(slot uint64) {
+	list := &tw.levels[0].slots[slot]
+
+	tw.levels[0].count -= list.len
+	tw.pending -= list.len
+
+	for e := list.takeAll(); e != nil; {
+		next := e.next
+
+		e.level = readyLevel
+		tw.ready.pushBack(e)
+
+		e = next
+	}
+}
+
+// cascade re-places the entries of every slot whose range starts at the
+// current tick, moving them to lower levels.
+func (tw *TimingWheel[T]) cascade() {
+	for level := len(tw.levels) - 1; level > 0; level-- {
+		shift := tw.slotBits * level
+		if tw.cur&(1<<shift-1) != 0 {
+			continue
+		}
+
+		list := &tw.levels[level].slots[tw.slotAt(tw.cur, level)]
+
+		tw.levels[level].count -= list.len
+		tw.pending -= list.len
+
+		for e := list.takeAll(); e != nil; {
+			next := e.next
+			e.prev, e.next = nil, nil
+
+			tw.place(e)
+
+			e = next
+		}
+	}
+}
+
+// earliest returns the entry that will be released next.
+// The queue must be non-empty.
+func (tw *TimingWheel[T]) earliest() *wheelEntry[T] {
+	if tw.ready.len > 0 {
+		return tw.ready.head
+	}
+
+	level := 0
+	for tw.levels[level].count == 0 {
+		level++
+	}
+
+	from := tw.slotAt(tw.cur, level)
+	if level > 0 {
+		from++
+	}
+
+	// Entries sharing a slot on a higher level span several ticks, so
+	// pick the one with the earliest deadline.
+	first := tw.levels[level].slots[tw.nextSlot(level, from)].head
+	for e := first.next; e != nil; e = e.next {
+		if e.deadline.Before(first.deadline) {
+			first = e
+		}
+	}
+
+	return first
+}
+
+// sorted returns every element in deadline order without removing them.
+func (tw *TimingWheel[T]) sorted() []T {
+	entries := make([]*wheelEntry[T], 0, tw.size())
+
+	for e := tw.ready.head; e != nil; e = e.next {
+		entries = append(entries, e)
+	}
+
+	for l := range tw.levels {
+		if tw.levels[l].count == 0 {
+			continue
+		}
+
+		for s := range tw.levels[l].slots {
+			for e := tw.levels[l].slots[s].head; e != nil; e = e.next {
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].deadline.Before(entries[j].deadline)
+	})
+
+	out := make([]T, len(entries))
+	for i := range entries {
+		out[i] = entries[i].elem
+	}
+
+	return out
+}
+
+// clear drops every entry. The wheel keeps its current tick.
+func (tw *TimingWheel[T]) clear() {
+	for l := range tw.levels {
+		tw.levels[l] = wheelLevel[T]{slots: make([]wheelList[T], tw.mask+1)}
+	}
+
+	tw.ready = wheelList[T]{}
+	tw.pending = 0
+	tw.index = make(map[T]*wheelEntry[T])
+}
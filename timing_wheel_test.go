@@ -0,0 +1,765 @@
+package queue_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+// fakeClock is a manually advanced clock for the time-based queues.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestTimingWheel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InvalidArguments", testTimingWheelInvalidArguments)
+	t.Run("Get", testTimingWheelGet)
+	t.Run("GetWait", testTimingWheelGetWait)
+	t.Run("Offer", testTimingWheelOffer)
+	t.Run("Remove", testTimingWheelRemove)
+	t.Run("Peek", testTimingWheelPeek)
+	t.Run("Contains", testTimingWheelContains)
+	t.Run("Clear", testTimingWheelClear)
+	t.Run("Iterator", testTimingWheelIterator)
+	t.Run("Reset", testTimingWheelReset)
+	t.Run("MarshalJSON", testTimingWheelMarshalJSON)
+	t.Run("CapacityLesserThanLenElems", testTimingWheelCapacityLesserThanLenElems)
+	t.Run("ReleasesWithinOneTick", testTimingWheelReleasesWithinOneTick)
+	t.Run("ClockBeforeStart", testTimingWheelClockBeforeStart)
+}
+
+func testTimingWheelClockBeforeStart(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	wheel := queue.NewTimingWheel(
+		[]delayed{{ID: 1, At: clock.Now().Add(time.Millisecond)}},
+		delayedDeadline,
+		time.Millisecond,
+		8,
+		queue.WithClock(clock.Now),
+	)
+
+	clock.Advance(-time.Second)
+
+	if _, err := wheel.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+}
+
+func testTimingWheelInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		panicMsg string
+		create   func()
+	}{
+		{
+			name:     "NilDeadlineFunc",
+			panicMsg: "nil deadline func",
+			create: func() {
+				queue.NewTimingWheel[delayed](nil, nil, time.Millisecond, 8)
+			},
+		},
+		{
+			name:     "NonPositiveTick",
+			panicMsg: "tick must be positive",
+			create: func() {
+				queue.NewTimingWheel[delayed](nil, delayedDeadline, 0, 8)
+			},
+		},
+		{
+			name:     "TooFewSlots",
+			panicMsg: "slots must be at least 2",
+			create: func() {
+				queue.NewTimingWheel[delayed](nil, delayedDeadline, time.Millisecond, 1)
+			},
+		},
+		{
+			name:     "NegativeCapacity",
+			panicMsg: negativeCapacityPanic,
+			create: func() {
+				queue.NewTimingWheel[delayed](
+					nil,
+					delayedDeadline,
+					time.Millisecond,
+					8,
+					queue.WithCapacity(-1),
+				)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if p := recover(); p != tc.panicMsg {
+					t.Fatalf("expected panic %q, got %v", tc.panicMsg, p)
+				}
+			}()
+
+			tc.create()
+		})
+	}
+}
+
+func testTimingWheelGet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Due", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		wheel := queue.NewTimingWheel(
+			[]delayed{{ID: 1, At: clock.Now().Add(-time.Minute)}},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+			queue.WithClock(clock.Now),
+		)
+
+		got, err := wheel.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got.ID != 1 {
+			t.Fatalf("got id=%d want 1", got.ID)
+		}
+	})
+
+	t.Run("NotDue", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		wheel := queue.NewTimingWheel(
+			[]delayed{{ID: 2, At: clock.Now().Add(time.Hour)}},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+			queue.WithClock(clock.Now),
+		)
+
+		if _, err := wheel.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+		}
+
+		clock.Advance(time.Hour)
+
+		got, err := wheel.Get()
+		if err != nil {
+			t.Fatalf("get after advance: %v", err)
+		}
+
+		if got.ID != 2 {
+			t.Fatalf("got id=%d want 2", got.ID)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		wheel := queue.NewTimingWheel[delayed](nil, delayedDeadline, time.Millisecond, 8)
+
+		if _, err := wheel.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+		}
+	})
+
+	t.Run("SameTickInOfferOrder", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		at := clock.Now().Add(5 * time.Millisecond)
+
+		wheel := queue.NewTimingWheel(
+			[]delayed{{ID: 1, At: at}, {ID: 2, At: at}, {ID: 3, At: at}},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+			queue.WithClock(clock.Now),
+		)
+
+		clock.Advance(5 * time.Millisecond)
+
+		for want := 1; want <= 3; want++ {
+			got, err := wheel.Get()
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+
+			if got.ID != want {
+				t.Fatalf("got id=%d want %d", got.ID, want)
+			}
+		}
+	})
+}
+
+func testTimingWheelGetWait(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WakesAfterDeadline", func(t *testing.T) {
+		t.Parallel()
+
+		due := time.Now().Add(20 * time.Millisecond)
+		wheel := queue.NewTimingWheel(
+			[]delayed{{ID: 1, At: due}},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+		)
+
+		got := wheel.GetWait()
+
+		if got.ID != 1 {
+			t.Fatalf("got id=%d want 1", got.ID)
+		}
+
+		if now := time.Now(); now.Before(due) {
+			t.Fatalf("GetWait returned %s before the deadline", due.Sub(now))
+		}
+	})
+
+	t.Run("BlocksOnEmpty", func(t *testing.T) {
+		t.Parallel()
+
+		wheel := queue.NewTimingWheel[delayed](nil, delayedDeadline, time.Millisecond, 8)
+
+		done := make(chan delayed, 1)
+
+		go func() {
+			done <- wheel.GetWait()
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("GetWait returned on empty queue")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		if err := wheel.Offer(delayed{ID: 7, At: time.Now().Add(-time.Second)}); err != nil {
+			t.Fatalf("offer: %v", err)
+		}
+
+		select {
+		case got := <-done:
+			if got.ID != 7 {
+				t.Fatalf("got id=%d want 7", got.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("GetWait did not wake after Offer")
+		}
+	})
+}
+
+func testTimingWheelOffer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		wheel := queue.NewTimingWheel[delayed](nil, delayedDeadline, time.Millisecond, 8)
+
+		if err := wheel.Offer(delayed{ID: 1, At: time.Now()}); err != nil {
+			t.Fatalf("offer: %v", err)
+		}
+
+		if wheel.Size() != 1 {
+			t.Fatalf("size = %d want 1", wheel.Size())
+		}
+	})
+
+	t.Run("ErrQueueIsFull", func(t *testing.T) {
+		t.Parallel()
+
+		wheel := queue.NewTimingWheel(
+			[]delayed{{ID: 1, At: time.Now()}},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+			queue.WithCapacity(1),
+		)
+
+		err := wheel.Offer(delayed{ID: 2, At: time.Now()})
+		if !errors.Is(err, queue.ErrQueueIsFull) {
+			t.Fatalf("expected ErrQueueIsFull, got %v", err)
+		}
+	})
+}
+
+func testTimingWheelRemove(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Pending", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		d := delayed{ID: 1, At: clock.Now().Add(time.Minute)}
+
+		wheel := queue.NewTimingWheel(
+			[]delayed{d},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+			queue.WithClock(clock.Now),
+		)
+
+		if !wheel.Remove(d) {
+			t.Fatal("expected Remove to find the element")
+		}
+
+		if wheel.Remove(d) {
+			t.Fatal("expected second Remove to find nothing")
+		}
+
+		clock.Advance(time.Hour)
+
+		if _, err := wheel.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+		}
+	})
+
+	t.Run("Due", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		d := delayed{ID: 1, At: clock.Now()}
+
+		wheel := queue.NewTimingWheel(
+			[]delayed{d, {ID: 2, At: clock.Now()}},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+			queue.WithClock(clock.Now),
+		)
+
+		clock.Advance(time.Millisecond)
+
+		// Move both elements to the ready list.
+		if _, err := wheel.Peek(); err != nil {
+			t.Fatalf("peek: %v", err)
+		}
+
+		if !wheel.Remove(d) {
+			t.Fatal("expected Remove to find the element")
+		}
+
+		got, err := wheel.Get()
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if got.ID != 2 {
+			t.Fatalf("got id=%d want 2", got.ID)
+		}
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		d := delayed{ID: 1, At: time.Now().Add(time.Minute)}
+
+		wheel := queue.NewTimingWheel(
+			[]delayed{d, d, d},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+		)
+
+		for i := 0; i < 3; i++ {
+			if !wheel.Contains(d) {
+				t.Fatalf("expected queue to contain d after %d removals", i)
+			}
+
+			if !wheel.Remove(d) {
+				t.Fatalf("remove %d failed", i)
+			}
+		}
+
+		if wheel.Contains(d) || !wheel.IsEmpty() {
+			t.Fatal("expected queue to be empty")
+		}
+	})
+
+	t.Run("DuplicateInTheMiddle", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		d := delayed{ID: 1, At: clock.Now()}
+
+		wheel := queue.NewTimingWheel(
+			[]delayed{d, d, d},
+			delayedDeadline,
+			time.Millisecond,
+			8,
+			queue.WithClock(clock.Now),
+		)
+
+		clock.Advance(time.Millisecond)
+
+		// Get pops the oldest occurrence, the tail of the duplicate chain;
+		// Remove then takes the newest, leaving the middle one.
+		for i := 0; i < 2; i++ {
+			if _, err := wheel.Get(); err != nil {
+				t.Fatalf("get: %v", err)
+			}
+
+			if !wheel.Contains(d) {
+				t.Fatalf("expected queue to still contain d after %d gets", i+1)
+			}
+		}
+
+		if !wheel.Remove(d) || wheel.Contains(d) {
+			t.Fatal("expected the last occurrence to be removed")
+		}
+	})
+}
+
+func testTimingWheelPeek(t *testing.T) {
+	t.Parallel()
+
+	t.Run("IgnoresDeadline", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		now := clock.Now()
+
+		// Far apart deadlines land on different levels of a small wheel.
+		wheel := queue.NewTimingWheel(
+			[]delayed{
+				{ID: 3, At: now.Add(time.Hour)},
+				{ID: 2, At: now.Add(90 * time.Millisecond)},
+				{ID: 1, At: now.Add(70 * time.Millisecond)},
+			},
+			delayedDeadline,
+			time.Millisecond,
+			4,
+			queue.WithClock(clock.Now),
+		)
+
+		got, err := wheel.Peek()
+		if err != nil {
+			t.Fatalf("peek: %v", err)
+		}
+
+		if got.ID != 1 {
+			t.Fatalf("got id=%d want 1", got.ID)
+		}
+
+		if wheel.Size() != 3 {
+			t.Fatal("Peek consumed an element")
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		wheel := queue.NewTimingWheel[delayed](nil, delayedDeadline, time.Millisecond, 8)
+
+		if _, err := wheel.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+			t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+		}
+	})
+}
+
+func testTimingWheelContains(t *testing.T) {
+	t.Parallel()
+
+	d := delayed{ID: 42, At: time.Now().Add(time.Minute)}
+	wheel := queue.NewTimingWheel([]delayed{d}, delayedDeadline, time.Millisecond, 8)
+
+	if !wheel.Contains(d) {
+		t.Fatal("expected queue to contain d")
+	}
+
+	if wheel.Contains(delayed{ID: 99, At: time.Now()}) {
+		t.Fatal("expected queue to not contain a stranger")
+	}
+}
+
+func testTimingWheelClear(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	wheel := queue.NewTimingWheel(
+		[]delayed{
+			{ID: 2, At: now.Add(20 * time.Millisecond)},
+			{ID: 1, At: now.Add(-10 * time.Millisecond)},
+			{ID: 3, At: now.Add(time.Hour)},
+		},
+		delayedDeadline,
+		time.Millisecond,
+		8,
+	)
+
+	// Peek advances the wheel, moving the overdue element to the ready list.
+	if _, err := wheel.Peek(); err != nil {
+		t.Fatalf("peek: %v", err)
+	}
+
+	cleared := wheel.Clear()
+	ids := make([]int, len(cleared))
+
+	for i, c := range cleared {
+		ids[i] = c.ID
+	}
+
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(expected, ids) {
+		t.Fatalf("expected %v got %v", expected, ids)
+	}
+
+	if !wheel.IsEmpty() {
+		t.Fatal("Clear did not empty the queue")
+	}
+}
+
+func testTimingWheelIterator(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	wheel := queue.NewTimingWheel(
+		[]delayed{
+			{ID: 2, At: now.Add(20 * time.Millisecond)},
+			{ID: 1, At: now.Add(10 * time.Millisecond)},
+		},
+		delayedDeadline,
+		time.Millisecond,
+		8,
+	)
+
+	ids := make([]int, 0, 2)
+
+	for d := range wheel.Iterator() {
+		ids = append(ids, d.ID)
+	}
+
+	expected := []int{1, 2}
+	if !reflect.DeepEqual(expected, ids) {
+		t.Fatalf("expected %v got %v", expected, ids)
+	}
+
+	if !wheel.IsEmpty() {
+		t.Fatal("Iterator did not empty the queue")
+	}
+}
+
+func testTimingWheelReset(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	wheel := queue.NewTimingWheel(
+		[]delayed{{ID: 1, At: clock.Now()}},
+		delayedDeadline,
+		time.Millisecond,
+		8,
+		queue.WithClock(clock.Now),
+	)
+
+	if err := wheel.Offer(delayed{ID: 2, At: clock.Now().Add(time.Minute)}); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	wheel.Reset()
+
+	if wheel.Size() != 1 {
+		t.Fatalf("size after reset = %d want 1", wheel.Size())
+	}
+
+	clock.Advance(time.Millisecond)
+
+	got, err := wheel.Get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	if got.ID != 1 {
+		t.Fatalf("got id=%d want 1", got.ID)
+	}
+}
+
+func testTimingWheelMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	wheel := queue.NewTimingWheel(
+		[]delayed{{ID: 2, At: at.Add(time.Second)}, {ID: 1, At: at}},
+		delayedDeadline,
+		time.Millisecond,
+		8,
+	)
+
+	marshaled, err := json.Marshal(wheel)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	expected := []byte(`[{"id":1,"at":"2030-01-01T00:00:00Z"},{"id":2,"at":"2030-01-01T00:00:01Z"}]`)
+	if !bytes.Equal(expected, marshaled) {
+		t.Fatalf("expected %s, got %s", expected, marshaled)
+	}
+}
+
+func testTimingWheelCapacityLesserThanLenElems(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	wheel := queue.NewTimingWheel(
+		[]delayed{{ID: 1, At: now}, {ID: 2, At: now}, {ID: 3, At: now}},
+		delayedDeadline,
+		time.Millisecond,
+		8,
+		queue.WithCapacity(2),
+	)
+
+	if wheel.Size() != 2 {
+		t.Fatalf("size = %d want 2", wheel.Size())
+	}
+}
+
+// testTimingWheelReleasesWithinOneTick schedules random deadlines across
+// several levels of a small wheel, then advances the clock in random
+// steps and checks that every element is released no earlier than its
+// deadline and no later than one tick after it, in deadline order across
+// ticks.
+func testTimingWheelReleasesWithinOneTick(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tick  = time.Millisecond
+		total = 2000
+	)
+
+	rng := rand.New(rand.NewSource(7))
+	clock := newFakeClock()
+	start := clock.Now()
+
+	wheel := queue.NewTimingWheel[delayed](
+		nil,
+		delayedDeadline,
+		tick,
+		4,
+		queue.WithClock(clock.Now),
+	)
+
+	for i := 0; i < total; i++ {
+		at := start.Add(time.Duration(rng.Int63n(int64(10 * time.Second))))
+
+		if err := wheel.Offer(delayed{ID: i, At: at}); err != nil {
+			t.Fatalf("offer: %v", err)
+		}
+	}
+
+	released := 0
+
+	for released < total {
+		clock.Advance(time.Duration(rng.Int63n(int64(50 * tick))))
+		now := clock.Now()
+
+		for {
+			got, err := wheel.Get()
+			if err != nil {
+				break
+			}
+
+			if now.Before(got.At) {
+				t.Fatalf("id %d released %s early", got.ID, got.At.Sub(now))
+			}
+
+			released++
+		}
+
+		// Nothing that is more than a tick overdue may remain.
+		if head, err := wheel.Peek(); err == nil && !now.Before(head.At.Add(tick)) {
+			t.Fatalf("id %d still pending %s after its deadline", head.ID, now.Sub(head.At))
+		}
+	}
+	if leftover := wheel.Clear(); len(leftover) != 0 {
+		t.Fatalf("expected drained wheel, got %d leftover elements", len(leftover))
+	}
+}
+
+func BenchmarkTimingWheel(b *testing.B) {
+	const pending = 1_000_000
+
+	now := time.Now()
+
+	// Spread 1M idle timers over the next minute.
+	fill := func(q queue.Queue[delayed]) {
+		for i := 0; i < pending; i++ {
+			_ = q.Offer(delayed{ID: -i - 1, At: now.Add(time.Duration(i) * 60 * time.Microsecond)})
+		}
+	}
+
+	b.Run("Offer_Remove_1M", func(b *testing.B) {
+		wheel := queue.NewTimingWheel[delayed](nil, delayedDeadline, time.Millisecond, 256)
+		fill(wheel)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			d := delayed{ID: i, At: now.Add(30 * time.Second)}
+
+			_ = wheel.Offer(d)
+			_ = wheel.Remove(d)
+		}
+	})
+
+	b.Run("Offer_1M", func(b *testing.B) {
+		wheel := queue.NewTimingWheel[delayed](nil, delayedDeadline, time.Millisecond, 256)
+		fill(wheel)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			_ = wheel.Offer(delayed{ID: i, At: now.Add(time.Duration(i%60) * time.Second)})
+		}
+	})
+
+	b.Run("Delay_Offer_1M", func(b *testing.B) {
+		delayQueue := queue.NewDelay[delayed](nil, delayedDeadline)
+		fill(delayQueue)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			_ = delayQueue.Offer(delayed{ID: i, At: now.Add(time.Duration(i%60) * time.Second)})
+		}
+	})
+}
@@ -0,0 +1,448 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// transferTicket tracks an element offered with Transfer until a consumer
+// receives it. err is set before done is closed, under the queue lock.
+type transferTicket struct {
+	done chan struct{}
+	err  error
+}
+
+// transferItem is an element of a Transfer queue; ticket is nil for
+// elements that were not offered with Transfer.
+type transferItem[T any] struct {
+	elem   T
+	ticket *transferTicket
+}
+
+// Ensure Transfer implements the Queue interface.
+var _ Queue[any] = (*Transfer[any])(nil)
+
+// Transfer is a Queue implementation with the semantics of Blocking that
+// additionally lets a producer wait until its element is received by a
+// consumer, like Java's TransferQueue.
+//
+// Transfer enqueues an element and blocks until a consumer dequeues that
+// specific element. TryTransfer only hands an element over if a consumer
+// is already waiting in GetWait. Every other method behaves like its
+// Blocking counterpart; an element removed by Get, GetWait, Clear or
+// Iterator counts as received.
+type Transfer[T comparable] struct {
+	initialElems []T
+	elems        []transferItem[T]
+	capacity     *int
+
+	// waiting is the number of consumers blocked in GetWait.
+	waiting int
+
+	// synchronization
+	lock         sync.Mutex
+	notEmptyCond *sync.Cond
+	notFullCond  *sync.Cond
+}
+
+// NewTransfer returns a new Transfer queue containing the given elements.
+// Panics if WithCapacity is negative.
+func NewTransfer[T comparable](
+	elems []T,
+	opts ...Option,
+) *Transfer[T] {
+	options := options{
+		capacity: nil,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if options.capacity != nil && *options.capacity < 0 {
+		panic("negative capacity")
+	}
+
+	if options.capacity != nil && len(elems) > *options.capacity {
+		elems = elems[:*options.capacity]
+	}
+
+	initialElems := make([]T, len(elems))
+	copy(initialElems, elems)
+
+	tq := &Transfer[T]{
+		initialElems: initialElems,
+		capacity:     options.capacity,
+	}
+
+	tq.notEmptyCond = sync.NewCond(&tq.lock)
+	tq.notFullCond = sync.NewCond(&tq.lock)
+
+	tq.reset()
+
+	return tq
+}
+
+// ==================================Insertion=================================
+
+// Transfer inserts the element to the tail of the queue and waits until a
+// consumer receives it, waiting first for a free slot if the queue is
+// full. An element for a consumer already waiting in GetWait is enqueued
+// even if the queue is full.
+//
+// If ctx is done before the element is received, the element is removed
+// from the queue and the context's error is returned. If Reset discards
+// the element, ErrElementDiscarded is returned.
+func (tq *Transfer[T]) Transfer(ctx context.Context, elem T) error {
+	tq.lock.Lock()
+
+	if !tq.hasIdleConsumer() && tq.isFull() {
+		stop := broadcastOnDone(ctx, tq.notFullCond)
+
+		for tq.isFull() && ctx.Err() == nil {
+			tq.notFullCond.Wait()
+		}
+
+		stop()
+
+		if err := ctx.Err(); err != nil {
+			tq.lock.Unlock()
+
+			return err
+		}
+	}
+
+	ticket := &transferTicket{done: make(chan struct{})}
+
+	tq.push(transferItem[T]{elem: elem, ticket: ticket})
+
+	tq.lock.Unlock()
+
+	select {
+	case <-ticket.done:
+	case <-ctx.Done():
+		tq.lock.Lock()
+		withdrawn := tq.withdraw(ticket)
+		tq.lock.Unlock()
+
+		// Otherwise a consumer received the element, or Reset discarded
+		// it, before the withdrawal.
+		if withdrawn {
+			return ctx.Err()
+		}
+	}
+
+	return ticket.err
+}
+
+// TryTransfer inserts the element only if a consumer is waiting in
+// GetWait to receive it, even if the queue is full, and reports whether
+// it did. It never blocks.
+func (tq *Transfer[T]) TryTransfer(elem T) bool {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	if !tq.hasIdleConsumer() {
+		return false
+	}
+
+	tq.push(transferItem[T]{elem: elem})
+
+	return true
+}
+
+// OfferWait inserts the element to the tail of the queue.
+// It waits for necessary space to become available.
+func (tq *Transfer[T]) OfferWait(elem T) {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	for tq.isFull() {
+		tq.notFullCond.Wait()
+	}
+
+	tq.push(transferItem[T]{elem: elem})
+}
+
+// Offer inserts the element to the tail of the queue.
+// If the queue is full it returns the ErrQueueIsFull error.
+func (tq *Transfer[T]) Offer(elem T) error {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	if tq.isFull() {
+		return ErrQueueIsFull
+	}
+
+	tq.push(transferItem[T]{elem: elem})
+
+	return nil
+}
+
+// Reset sets the queue to its initial state with the original elements.
+// Pending Transfer calls for the dropped elements return
+// ErrElementDiscarded.
+func (tq *Transfer[T]) Reset() {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	for i := range tq.elems {
+		if ticket := tq.elems[i].ticket; ticket != nil {
+			ticket.err = ErrElementDiscarded
+			close(ticket.done)
+		}
+	}
+
+	tq.reset()
+
+	tq.notEmptyCond.Broadcast()
+	tq.notFullCond.Broadcast()
+}
+
+// ===================================Removal==================================
+
+// GetWait removes and returns the head of the elements queue.
+// If no element is available it waits until the queue
+// has an element available.
+func (tq *Transfer[T]) GetWait() T {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	tq.waiting++
+
+	for len(tq.elems) == 0 {
+		tq.notEmptyCond.Wait()
+	}
+
+	tq.waiting--
+
+	return tq.pop()
+}
+
+// Get removes and returns the head of the elements queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (tq *Transfer[T]) Get() (v T, _ error) {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	if len(tq.elems) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return tq.pop(), nil
+}
+
+// Clear removes and returns all elements from the queue.
+func (tq *Transfer[T]) Clear() []T {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	removed := make([]T, 0, len(tq.elems))
+
+	for len(tq.elems) > 0 {
+		removed = append(removed, tq.pop())
+	}
+
+	return removed
+}
+
+// Iterator returns an iterator over the elements in this queue.
+// It removes the elements from the queue.
+func (tq *Transfer[T]) Iterator() <-chan T {
+	elems := tq.Clear()
+
+	iteratorCh := make(chan T, len(elems))
+
+	for i := range elems {
+		iteratorCh <- elems[i]
+	}
+
+	close(iteratorCh)
+
+	return iteratorCh
+}
+
+// =================================Examination================================
+
+// HasWaitingConsumer returns true if at least one consumer is waiting in
+// GetWait.
+func (tq *Transfer[T]) HasWaitingConsumer() bool {
+	return tq.WaitingConsumerCount() > 0
+}
+
+// WaitingConsumerCount returns the number of consumers waiting in GetWait.
+func (tq *Transfer[T]) WaitingConsumerCount() int {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	return tq.waiting
+}
+
+// Peek retrieves but does not return the head of the queue.
+// If no element is available it returns an ErrNoElementsAvailable error.
+func (tq *Transfer[T]) Peek() (v T, _ error) {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	if len(tq.elems) == 0 {
+		return v, ErrNoElementsAvailable
+	}
+
+	return tq.elems[0].elem, nil
+}
+
+// PeekWait retrieves but does not return the head of the queue.
+// If no element is available it waits until the queue
+// has an element available.
+func (tq *Transfer[T]) PeekWait() T {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	for len(tq.elems) == 0 {
+		tq.notEmptyCond.Wait()
+	}
+
+	return tq.elems[0].elem
+}
+
+// Size returns the number of elements in the queue.
+func (tq *Transfer[T]) Size() int {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	return len(tq.elems)
+}
+
+// IsEmpty returns true if the queue is empty.
+func (tq *Transfer[T]) IsEmpty() bool {
+	return tq.Size() == 0
+}
+
+// Contains returns true if the queue contains the given element.
+func (tq *Transfer[T]) Contains(elem T) bool {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	for i := range tq.elems {
+		if tq.elems[i].elem == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON serializes the Transfer queue to JSON.
+func (tq *Transfer[T]) MarshalJSON() ([]byte, error) {
+	tq.lock.Lock()
+
+	output := make([]T, len(tq.elems))
+	for i := range tq.elems {
+		output[i] = tq.elems[i].elem
+	}
+
+	tq.lock.Unlock()
+
+	return json.Marshal(output)
+}
+
+// ===================================Helpers==================================
+
+// isFull returns true if the queue is full.
+func (tq *Transfer[T]) isFull() bool {
+	if tq.capacity == nil {
+		return false
+	}
+
+	return len(tq.elems) >= *tq.capacity
+}
+
+// hasIdleConsumer returns true if a consumer waiting in GetWait has no
+// queued element on its way yet. Caller must hold the lock.
+func (tq *Transfer[T]) hasIdleConsumer() bool {
+	return tq.waiting > len(tq.elems)
+}
+
+// push appends item to the tail. Caller must hold the lock.
+func (tq *Transfer[T]) push(item transferItem[T]) {
+	tq.elems = append(tq.elems, item)
+
+	tq.notEmptyCond.Broadcast()
+}
+
+// pop removes the head, marks it as received and returns it. The queue
+// must be non-empty and the caller must hold the lock.
+func (tq *Transfer[T]) pop() T {
+	item := tq.elems[0]
+
+	var zero transferItem[T]
+
+	tq.elems[0] = zero
+	tq.elems = tq.elems[1:]
+
+	if item.ticket != nil {
+		close(item.ticket.done)
+	}
+
+	tq.notFullCond.Broadcast()
+
+	return item.elem
+}
+
+// withdraw removes the element holding ticket and reports whether it was
+// still queued. Caller must hold the lock.
+func (tq *Transfer[T]) withdraw(ticket *transferTicket) bool {
+	i := 0
+	for i < len(tq.elems) && tq.elems[i].ticket != ticket {
+		i++
+	}
+
+	found := i < len(tq.elems)
+
+	if found {
+		copy(tq.elems[i:], tq.elems[i+1:])
+
+		var zero transferItem[T]
+
+		tq.elems[len(tq.elems)-1] = zero
+		tq.elems = tq.elems[:len(tq.elems)-1]
+
+		tq.notFullCond.Broadcast()
+	}
+
+	return found
+}
+
+// reset replaces the elements with the initial ones. Caller must hold the
+// lock.
+func (tq *Transfer[T]) reset() {
+	tq.elems = make([]transferItem[T], len(tq.initialElems))
+
+	for i, e := range tq.initialElems {
+		tq.elems[i] = transferItem[T]{elem: e}
+	}
+}
+
+// broadcastOnDone broadcasts cond once ctx is done, so that goroutines
+// waiting on cond can observe the cancellation. The returned stop function
+// must be called once the wait is over.
+func broadcastOnDone(ctx context.Context, cond *sync.Cond) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	stopCh := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			cond.L.Lock()
+			cond.Broadcast()
+			cond.L.Unlock()
+		case <-stopCh:
+		}
+	}()
+
+	return func() { close(stopCh) }
+}
@@ -0,0 +1,405 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+// waitForConsumers polls until n consumers are waiting in GetWait.
+func waitForConsumers(t *testing.T, tq *queue.Transfer[int], n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for tq.WaitingConsumerCount() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d waiting consumers, got %d", n, tq.WaitingConsumerCount())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NegativeCapacity", testTransferNegativeCapacity)
+	t.Run("TransferWaitsForReceipt", testTransferWaitsForReceipt)
+	t.Run("TransferContextCancelled", testTransferContextCancelled)
+	t.Run("TransferWaitsForCapacity", testTransferWaitsForCapacity)
+	t.Run("TransferCancelledWhileFull", testTransferCancelledWhileFull)
+	t.Run("TransferDiscardedByReset", testTransferDiscardedByReset)
+	t.Run("TransferToWaitingConsumerWhenFull", testTransferToWaitingConsumerWhenFull)
+	t.Run("TryTransfer", testTransferTryTransfer)
+	t.Run("WaitingConsumers", testTransferWaitingConsumers)
+	t.Run("BlockingSemantics", testTransferBlockingSemantics)
+	t.Run("OfferWait", testTransferOfferWait)
+	t.Run("PeekWait", testTransferPeekWait)
+	t.Run("ClearAndIterator", testTransferClearAndIterator)
+	t.Run("MarshalJSON", testTransferMarshalJSON)
+}
+
+func testTransferNegativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if p := recover(); p != negativeCapacityPanic {
+			t.Fatalf("expected panic %q, got %v", negativeCapacityPanic, p)
+		}
+	}()
+
+	_ = queue.NewTransfer[int](nil, queue.WithCapacity(-1))
+}
+
+func testTransferWaitsForReceipt(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1})
+
+	done := make(chan error)
+
+	go func() { done <- transferQueue.Transfer(context.Background(), 2) }()
+
+	// The transfer stays pending while the element is only buffered.
+	deadline := time.Now().Add(time.Second)
+
+	for !transferQueue.Contains(2) {
+		if time.Now().After(deadline) {
+			t.Fatal("transferred element never enqueued")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if got, _ := transferQueue.Get(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("transfer returned %v before its element was received", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if got := transferQueue.GetWait(); got != 2 {
+		t.Fatalf("got %d want 2", got)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+}
+
+func testTransferContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := transferQueue.Transfer(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	if got := transferQueue.Clear(); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("expected only the withdrawn element to be removed, got %v", got)
+	}
+}
+
+func testTransferWaitsForCapacity(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1}, queue.WithCapacity(1))
+
+	done := make(chan error)
+
+	go func() { done <- transferQueue.Transfer(context.Background(), 2) }()
+
+	time.Sleep(10 * time.Millisecond)
+
+	for _, want := range []int{1, 2} {
+		if got := transferQueue.GetWait(); got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+}
+
+func testTransferCancelledWhileFull(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1}, queue.WithCapacity(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := transferQueue.Transfer(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	if got := transferQueue.Clear(); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("expected [1], got %v", got)
+	}
+}
+
+func testTransferDiscardedByReset(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1})
+
+	done := make(chan error)
+
+	go func() { done <- transferQueue.Transfer(context.Background(), 2) }()
+
+	deadline := time.Now().Add(time.Second)
+
+	for transferQueue.Size() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("transferred element never enqueued")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	transferQueue.Reset()
+
+	if err := <-done; !errors.Is(err, queue.ErrElementDiscarded) {
+		t.Fatalf("expected ErrElementDiscarded, got %v", err)
+	}
+
+	if got := transferQueue.Clear(); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("expected [1], got %v", got)
+	}
+}
+
+func testTransferToWaitingConsumerWhenFull(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer[int](nil, queue.WithCapacity(0))
+
+	result := make(chan int)
+
+	go func() { result <- transferQueue.GetWait() }()
+
+	waitForConsumers(t, transferQueue, 1)
+
+	if err := transferQueue.Transfer(context.Background(), 1); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	if got := <-result; got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+}
+
+func testTransferTryTransfer(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer[int](nil)
+
+	if transferQueue.TryTransfer(1) {
+		t.Fatal("expected TryTransfer to fail without a waiting consumer")
+	}
+
+	if !transferQueue.IsEmpty() {
+		t.Fatal("expected a failed TryTransfer to leave the queue empty")
+	}
+
+	result := make(chan int)
+
+	go func() { result <- transferQueue.GetWait() }()
+
+	waitForConsumers(t, transferQueue, 1)
+
+	if !transferQueue.TryTransfer(2) {
+		t.Fatal("expected TryTransfer to succeed with a waiting consumer")
+	}
+
+	// The only waiting consumer already has an element on its way.
+	if transferQueue.TryTransfer(3) {
+		t.Fatal("expected a second TryTransfer to fail")
+	}
+
+	if got := <-result; got != 2 {
+		t.Fatalf("got %d want 2", got)
+	}
+}
+
+func testTransferWaitingConsumers(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer[int](nil)
+
+	if transferQueue.HasWaitingConsumer() {
+		t.Fatal("expected no waiting consumer")
+	}
+
+	results := make(chan int, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() { results <- transferQueue.GetWait() }()
+	}
+
+	waitForConsumers(t, transferQueue, 2)
+
+	if !transferQueue.HasWaitingConsumer() {
+		t.Fatal("expected a waiting consumer")
+	}
+
+	transferQueue.OfferWait(1)
+	transferQueue.OfferWait(2)
+
+	<-results
+	<-results
+
+	if got := transferQueue.WaitingConsumerCount(); got != 0 {
+		t.Fatalf("expected no waiting consumers, got %d", got)
+	}
+}
+
+func testTransferBlockingSemantics(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1, 2, 3}, queue.WithCapacity(2))
+
+	if err := transferQueue.Offer(3); !errors.Is(err, queue.ErrQueueIsFull) {
+		t.Fatalf("expected ErrQueueIsFull, got %v", err)
+	}
+
+	if got, _ := transferQueue.Peek(); got != 1 {
+		t.Fatalf("peek: got %d want 1", got)
+	}
+
+	if !transferQueue.Contains(2) || transferQueue.Contains(3) {
+		t.Fatal("unexpected Contains result")
+	}
+
+	if got, _ := transferQueue.Get(); got != 1 {
+		t.Fatalf("get: got %d want 1", got)
+	}
+
+	if err := transferQueue.Offer(4); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if got := transferQueue.Clear(); !reflect.DeepEqual(got, []int{2, 4}) {
+		t.Fatalf("expected [2 4], got %v", got)
+	}
+
+	if _, err := transferQueue.Get(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := transferQueue.Peek(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	transferQueue.Reset()
+
+	if transferQueue.Size() != 2 {
+		t.Fatalf("expected size 2 after reset, got %d", transferQueue.Size())
+	}
+}
+
+func testTransferOfferWait(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1}, queue.WithCapacity(1))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		transferQueue.OfferWait(2)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if got := transferQueue.GetWait(); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("OfferWait did not return after a slot was freed")
+	}
+}
+
+func testTransferPeekWait(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer[int](nil)
+
+	result := make(chan int)
+
+	go func() { result <- transferQueue.PeekWait() }()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := transferQueue.Offer(1); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+
+	if got := <-result; got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+}
+
+func testTransferClearAndIterator(t *testing.T) {
+	t.Parallel()
+
+	transferQueue := queue.NewTransfer([]int{1})
+
+	done := make(chan error)
+
+	go func() { done <- transferQueue.Transfer(context.Background(), 2) }()
+
+	deadline := time.Now().Add(time.Second)
+
+	for transferQueue.Size() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("transferred element never enqueued")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	var got []int
+
+	for e := range transferQueue.Iterator() {
+		got = append(got, e)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+
+	// Draining the queue counts as receiving the transferred element.
+	if err := <-done; err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+}
+
+func testTransferMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := queue.NewTransfer([]int{1, 2}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(data) != "[1,2]" {
+		t.Fatalf("expected [1,2], got %s", data)
+	}
+}
@@ -0,0 +1,190 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+// minWorkStealingBuf is the smallest ring allocated by a WorkStealing
+// deque. It must be a power of two.
+const minWorkStealingBuf = 32
+
+// wsRing is the circular array of a WorkStealing deque. Slots hold
+// pointers so that a thief racing with the owner never reads a torn
+// element.
+type wsRing[T any] struct {
+	slots []atomic.Pointer[T]
+	mask  int64
+}
+
+func newWSRing[T any](size int64) *wsRing[T] {
+	return &wsRing[T]{
+		slots: make([]atomic.Pointer[T], size),
+		mask:  size - 1,
+	}
+}
+
+func (r *wsRing[T]) get(i int64) *T {
+	return r.slots[i&r.mask].Load()
+}
+
+func (r *wsRing[T]) put(i int64, elem *T) {
+	r.slots[i&r.mask].Store(elem)
+}
+
+// release clears slot i if it still holds elem, so that the ring does not
+// keep a taken element reachable. Every Push stores a new pointer, so a
+// slot the owner already reused is left alone.
+func (r *wsRing[T]) release(i int64, elem *T) {
+	r.slots[i&r.mask].CompareAndSwap(elem, nil)
+}
+
+// WorkStealing is an unbounded Chase-Lev work-stealing deque, the building
+// block of work-stealing task schedulers such as Pool.
+//
+// A single goroutine, the owner, pushes and pops elements at the bottom
+// in LIFO order without taking locks, which keeps recently spawned,
+// cache-warm tasks on the worker that created them. Any number of other
+// goroutines, the thieves, take the oldest elements from the top with
+// Steal; they only synchronize with the owner through a compare-and-swap
+// when they race for the same element.
+//
+// Push and Pop may only be called by the owner. Steal, Size and IsEmpty
+// may be called by any goroutine.
+//
+// The ring grows when it is full and never shrinks. Old rings are left to
+// the garbage collector, which stays correct for thieves still reading a
+// ring the owner already replaced. Pop and Steal clear the slot of the
+// element they take; only an element stolen while the ring grows may stay
+// in the new ring until its slot is reused.
+type WorkStealing[T any] struct {
+	// top is the index of the oldest element; thieves advance it.
+	top atomic.Int64
+	_   [cacheLineSize - 8]byte
+	// bottom is the index one past the newest element; only the owner
+	// writes it.
+	bottom atomic.Int64
+	_      [cacheLineSize - 8]byte
+	ring   atomic.Pointer[wsRing[T]]
+}
+
+// NewWorkStealing returns a new, empty WorkStealing deque.
+func NewWorkStealing[T any]() *WorkStealing[T] {
+	wq := &WorkStealing[T]{}
+
+	wq.ring.Store(newWSRing[T](minWorkStealingBuf))
+
+	return wq
+}
+
+// Push inserts the element at the bottom of the deque, growing the ring
+// if it is full. Only the owner may call Push.
+func (wq *WorkStealing[T]) Push(elem T) {
+	b := wq.bottom.Load()
+	t := wq.top.Load()
+	r := wq.ring.Load()
+
+	if b-t > r.mask {
+		r = wq.grow(r, t, b)
+	}
+
+	r.put(b, &elem)
+
+	// Publishing the new bottom makes the element visible to thieves.
+	wq.bottom.Store(b + 1)
+}
+
+// Pop removes and returns the element at the bottom of the deque, the
+// most recently pushed one.
+// If no element is available it returns an ErrNoElementsAvailable error.
+// Only the owner may call Pop.
+func (wq *WorkStealing[T]) Pop() (v T, _ error) {
+	b := wq.bottom.Load() - 1
+	r := wq.ring.Load()
+
+	// Reserve the bottom element before looking at top, so that thieves
+	// arriving from now on leave it alone.
+	wq.bottom.Store(b)
+
+	t := wq.top.Load()
+
+	// Thieves never read the slot of the element taken by Pop again, so
+	// it is cleared once the element is taken.
+	if t < b {
+		elem := r.get(b)
+		r.release(b, elem)
+
+		return *elem, nil
+	}
+
+	// At most one element is left, and thieves may be racing for it:
+	// restore bottom and claim the element through top, as they do.
+	wq.bottom.Store(b + 1)
+
+	if t > b || !wq.top.CompareAndSwap(t, t+1) {
+		return v, ErrNoElementsAvailable
+	}
+
+	elem := r.get(b)
+	r.release(b, elem)
+
+	return *elem, nil
+}
+
+// Steal removes and returns the element at the top of the deque, the
+// least recently pushed one. It retries while it loses races against
+// other thieves or the owner, and returns an ErrNoElementsAvailable
+// error once the deque is empty.
+// Any goroutine may call Steal.
+func (wq *WorkStealing[T]) Steal() (v T, _ error) {
+	for {
+		t := wq.top.Load()
+		b := wq.bottom.Load()
+
+		if t >= b {
+			return v, ErrNoElementsAvailable
+		}
+
+		r := wq.ring.Load()
+		elem := r.get(t)
+
+		// The slot is only dereferenced once the element is claimed: if
+		// the claim fails, the slot may already be reused or missing
+		// from a newer ring.
+		if wq.top.CompareAndSwap(t, t+1) {
+			r.release(t, elem)
+
+			return *elem, nil
+		}
+	}
+}
+
+// Size returns the number of elements in the deque. While the owner and
+// thieves run it is an approximation.
+// Any goroutine may call Size.
+func (wq *WorkStealing[T]) Size() int {
+	if n := wq.bottom.Load() - wq.top.Load(); n > 0 {
+		return int(n)
+	}
+
+	return 0
+}
+
+// IsEmpty returns true if the deque is empty.
+// Any goroutine may call IsEmpty.
+func (wq *WorkStealing[T]) IsEmpty() bool {
+	return wq.Size() == 0
+}
+
+// grow replaces the full ring r holding the elements from t to b with one
+// twice as large and returns it. Owner side.
+func (wq *WorkStealing[T]) grow(r *wsRing[T], t, b int64) *wsRing[T] {
+	bigger := newWSRing[T](2 * int64(len(r.slots))) //nolint:mnd // doubling keeps pushes amortized O(1).
+
+	for i := t; i < b; i++ {
+		bigger.put(i, r.get(i))
+	}
+
+	wq.ring.Store(bigger)
+
+	return bigger
+}
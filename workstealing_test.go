@@ -0,0 +1,283 @@
+package queue_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrianbrad/queue"
+)
+
+func TestWorkStealing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PopIsLIFO", testWorkStealingPopIsLIFO)
+	t.Run("StealIsFIFO", testWorkStealingStealIsFIFO)
+	t.Run("Empty", testWorkStealingEmpty)
+	t.Run("Grow", testWorkStealingGrow)
+	t.Run("LastElement", testWorkStealingLastElement)
+	t.Run("OwnerAndThieves", testWorkStealingOwnerAndThieves)
+	t.Run("TakeReleasesReference", testWorkStealingTakeReleasesReference)
+}
+
+func testWorkStealingPopIsLIFO(t *testing.T) {
+	t.Parallel()
+
+	ws := queue.NewWorkStealing[int]()
+
+	for i := 1; i <= 3; i++ {
+		ws.Push(i)
+	}
+
+	for want := 3; want >= 1; want-- {
+		got, err := ws.Pop()
+		if err != nil {
+			t.Fatalf("pop: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testWorkStealingStealIsFIFO(t *testing.T) {
+	t.Parallel()
+
+	ws := queue.NewWorkStealing[int]()
+
+	for i := 1; i <= 3; i++ {
+		ws.Push(i)
+	}
+
+	for want := 1; want <= 3; want++ {
+		got, err := ws.Steal()
+		if err != nil {
+			t.Fatalf("steal: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("got %d want %d", got, want)
+		}
+	}
+}
+
+func testWorkStealingEmpty(t *testing.T) {
+	t.Parallel()
+
+	ws := queue.NewWorkStealing[int]()
+
+	if _, err := ws.Pop(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("pop: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if _, err := ws.Steal(); !errors.Is(err, queue.ErrNoElementsAvailable) {
+		t.Fatalf("steal: expected ErrNoElementsAvailable, got %v", err)
+	}
+
+	if !ws.IsEmpty() || ws.Size() != 0 {
+		t.Fatal("expected an empty deque")
+	}
+}
+
+func testWorkStealingGrow(t *testing.T) {
+	t.Parallel()
+
+	ws := queue.NewWorkStealing[int]()
+
+	// Steal a few elements first so that the ring grows while its
+	// elements wrap around.
+	for i := 0; i < 10; i++ {
+		ws.Push(i)
+	}
+
+	for i := 0; i < 5; i++ {
+		_, _ = ws.Steal()
+	}
+
+	for i := 10; i < 200; i++ {
+		ws.Push(i)
+	}
+
+	if ws.Size() != 195 {
+		t.Fatalf("expected size 195, got %d", ws.Size())
+	}
+
+	if got, _ := ws.Steal(); got != 5 {
+		t.Fatalf("steal: got %d want 5", got)
+	}
+
+	for want := 199; want >= 6; want-- {
+		if got, _ := ws.Pop(); got != want {
+			t.Fatalf("pop: got %d want %d", got, want)
+		}
+	}
+}
+
+func testWorkStealingLastElement(t *testing.T) {
+	t.Parallel()
+
+	ws := queue.NewWorkStealing[string]()
+
+	ws.Push("a")
+
+	if got, err := ws.Pop(); err != nil || got != "a" {
+		t.Fatalf("expected a, got %q (%v)", got, err)
+	}
+
+	// The deque stays usable after its last element was claimed.
+	ws.Push("b")
+
+	if got, err := ws.Steal(); err != nil || got != "b" {
+		t.Fatalf("expected b, got %q (%v)", got, err)
+	}
+}
+
+func testWorkStealingOwnerAndThieves(t *testing.T) {
+	t.Parallel()
+
+	const (
+		elems   = 20_000
+		thieves = 4
+	)
+
+	ws := queue.NewWorkStealing[int]()
+
+	seen := make([]int, elems)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done = make(chan struct{})
+	)
+
+	record := func(e int) {
+		mu.Lock()
+		seen[e]++
+		mu.Unlock()
+	}
+
+	wg.Add(thieves)
+
+	for i := 0; i < thieves; i++ {
+		go func() {
+			defer wg.Done()
+
+			for {
+				if e, err := ws.Steal(); err == nil {
+					record(e)
+
+					continue
+				}
+
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}()
+	}
+
+	// The owner interleaves pushes and pops, racing the thieves for the
+	// last elements.
+	for i := 0; i < elems; i++ {
+		ws.Push(i)
+
+		if i%3 == 0 {
+			if e, err := ws.Pop(); err == nil {
+				record(e)
+			}
+		}
+	}
+
+	for {
+		e, err := ws.Pop()
+		if err != nil {
+			break
+		}
+
+		record(e)
+	}
+
+	close(done)
+	wg.Wait()
+
+	for e, n := range seen {
+		if n != 1 {
+			t.Fatalf("element %d taken %d times", e, n)
+		}
+	}
+}
+
+func testWorkStealingTakeReleasesReference(t *testing.T) {
+	t.Parallel()
+
+	// payload is 16 bytes so that it is not batched with other objects
+	// by the tiny allocator, which would delay its finalizer.
+	type payload struct {
+		id int
+		_  int
+	}
+
+	takes := map[string]func(*queue.WorkStealing[*payload]) (*payload, error){
+		"Pop":   (*queue.WorkStealing[*payload]).Pop,
+		"Steal": (*queue.WorkStealing[*payload]).Steal,
+	}
+
+	for name, take := range takes {
+		deque := queue.NewWorkStealing[*payload]()
+
+		finalized := make(chan struct{}, 1)
+
+		// Push two payloads, so that Pop takes the bottom one without
+		// racing for the last element, and take the one with a
+		// finalizer in a nested scope, so that nothing but the deque can
+		// keep it alive.
+		func() {
+			p := &payload{id: 42}
+			runtime.SetFinalizer(p, func(*payload) {
+				finalized <- struct{}{}
+			})
+
+			if name == "Pop" {
+				deque.Push(&payload{id: 1})
+				deque.Push(p)
+			} else {
+				deque.Push(p)
+				deque.Push(&payload{id: 1})
+			}
+
+			if got, err := take(deque); err != nil || got != p {
+				t.Fatalf("%s: got %v, %v", name, got, err)
+			}
+		}()
+
+		if !finalizedWithinASecond(finalized) {
+			t.Fatalf("%s: taken element not finalized; its slot still holds it", name)
+		}
+
+		runtime.KeepAlive(deque)
+	}
+}
+
+// finalizedWithinASecond nudges the garbage collector until finalized
+// receives, and reports whether it did within a second.
+func finalizedWithinASecond(finalized <-chan struct{}) bool {
+	deadline := time.After(time.Second)
+
+	for {
+		runtime.GC() //nolint:revive // explicit GC needed to drive finalizer
+
+		select {
+		case <-finalized:
+			return true
+		case <-deadline:
+			return false
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}